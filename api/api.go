@@ -4,36 +4,88 @@ import (
 	"context"
 	"log/slog"
 	"net/http"
+	"newsservice/internal/pagination"
+	"newsservice/internal/search"
 	transport "newsservice/internal/transport/http"
+	"newsservice/internal/usecase"
 	"newsservice/storage"
 )
 
 type Api struct {
-	mux *http.ServeMux
-	db  storage.NewsStorage
-	ctx context.Context
-	log *slog.Logger
+	mux     *http.ServeMux
+	db      storage.NewsStorage
+	cursors *pagination.CursorCodec
+	search  *usecase.SearchUseCase
+	ctx     context.Context
+	log     *slog.Logger
 }
 
-func NewApi(db storage.NewsStorage, log *slog.Logger) *Api {
+func NewApi(db storage.NewsStorage, cursors *pagination.CursorCodec, log *slog.Logger) *Api {
 	api := Api{
-		mux: http.NewServeMux(),
-		db:  db,
-		log: log,
-		ctx: context.Background(),
+		mux:     http.NewServeMux(),
+		db:      db,
+		cursors: cursors,
+		log:     log,
+		ctx:     context.Background(),
 	}
 	api.endpoints()
 	return &api
 }
 
+// Router возвращает обработчик со всеми зарегистрированными маршрутами.
+func (api *Api) Router() http.Handler {
+	return api.mux
+}
+
+// Handle регистрирует дополнительный маршрут, обработчик которого не зависит от хранилища.
+func (api *Api) Handle(pattern string, handler http.Handler) {
+	api.mux.Handle(pattern, handler)
+}
+
+// UseSearcher задает реализацию полнотекстового поиска для /api/v1/search
+// вместо поиска средствами хранилища. Вызывается до запуска сервера.
+func (api *Api) UseSearcher(searcher search.Searcher) {
+	if api.search != nil {
+		*api.search = *usecase.NewSearchUseCase(searcher)
+		return
+	}
+	api.search = usecase.NewSearchUseCase(searcher)
+	//маршрут для полнотекстового поиска с ранжированием
+	api.mux.HandleFunc("/api/v1/search", transport.HandleFullTextSearch(api.search, api.log))
+}
+
 // Метод регистратор endpoint-ов, настраивающий саброутинг.
 func (api *Api) endpoints() {
-	//маршрут для возврата детальной информации о новости
-	api.mux.HandleFunc("/newsdetail/", transport.HandleDetailedNews())
+	news := usecase.NewNewsQueryUseCase(api.db, api.cursors)
+	v1 := transport.NewV1Handler(news, api.log)
 	//маршрут для возврата списка новостей
-	api.mux.HandleFunc("/newslist/", transport.HandleGetNewsList())
-	//маршрут для возврата списка  новостей отфильтрованных по контенту
-	api.mux.HandleFunc("/newslist/filtered/", transport.HandleFilterNewsByContent())
-	//маршрут для возврата списка новостей отфильтрованных по дате публикации
-	// api.mux.HandleFunc("/newslist/filtered/date/", transport.FilterNewsByPublishedHandler)
+	api.mux.HandleFunc("/api/v1/news", v1.HandleListNews())
+	//маршрут для поиска новостей по тексту
+	api.mux.HandleFunc("/api/v1/news/search", v1.HandleSearchNews())
+	//маршрут для возврата детальной информации о новости
+	api.mux.HandleFunc("/api/v1/news/{id}", v1.HandleGetNews())
+
+	if searcher, ok := api.db.(search.Searcher); ok {
+		api.UseSearcher(searcher)
+	}
+	if admin, ok := api.db.(storage.AdminStorage); ok {
+		adminHandler := transport.NewAdminHandler(admin)
+		//маршрут для возврата распределения новостей источников по языкам
+		api.mux.HandleFunc("/admin/sources/languages", adminHandler.HandleLanguageStats())
+	}
+	if revisions, ok := api.db.(storage.RevisionStorage); ok {
+		//маршрут для возврата истории правок новости
+		api.mux.HandleFunc("/news/{id}/revisions", transport.HandleNewsRevisions(revisions))
+	}
+	if taxonomy, ok := api.db.(storage.TaxonomyStorage); ok {
+		taxonomyHandler := transport.NewTaxonomyHandler(usecase.NewTaxonomyUseCase(taxonomy, news), api.log)
+		//маршруты для просмотра тегов и категорий
+		api.mux.HandleFunc("/api/v1/tags", taxonomyHandler.HandleListTags())
+		api.mux.HandleFunc("/api/v1/tags/{slug}/news", taxonomyHandler.HandleTagNews())
+		api.mux.HandleFunc("/api/v1/categories", taxonomyHandler.HandleListCategories())
+		//маршруты для администрирования справочников
+		api.mux.HandleFunc("/admin/tags/merge", taxonomyHandler.HandleMergeTags())
+		api.mux.HandleFunc("/admin/tags/{slug}/rename", taxonomyHandler.HandleRenameTag())
+		api.mux.HandleFunc("/admin/categories/{slug}", taxonomyHandler.HandleSaveCategory())
+	}
 }
@@ -0,0 +1,620 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"newsservice/internal/domain"
+	"newsservice/internal/models"
+	"newsservice/internal/pagination"
+	"newsservice/internal/search"
+	"newsservice/internal/usecase"
+	"newsservice/storage"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeStorage — реализация storage.NewsStorage в памяти для тестов обработчиков.
+type fakeStorage struct {
+	news     map[int]models.NewsFullDetailed
+	total    int
+	list     []models.NewsFullDetailed
+	err      error
+	filter   models.NewsFilter
+	detailID int
+}
+
+func (f *fakeStorage) GetNewsCount(ctx context.Context, filter models.NewsFilter) (int, error) {
+	f.filter = filter
+	return f.total, f.err
+}
+
+func (f *fakeStorage) GetDetailedNews(ctx context.Context, id int) (models.NewsFullDetailed, error) {
+	f.detailID = id
+	if f.err != nil {
+		return models.NewsFullDetailed{}, f.err
+	}
+	news, ok := f.news[id]
+	if !ok {
+		return models.NewsFullDetailed{}, fmt.Errorf("news with ID %d: %w", id, storage.ErrNotFound)
+	}
+	return news, nil
+}
+
+func (f *fakeStorage) GetNewsByFilter(ctx context.Context, filter models.NewsFilter) ([]models.NewsFullDetailed, error) {
+	f.filter = filter
+	return f.list, f.err
+}
+
+func (f *fakeStorage) SaveNews(ctx context.Context, feed *domain.Feed) (int, error) {
+	return 0, errors.New("not implemented")
+}
+
+func (f *fakeStorage) Close() {}
+
+type response struct {
+	Status  string          `json:"status"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+func serve(t *testing.T, db storage.NewsStorage, method, target string) (int, response) {
+	t.Helper()
+	return serveBody(t, db, method, target, "")
+}
+
+func serveBody(t *testing.T, db storage.NewsStorage, method, target, body string) (int, response) {
+	t.Helper()
+	api := NewApi(db, pagination.NewCursorCodec([]byte("test")), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	rec := httptest.NewRecorder()
+	api.Router().ServeHTTP(rec, httptest.NewRequest(method, target, strings.NewReader(body)))
+	var resp response
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid JSON response %q: %v", rec.Body.String(), err)
+	}
+	return rec.Code, resp
+}
+
+func TestListNews(t *testing.T) {
+	db := &fakeStorage{
+		total: 12,
+		list:  []models.NewsFullDetailed{{NewsID: 6}, {NewsID: 7}},
+	}
+	code, resp := serve(t, db, http.MethodGet, "/api/v1/news?page=2&limit=5&category=world&lang=EN&date=2025-03-01")
+	if code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (%s)", code, resp.Message)
+	}
+	if db.filter.Limit != 5 || db.filter.Offset != 5 {
+		t.Errorf("limit/offset = %d/%d, want 5/5", db.filter.Limit, db.filter.Offset)
+	}
+	day := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	if !slices.Equal(db.filter.Categories, []string{"world"}) || !slices.Equal(db.filter.Languages, []string{"en"}) ||
+		!db.filter.From.Equal(day) || !db.filter.To.Equal(day.AddDate(0, 0, 1)) {
+		t.Errorf("unexpected filter %+v", db.filter)
+	}
+	var page struct {
+		TotalResults int                       `json:"total_results"`
+		CurrentPage  int                       `json:"current_page"`
+		NewsPerPage  int                       `json:"news_per_page"`
+		Results      []models.NewsFullDetailed `json:"results"`
+	}
+	if err := json.Unmarshal(resp.Data, &page); err != nil {
+		t.Fatal(err)
+	}
+	if page.TotalResults != 12 || page.CurrentPage != 2 || page.NewsPerPage != 5 || len(page.Results) != 2 {
+		t.Errorf("unexpected page %+v", page)
+	}
+}
+
+func TestListNewsDefaults(t *testing.T) {
+	db := &fakeStorage{}
+	code, resp := serve(t, db, http.MethodGet, "/api/v1/news")
+	if code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (%s)", code, resp.Message)
+	}
+	if db.filter.Limit != 20 || db.filter.Offset != 0 {
+		t.Errorf("limit/offset = %d/%d, want 20/0", db.filter.Limit, db.filter.Offset)
+	}
+}
+
+func TestListNewsBadRequest(t *testing.T) {
+	for _, query := range []string{
+		"page=0",
+		"page=abc",
+		"limit=0",
+		"limit=101",
+		"date=01.03.2025",
+		"collapse=everything",
+		"lang=%21%21",
+	} {
+		t.Run(query, func(t *testing.T) {
+			code, resp := serve(t, &fakeStorage{}, http.MethodGet, "/api/v1/news?"+query)
+			if code != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400", code)
+			}
+			if resp.Status != "error" || resp.Message == "" {
+				t.Errorf("unexpected error body %+v", resp)
+			}
+		})
+	}
+}
+
+func TestListNewsStorageError(t *testing.T) {
+	code, _ := serve(t, &fakeStorage{err: errors.New("connection refused")}, http.MethodGet, "/api/v1/news")
+	if code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", code)
+	}
+}
+
+func TestListNewsUnsupportedCollapse(t *testing.T) {
+	db := &fakeStorage{err: fmt.Errorf("collapse %q: %w", models.CollapseEarliest, storage.ErrUnsupported)}
+	code, resp := serve(t, db, http.MethodGet, "/api/v1/news?collapse=earliest")
+	if code != http.StatusBadRequest || !strings.Contains(resp.Message, "not supported") {
+		t.Errorf("status = %d, message %q, want 400 not supported", code, resp.Message)
+	}
+}
+
+func TestListNewsMethodNotAllowed(t *testing.T) {
+	code, _ := serve(t, &fakeStorage{}, http.MethodPost, "/api/v1/news")
+	if code != http.StatusMethodNotAllowed {
+		t.Errorf("status = %d, want 405", code)
+	}
+}
+
+func TestGetNews(t *testing.T) {
+	db := &fakeStorage{news: map[int]models.NewsFullDetailed{7: {NewsID: 7, Title: "Seven"}}}
+	tests := []struct {
+		name   string
+		target string
+		db     *fakeStorage
+		want   int
+	}{
+		{"found", "/api/v1/news/7", db, http.StatusOK},
+		{"not found", "/api/v1/news/8", db, http.StatusNotFound},
+		{"invalid id", "/api/v1/news/abc", db, http.StatusBadRequest},
+		{"negative id", "/api/v1/news/-1", db, http.StatusBadRequest},
+		{"storage error", "/api/v1/news/7", &fakeStorage{err: errors.New("timeout")}, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, resp := serve(t, tt.db, http.MethodGet, tt.target)
+			if code != tt.want {
+				t.Fatalf("status = %d, want %d (%s)", code, tt.want, resp.Message)
+			}
+			if tt.want != http.StatusOK {
+				return
+			}
+			var news models.NewsFullDetailed
+			if err := json.Unmarshal(resp.Data, &news); err != nil {
+				t.Fatal(err)
+			}
+			if news.NewsID != 7 || news.Title != "Seven" {
+				t.Errorf("unexpected news %+v", news)
+			}
+		})
+	}
+}
+
+func TestSearchNews(t *testing.T) {
+	db := &fakeStorage{total: 1, list: []models.NewsFullDetailed{{NewsID: 1}}}
+	code, resp := serve(t, db, http.MethodGet, "/api/v1/news/search?q=%20election%20&author=Smith")
+	if code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (%s)", code, resp.Message)
+	}
+	if db.filter.Query != "election" || db.filter.Author != "Smith" {
+		t.Errorf("unexpected filter %+v", db.filter)
+	}
+}
+
+func TestSearchNewsBadRequest(t *testing.T) {
+	for _, query := range []string{"", "q=", "q=a", "q=news&limit=-5"} {
+		t.Run(query, func(t *testing.T) {
+			code, _ := serve(t, &fakeStorage{}, http.MethodGet, "/api/v1/news/search?"+query)
+			if code != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400", code)
+			}
+		})
+	}
+}
+
+// fakeSearchStorage дополняет fakeStorage полнотекстовым поиском.
+type fakeSearchStorage struct {
+	fakeStorage
+	request search.Request
+	results []models.SearchResult
+}
+
+func (f *fakeSearchStorage) Search(ctx context.Context, req search.Request) (search.Result, error) {
+	f.request = req
+	return search.Result{Hits: f.results, Total: len(f.results)}, f.err
+}
+
+func (f *fakeSearchStorage) Index(ctx context.Context, docs ...search.Document) error {
+	return nil
+}
+
+func TestFullTextSearch(t *testing.T) {
+	db := &fakeSearchStorage{results: []models.SearchResult{{NewsID: 3, Snippet: "<mark>oil</mark> prices"}}}
+	code, resp := serve(t, db, http.MethodGet, `/api/v1/search?q=oil+-"fake+news"&lang=en&source=reuters&date=2024-03-01&limit=5&page=2&facets=true`)
+	if code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (%s)", code, resp.Message)
+	}
+	req := db.request
+	if got := req.Query.String(); got != `oil -"fake news"` {
+		t.Errorf("query = %q", got)
+	}
+	if req.Filter.Language != "en" || req.Filter.Source != "reuters" || req.Limit != 5 || req.Offset != 5 || !req.Facets {
+		t.Errorf("unexpected request %+v", req)
+	}
+	if want := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC); !req.Filter.From.Equal(want) || !req.Filter.To.Equal(want.AddDate(0, 0, 1)) {
+		t.Errorf("unexpected date range %v..%v", req.Filter.From, req.Filter.To)
+	}
+	var page struct {
+		Results []models.SearchResult `json:"results"`
+		Query   string                `json:"query"`
+	}
+	if err := json.Unmarshal(resp.Data, &page); err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Results) != 1 || page.Results[0].Snippet != "<mark>oil</mark> prices" {
+		t.Errorf("unexpected results %+v", page.Results)
+	}
+}
+
+func TestFullTextSearchBadRequest(t *testing.T) {
+	for _, query := range []string{"", "q=", "q=-sport", "q=***", "q=oil&limit=500", "q=oil&facets=maybe", "q=oil&date=yesterday", "q=oil&cursor="} {
+		t.Run(query, func(t *testing.T) {
+			code, _ := serve(t, &fakeSearchStorage{}, http.MethodGet, "/api/v1/search?"+query)
+			if code != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400", code)
+			}
+		})
+	}
+}
+
+// keysetStorage выбирает новости по позиции курсора, как хранилище PostgreSQL.
+type keysetStorage struct {
+	fakeStorage
+}
+
+func (f *keysetStorage) GetNewsByFilter(ctx context.Context, filter models.NewsFilter) ([]models.NewsFullDetailed, error) {
+	k := filter.Keyset
+	if k == nil || k.Order != models.SortDesc {
+		return nil, errors.New("descending keyset expected")
+	}
+	// cmp сравнивает позицию новости с позицией курсора в порядке вывода.
+	cmp := func(n models.NewsFullDetailed) int {
+		if c := k.PublishedAt.Compare(n.PublishedAt); c != 0 {
+			return c
+		}
+		return k.ID - n.NewsID
+	}
+	var out []models.NewsFullDetailed
+	if k.Backward {
+		for i := len(f.list) - 1; i >= 0 && len(out) < filter.Limit; i-- {
+			if cmp(f.list[i]) < 0 {
+				out = append([]models.NewsFullDetailed{f.list[i]}, out...)
+			}
+		}
+		return out, nil
+	}
+	for _, n := range f.list {
+		if len(out) < filter.Limit && (k.ID == 0 || cmp(n) > 0) {
+			out = append(out, n)
+		}
+	}
+	return out, nil
+}
+
+func TestListNewsByCursor(t *testing.T) {
+	base := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	db := &keysetStorage{}
+	// Новости 5 и 4 опубликованы одновременно: порядок между ними задает id.
+	for id, at := range map[int]time.Time{7: base.Add(3 * time.Hour), 6: base.Add(2 * time.Hour), 5: base.Add(time.Hour), 4: base.Add(time.Hour), 3: base} {
+		db.list = append(db.list, models.NewsFullDetailed{NewsID: id, PublishedAt: at})
+	}
+	slices.SortFunc(db.list, func(a, b models.NewsFullDetailed) int { return b.NewsID - a.NewsID })
+
+	type cursorPage struct {
+		Results    []models.NewsFullDetailed `json:"results"`
+		HasNext    bool                      `json:"has_next"`
+		HasPrev    bool                      `json:"has_prev"`
+		NextCursor string                    `json:"next_cursor"`
+		PrevCursor string                    `json:"prev_cursor"`
+	}
+	get := func(cursor string) cursorPage {
+		t.Helper()
+		code, resp := serve(t, db, http.MethodGet, "/api/v1/news?limit=2&cursor="+url.QueryEscape(cursor))
+		if code != http.StatusOK {
+			t.Fatalf("status = %d, want 200 (%s)", code, resp.Message)
+		}
+		var page cursorPage
+		if err := json.Unmarshal(resp.Data, &page); err != nil {
+			t.Fatal(err)
+		}
+		return page
+	}
+	ids := func(page cursorPage) []int {
+		var out []int
+		for _, n := range page.Results {
+			out = append(out, n.NewsID)
+		}
+		return out
+	}
+
+	first := get("")
+	if got := ids(first); !slices.Equal(got, []int{7, 6}) || first.HasPrev || !first.HasNext {
+		t.Fatalf("first page = %v %+v", got, first)
+	}
+	second := get(first.NextCursor)
+	if got := ids(second); !slices.Equal(got, []int{5, 4}) || !second.HasPrev || !second.HasNext {
+		t.Fatalf("second page = %v %+v", got, second)
+	}
+	last := get(second.NextCursor)
+	if got := ids(last); !slices.Equal(got, []int{3}) || last.HasNext || last.NextCursor != "" {
+		t.Fatalf("last page = %v %+v", got, last)
+	}
+	back := get(last.PrevCursor)
+	if got := ids(back); !slices.Equal(got, []int{5, 4}) || !back.HasPrev {
+		t.Fatalf("previous page = %v %+v", got, back)
+	}
+	if got := ids(get(back.PrevCursor)); !slices.Equal(got, []int{7, 6}) {
+		t.Fatalf("first page again = %v", got)
+	}
+}
+
+func TestListNewsByCursorBadRequest(t *testing.T) {
+	token := pagination.NewCursorCodec([]byte("test")).Encode(pagination.Cursor{ID: 3, Order: models.SortDesc})
+	forged := pagination.NewCursorCodec([]byte("other")).Encode(pagination.Cursor{ID: 3, Order: models.SortDesc})
+	for _, query := range []string{"cursor=garbage", "cursor=" + forged, "cursor=" + token + "x", "cursor=" + token + "&page=2"} {
+		t.Run(query, func(t *testing.T) {
+			code, _ := serve(t, &keysetStorage{}, http.MethodGet, "/api/v1/news?"+query)
+			if code != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400", code)
+			}
+		})
+	}
+}
+
+func TestListNewsSort(t *testing.T) {
+	db := &fakeStorage{}
+	code, resp := serve(t, db, http.MethodGet, "/api/v1/news/search?q=oil&sort=-relevance,source,-published_at")
+	if code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (%s)", code, resp.Message)
+	}
+	want := []models.SortField{
+		{Field: models.SortRelevance, Order: models.SortDesc},
+		{Field: models.SortSource, Order: models.SortAsc},
+		{Field: models.SortPublishedAt, Order: models.SortDesc},
+	}
+	if !slices.Equal(db.filter.Sort, want) {
+		t.Errorf("sort = %+v, want %+v", db.filter.Sort, want)
+	}
+}
+
+func TestListNewsSortBadRequest(t *testing.T) {
+	for _, target := range []string{
+		"/api/v1/news?sort=id%3BDROP+TABLE+news",
+		"/api/v1/news?sort=title,-title",
+		"/api/v1/news?sort=title,",
+		"/api/v1/news?sort=relevance",
+		"/api/v1/news?sort=title&cursor=",
+		"/api/v1/news/search?q=oil&sort=-relevance&cursor=",
+	} {
+		t.Run(target, func(t *testing.T) {
+			code, _ := serve(t, &fakeStorage{}, http.MethodGet, target)
+			if code != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400", code)
+			}
+		})
+	}
+
+	_, resp := serve(t, &fakeStorage{}, http.MethodGet, "/api/v1/news?sort=-views")
+	if !strings.Contains(resp.Message, "published_at, fetched_at, title, source, relevance") {
+		t.Errorf("message %q does not list allowed fields", resp.Message)
+	}
+}
+
+func TestListNewsFilters(t *testing.T) {
+	db := &fakeStorage{}
+	target := "/api/v1/news?source=bbc,reuters&source=tass&exclude_category=sport&tag=oil&tag=opec&tags_match=all" +
+		"&exclude_tag=ads&lang=en,RU&has_image=true&from=2025-03-01T10:00:00%2B03:00&to=2025-03-05"
+	code, resp := serve(t, db, http.MethodGet, target)
+	if code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (%s)", code, resp.Message)
+	}
+	f := db.filter
+	if !slices.Equal(f.Sources, []string{"bbc", "reuters", "tass"}) || !slices.Equal(f.ExcludeCategories, []string{"sport"}) {
+		t.Errorf("sources/exclusions = %v/%v", f.Sources, f.ExcludeCategories)
+	}
+	if !slices.Equal(f.Tags, []string{"oil", "opec"}) || f.TagsMatch != models.TagsMatchAll || !slices.Equal(f.ExcludeTags, []string{"ads"}) {
+		t.Errorf("tags = %v %q, excluded %v", f.Tags, f.TagsMatch, f.ExcludeTags)
+	}
+	if !slices.Equal(f.Languages, []string{"en", "ru"}) || f.HasImage == nil || !*f.HasImage {
+		t.Errorf("languages/has_image = %v/%v", f.Languages, f.HasImage)
+	}
+	if want := time.Date(2025, 3, 1, 7, 0, 0, 0, time.UTC); !f.From.Equal(want) {
+		t.Errorf("from = %v, want %v", f.From, want)
+	}
+	if want := time.Date(2025, 3, 6, 0, 0, 0, 0, time.UTC); !f.To.Equal(want) {
+		t.Errorf("to = %v, want %v (whole day included)", f.To, want)
+	}
+}
+
+func TestListNewsFiltersBadRequest(t *testing.T) {
+	for _, query := range []string{
+		"tags_match=some&tag=a",
+		"has_image=maybe",
+		"from=yesterday",
+		"from=2025-03-05&to=2025-03-01",
+		"date=2025-03-01&from=2025-02-01",
+		"lang=en,klingon",
+		"source=" + strings.Repeat("s,", usecase.MaxFilterValues+1),
+	} {
+		t.Run(query, func(t *testing.T) {
+			code, _ := serve(t, &fakeStorage{}, http.MethodGet, "/api/v1/news?"+query)
+			if code != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400", code)
+			}
+		})
+	}
+}
+
+// taxonomyStorage дополняет fakeStorage справочниками тегов и категорий.
+type taxonomyStorage struct {
+	fakeStorage
+	tags       map[string]models.Tag
+	categories []models.Category
+	saved      models.Category
+	prefix     string
+	merged     []string
+}
+
+func (f *taxonomyStorage) ListTags(ctx context.Context, prefix string, limit int) ([]models.Tag, error) {
+	f.prefix = prefix
+	var tags []models.Tag
+	for _, tag := range f.tags {
+		if strings.HasPrefix(tag.Slug, prefix) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags, nil
+}
+
+func (f *taxonomyStorage) GetTag(ctx context.Context, slug string) (models.Tag, error) {
+	tag, ok := f.tags[slug]
+	if !ok {
+		return models.Tag{}, fmt.Errorf("tag %q: %w", slug, storage.ErrNotFound)
+	}
+	return tag, nil
+}
+
+func (f *taxonomyStorage) RenameTag(ctx context.Context, slug, newSlug, name string) (models.Tag, error) {
+	if _, ok := f.tags[slug]; !ok {
+		return models.Tag{}, fmt.Errorf("tag %q: %w", slug, storage.ErrNotFound)
+	}
+	if existing, ok := f.tags[newSlug]; ok && existing.Slug != slug {
+		return models.Tag{}, fmt.Errorf("tag %q: %w", newSlug, storage.ErrConflict)
+	}
+	return models.Tag{Slug: newSlug, Name: name}, nil
+}
+
+func (f *taxonomyStorage) MergeTags(ctx context.Context, from []string, into string) (models.Tag, error) {
+	f.merged = from
+	return f.GetTag(ctx, into)
+}
+
+func (f *taxonomyStorage) ListCategories(ctx context.Context) ([]models.Category, error) {
+	return slices.Clone(f.categories), nil
+}
+
+func (f *taxonomyStorage) SaveCategory(ctx context.Context, category models.Category) error {
+	f.saved = category
+	return nil
+}
+
+func newTaxonomyStorage() *taxonomyStorage {
+	return &taxonomyStorage{
+		tags: map[string]models.Tag{
+			"oil":      {Slug: "oil", Name: "Oil", NewsCount: 12},
+			"olympics": {Slug: "olympics", Name: "Olympics", NewsCount: 3},
+			"opec":     {Slug: "opec", Name: "OPEC", NewsCount: 5},
+		},
+		categories: []models.Category{
+			{Slug: "sport", Names: map[string]string{"en": "Sport", "ru": "Спорт"}},
+			{Slug: "football", Parent: "sport", Names: map[string]string{"en": "Football"}},
+			{Slug: "economy"},
+		},
+	}
+}
+
+func TestListTags(t *testing.T) {
+	db := newTaxonomyStorage()
+	code, resp := serve(t, db, http.MethodGet, "/api/v1/tags?prefix=O&limit=10")
+	if code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (%s)", code, resp.Message)
+	}
+	var tags []models.Tag
+	if err := json.Unmarshal(resp.Data, &tags); err != nil {
+		t.Fatal(err)
+	}
+	if db.prefix != "o" || len(tags) != 3 {
+		t.Errorf("prefix = %q, tags = %v", db.prefix, tags)
+	}
+
+	if code, _ := serve(t, db, http.MethodGet, "/api/v1/tags?limit=1000"); code != http.StatusBadRequest {
+		t.Errorf("limit=1000: status = %d, want 400", code)
+	}
+}
+
+func TestTagNews(t *testing.T) {
+	db := newTaxonomyStorage()
+	db.total = 1
+	db.list = []models.NewsFullDetailed{{NewsID: 1, Title: "Oil prices"}}
+	code, resp := serve(t, db, http.MethodGet, "/api/v1/tags/opec/news?tag=oil&lang=en")
+	if code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (%s)", code, resp.Message)
+	}
+	f := db.filter
+	if !slices.Equal(f.Tags, []string{"oil", "opec"}) || f.TagsMatch != models.TagsMatchAll || !slices.Equal(f.Languages, []string{"en"}) {
+		t.Errorf("filter = %+v", f)
+	}
+
+	if code, _ := serve(t, db, http.MethodGet, "/api/v1/tags/missing/news"); code != http.StatusNotFound {
+		t.Errorf("missing tag: status = %d, want 404", code)
+	}
+}
+
+func TestListCategories(t *testing.T) {
+	code, resp := serve(t, newTaxonomyStorage(), http.MethodGet, "/api/v1/categories?lang=ru")
+	if code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (%s)", code, resp.Message)
+	}
+	var tree []models.Category
+	if err := json.Unmarshal(resp.Data, &tree); err != nil {
+		t.Fatal(err)
+	}
+	if len(tree) != 2 || tree[0].Slug != "economy" || tree[1].Slug != "sport" {
+		t.Fatalf("roots = %+v", tree)
+	}
+	if tree[0].Name != "economy" || tree[1].Name != "Спорт" {
+		t.Errorf("names = %q, %q", tree[0].Name, tree[1].Name)
+	}
+	if len(tree[1].Children) != 1 || tree[1].Children[0].Name != "Football" {
+		t.Errorf("children = %+v", tree[1].Children)
+	}
+}
+
+func TestTaxonomyAdmin(t *testing.T) {
+	for _, tc := range []struct {
+		name, method, target, body string
+		want                       int
+	}{
+		{"rename", http.MethodPost, "/admin/tags/oil/rename", `{"slug":"Crude Oil","name":"Crude oil"}`, http.StatusOK},
+		{"rename conflict", http.MethodPost, "/admin/tags/oil/rename", `{"slug":"opec"}`, http.StatusConflict},
+		{"rename missing", http.MethodPost, "/admin/tags/gas/rename", `{"slug":"lng"}`, http.StatusNotFound},
+		{"rename bad body", http.MethodPost, "/admin/tags/oil/rename", `{`, http.StatusBadRequest},
+		{"merge", http.MethodPost, "/admin/tags/merge", `{"from":["olympics"],"into":"opec"}`, http.StatusOK},
+		{"merge without into", http.MethodPost, "/admin/tags/merge", `{"from":["olympics"]}`, http.StatusBadRequest},
+		{"save category", http.MethodPut, "/admin/categories/tennis", `{"parent":"sport","names":{"en":"Tennis"}}`, http.StatusOK},
+		{"unknown parent", http.MethodPut, "/admin/categories/tennis", `{"parent":"games"}`, http.StatusNotFound},
+		{"category cycle", http.MethodPut, "/admin/categories/sport", `{"parent":"football"}`, http.StatusBadRequest},
+		{"bad language", http.MethodPut, "/admin/categories/tennis", `{"names":{"klingon":"x"}}`, http.StatusBadRequest},
+		{"wrong method", http.MethodGet, "/admin/categories/tennis", ``, http.StatusMethodNotAllowed},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			code, resp := serveBody(t, newTaxonomyStorage(), tc.method, tc.target, tc.body)
+			if code != tc.want {
+				t.Errorf("status = %d, want %d (%s)", code, tc.want, resp.Message)
+			}
+		})
+	}
+}
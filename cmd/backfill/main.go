@@ -0,0 +1,118 @@
+// Команда backfill заново назначает категории и теги сохраненным новостям
+// после изменения правил категоризации.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"newsservice/internal/infrastructure/config"
+	"newsservice/internal/tagging"
+	"newsservice/storage"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+)
+
+func main() {
+	configPath := flag.String("config", "configs/dev.yaml", "path to config file")
+	batchSize := flag.Int("batch", 500, "number of news processed per batch")
+	dryRun := flag.Bool("dry-run", false, "report changes without writing them")
+	flag.Parse()
+
+	log := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx, log, *configPath, *batchSize, *dryRun); err != nil {
+		log.Error("Backfill failed", slog.Any("error", err))
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, log *slog.Logger, configPath string, batchSize int, dryRun bool) error {
+	if batchSize < 1 {
+		return fmt.Errorf("batch size must be positive")
+	}
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		return err
+	}
+	db, err := storage.Open(*cfg, log)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	news, ok := db.(storage.TaggingStorage)
+	if !ok {
+		return storage.Unsupported(cfg.DB.Driver, "tagging backfill")
+	}
+
+	source, err := tagging.NewSource(cfg.Ingest.Tagging, news)
+	if err != nil {
+		return err
+	}
+	engine := tagging.NewEngine(source, log)
+	if err := engine.Reload(ctx); err != nil {
+		return err
+	}
+
+	processed, changed := 0, 0
+	afterID := 0
+	for {
+		batch, err := news.ListNewsForRetag(ctx, afterID, batchSize)
+		if err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			break
+		}
+		for _, n := range batch {
+			afterID = n.NewsID
+			processed++
+
+			text := n.DescriptionText
+			if n.ContentText != "" {
+				text += "\n" + n.ContentText
+			}
+			result := engine.Classify(tagging.Document{
+				Source:         n.Source,
+				Language:       n.Language,
+				Title:          n.Title,
+				Text:           text,
+				Link:           n.Link,
+				FeedCategories: n.FeedCategories,
+			})
+			if result.Category == n.Category && slices.Equal(result.Tags, n.Tag) {
+				continue
+			}
+			changed++
+
+			if dryRun {
+				log.Info("News would be retagged",
+					slog.Int("newsID", n.NewsID),
+					slog.String("category", result.Category),
+					slog.Any("tags", result.Tags),
+				)
+				continue
+			}
+			if err := news.UpdateNewsTags(ctx, n.NewsID, result.Category, result.Tags); err != nil {
+				return err
+			}
+		}
+		log.Info("Backfill batch processed",
+			slog.Int("processed", processed),
+			slog.Int("changed", changed),
+		)
+	}
+
+	log.Info("Backfill completed",
+		slog.Int("processed", processed),
+		slog.Int("changed", changed),
+		slog.Bool("dry_run", dryRun),
+	)
+	return nil
+}
@@ -0,0 +1,188 @@
+// Команда dlq показывает сообщения dead-letter топика и возвращает их
+// во входной топик для повторной обработки.
+//
+//	dlq inspect [-limit N]
+//	dlq replay (-all -consumer name | -partition P -offset O) [-since T] [-from-offset O] [-topic name] [-dry-run]
+//
+// Dead-letter топик общий для всех потребителей, поэтому -all требует
+// указать потребителя. -since и -from-offset отсекают уже возвращенные
+// сообщения: сообщения из dead-letter топика при повторе не удаляются.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"newsservice/internal/broker"
+	"newsservice/internal/dlq"
+	"newsservice/internal/infrastructure/config"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	command, args := os.Args[1], os.Args[2:]
+
+	log := slog.New(slog.NewTextHandler(os.Stderr, nil))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var err error
+	switch command {
+	case "inspect":
+		err = inspect(ctx, args)
+	case "replay":
+		err = replay(ctx, log, args)
+	default:
+		usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Error("DLQ command failed", slog.String("command", command), slog.Any("error", err))
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage:")
+	fmt.Fprintln(os.Stderr, "  dlq inspect [-config path] [-limit N]")
+	fmt.Fprintln(os.Stderr, "  dlq replay [-config path] (-all -consumer name | -partition P -offset O) [-since T] [-from-offset O] [-topic name] [-dry-run]")
+}
+
+func loadConfig(path string) (*config.Config, error) {
+	cfg, err := config.LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Kafka.Topics.DeadLetter == "" {
+		return nil, errors.New("dead-letter topic is not configured")
+	}
+	return cfg, nil
+}
+
+// inspect печатает сообщения dead-letter топика в формате JSON Lines.
+func inspect(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("inspect", flag.ExitOnError)
+	configPath := fs.String("config", "configs/dev.yaml", "path to config file")
+	limit := fs.Int("limit", 0, "max messages per partition, 0 for all")
+	fs.Parse(args)
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+	entries, err := dlq.ReadAll(ctx, cfg.Kafka.Brokers, cfg.Kafka.Topics.DeadLetter, *limit)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(os.Stdout)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replay отправляет исходные сообщения обратно во входной топик.
+// Сообщения в dead-letter топике при этом остаются.
+func replay(ctx context.Context, log *slog.Logger, args []string) error {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	configPath := fs.String("config", "configs/dev.yaml", "path to config file")
+	all := fs.Bool("all", false, "replay every message of the consumer in the dead-letter topic")
+	consumer := fs.String("consumer", "", "replay only messages that failed in this consumer (kafka-gateway, ingest, search-indexer)")
+	since := fs.String("since", "", "replay only messages that failed after this time (RFC 3339) or within this duration (e.g. 2h)")
+	fromOffset := fs.Int64("from-offset", 0, "replay only messages at or after this dead-letter offset in each partition")
+	partition := fs.Int("partition", -1, "dead-letter partition of the message to replay")
+	offset := fs.Int64("offset", -1, "dead-letter offset of the message to replay")
+	topic := fs.String("topic", "", "target topic, defaults to the original topic of each message")
+	dryRun := fs.Bool("dry-run", false, "list messages that would be replayed")
+	fs.Parse(args)
+
+	if !*all && (*partition < 0 || *offset < 0) {
+		return errors.New("either -all or both -partition and -offset are required")
+	}
+	if *all && *consumer == "" {
+		return errors.New("-all requires -consumer: the dead-letter topic is shared by all consumers")
+	}
+	filter := dlq.Filter{Consumer: *consumer, FromOffset: *fromOffset}
+	if *since != "" {
+		t, err := parseSince(*since, time.Now())
+		if err != nil {
+			return err
+		}
+		filter.Since = t
+	}
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+	entries, err := dlq.ReadAll(ctx, cfg.Kafka.Brokers, cfg.Kafka.Topics.DeadLetter, 0)
+	if err != nil {
+		return err
+	}
+
+	publisher := broker.NewKafkaPublisher(cfg.Kafka.Brokers)
+	defer publisher.Close()
+
+	replayed := 0
+	for _, e := range entries {
+		if !*all && (e.Partition != *partition || e.Offset != *offset) {
+			continue
+		}
+		if !filter.Match(e) {
+			continue
+		}
+		log := log.With(slog.Int("partition", e.Partition), slog.Int64("offset", e.Offset))
+		if e.Raw != nil {
+			log.Warn("Skipping unreadable dead-letter message")
+			continue
+		}
+		target := *topic
+		if target == "" {
+			target = e.Message.OriginalTopic
+		}
+		if target == "" {
+			log.Warn("Skipping message without original topic")
+			continue
+		}
+		if *dryRun {
+			log.Info("Message would be replayed", slog.String("topic", target), slog.String("error", e.Message.Error))
+			replayed++
+			continue
+		}
+		if err := publisher.Publish(ctx, target, e.Message.Key, e.Message.Payload); err != nil {
+			return fmt.Errorf("failed to replay message %d:%d: %w", e.Partition, e.Offset, err)
+		}
+		log.Info("Message replayed", slog.String("topic", target))
+		replayed++
+	}
+	if !*all && replayed == 0 {
+		return fmt.Errorf("message %d:%d not found", *partition, *offset)
+	}
+	log.Info("Replay completed", slog.Int("replayed", replayed), slog.Bool("dry_run", *dryRun))
+	return nil
+}
+
+// parseSince разбирает момент времени в формате RFC 3339 или длительность,
+// отсчитываемую назад от now.
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid -since %q: want RFC 3339 time or duration", value)
+	}
+	return t, nil
+}
@@ -1 +1,24 @@
 package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"newsservice/internal/app"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+func main() {
+	configPath := flag.String("config", "configs/dev.yaml", "path to config file")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := app.Run(ctx, *configPath); err != nil {
+		slog.Error("Newsservice stopped with error", slog.Any("error", err))
+		os.Exit(1)
+	}
+}
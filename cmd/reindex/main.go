@@ -0,0 +1,94 @@
+// Команда reindex заново строит встроенный поисковый индекс по всем
+// сохраненным новостям. Сервис, использующий тот же каталог индекса,
+// на время перестроения должен быть остановлен.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"newsservice/internal/infrastructure/config"
+	"newsservice/internal/search"
+	"newsservice/storage"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+func main() {
+	configPath := flag.String("config", "configs/dev.yaml", "path to config file")
+	indexDir := flag.String("dir", "", "index directory, defaults to search.index_dir from config")
+	batchSize := flag.Int("batch", 500, "number of news indexed per batch")
+	reset := flag.Bool("reset", true, "drop the existing index before indexing")
+	flag.Parse()
+
+	log := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx, log, *configPath, *indexDir, *batchSize, *reset); err != nil {
+		log.Error("Reindex failed", slog.Any("error", err))
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, log *slog.Logger, configPath, indexDir string, batchSize int, reset bool) error {
+	if batchSize < 1 {
+		return fmt.Errorf("batch size must be positive")
+	}
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		return err
+	}
+	if indexDir == "" {
+		indexDir = cfg.Search.IndexDir
+	}
+	if indexDir == "" {
+		return errors.New("search index directory is not configured")
+	}
+	db, err := storage.Open(*cfg, log)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	news, ok := db.(storage.SearchIndexStorage)
+	if !ok {
+		return storage.Unsupported(cfg.DB.Driver, "search reindexing")
+	}
+
+	if reset {
+		if err := search.RemoveLocalIndex(indexDir); err != nil {
+			return err
+		}
+	}
+	index, err := search.OpenLocalIndex(indexDir)
+	if err != nil {
+		return err
+	}
+
+	indexed := 0
+	var afterID int64
+	for {
+		docs, err := news.ListNewsForIndex(ctx, afterID, batchSize)
+		if err != nil {
+			return err
+		}
+		if len(docs) == 0 {
+			break
+		}
+		if err := index.Index(ctx, docs...); err != nil {
+			return err
+		}
+		afterID = docs[len(docs)-1].ID
+		indexed += len(docs)
+		log.Info("Batch indexed", slog.Int64("last_id", afterID), slog.Int("indexed", indexed))
+	}
+	if err := index.Close(); err != nil {
+		return err
+	}
+	log.Info("Reindex finished", slog.String("dir", indexDir), slog.Int("indexed", indexed), slog.Int("documents", index.Len()))
+	return nil
+}
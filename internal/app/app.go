@@ -2,207 +2,281 @@ package app
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
+	"expvar"
 	"fmt"
-	"io"
-	"log"
+	"io/fs"
 	"log/slog"
 	"net/http"
+	"newsservice/api"
+	"newsservice/internal/blobstore"
+	"newsservice/internal/broker"
+	"newsservice/internal/canonicalizer"
+	"newsservice/internal/contentfilter"
+	"newsservice/internal/dedup"
+	"newsservice/internal/dlq"
+	"newsservice/internal/extraction"
+	"newsservice/internal/fetcher"
+	"newsservice/internal/imaging"
+	"newsservice/internal/infrastructure/config"
+	"newsservice/internal/ingest"
+	"newsservice/internal/langdetect"
 	"newsservice/internal/models"
-	"newsservice/internal/transport"
+	"newsservice/internal/outbox"
+	"newsservice/internal/pagination"
+	"newsservice/internal/parser"
+	"newsservice/internal/sanitizer"
+	"newsservice/internal/search"
+	"newsservice/internal/sources"
+	"newsservice/internal/summarizer"
+	"newsservice/internal/tagging"
+	transport "newsservice/internal/transport/http"
+	gateway "newsservice/internal/transport/kafka"
+	"newsservice/internal/usecase"
+	"newsservice/storage"
 	"os"
 	"strings"
 	"time"
 
-	kfk "github.com/Fau1con/kafkawrapper"
 	"github.com/joho/godotenv"
-	"gorm.io/driver/postgres"
 )
 
-// Config содержит настройки приложения
-type Config struct {
-	RSSsources []string `json:"source"`
-	Interval   int      `json:"interval"`
-	Brokers    []string `json:"brokers"`
-	Topic      []string `json:"topic"`
-}
-
-// Run запускает приложение Newsservice
-func Run() error {
-	ctxmain := context.Background()
+const (
+	defaultProcessingInterval = 3 * time.Minute
+	shutdownTimeout           = 10 * time.Second
+)
 
-	// Подключение к новостной БД
-	pool, err := postgres.New()
+// Run запускает сервис newsservice: обработку фидов по расписанию, HTTP API
+// и шлюз запросов Kafka. Работает до отмены ctx.
+func Run(ctx context.Context, configPath string) error {
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to load .env file: %w", err)
+	}
+	cfg, err := config.LoadConfig(configPath)
 	if err != nil {
-		log.Printf("Error DB connection: %v", err)
 		return err
 	}
-	defer pool.DB.Close()
+	log := newLogger(cfg.Logging)
 
-	// Инициализация API
-	apiInstance := api.New(pool)
+	db, err := storage.Open(*cfg, log)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	if migrator, ok := db.(storage.Migrator); ok {
+		if err := migrator.Migrate(ctx); err != nil {
+			return err
+		}
+	}
+	sourceStore, ok := db.(storage.SourceStorage)
+	if !ok {
+		return storage.Unsupported(cfg.DB.Driver, "the source registry")
+	}
 
-	// Инициализация Kafka клиентов
-	kafkaBrokers := os.Getenv("KAFKA_BROKERS")
-	if kafkaBrokers == "" {
-		kafkaBrokers = "kafka:9093"
+	filter, err := contentfilter.New(cfg.Ingest.Filter, log)
+	if err != nil {
+		return err
+	}
+	detector, err := langdetect.New(cfg.Ingest.Language, log)
+	if err != nil {
+		return err
 	}
-	consumer, err := kfk.NewConsumer([]string{kafkaBrokers}, "news_input")
+	rulesStore, _ := db.(tagging.RulesStore)
+	rules, err := tagging.NewSource(cfg.Ingest.Tagging, rulesStore)
 	if err != nil {
-		log.Printf("Kafka consumer creating error: %v\n", err)
 		return err
 	}
-	producer, err := kfk.NewProducer([]string{kafkaBrokers})
-	log.Printf("Producer created! Broker: %v", kafkaBrokers)
+	tagger := tagging.NewEngine(rules, log)
+	if err := tagger.Reload(ctx); err != nil {
+		return err
+	}
+	go tagger.Watch(ctx, cfg.Ingest.Tagging.ReloadInterval)
+	extractor, err := extraction.New(cfg.Ingest.Extraction, log)
 	if err != nil {
-		log.Printf("Kafka creating producer error: %v\n", err)
 		return err
 	}
+	if err := extractor.Warm(ctx, db); err != nil {
+		return err
+	}
+
+	canonical := canonicalizer.New(cfg.Ingest.Canonical, log)
+	stages := []usecase.FeedStage{
+		filter,
+		sanitizer.New(log),
+		canonical,
+		detector,
+		dedup.NewFingerprinter(),
+		tagger,
+		extractor,
+		summarizer.New(cfg.Ingest.Summary, log),
+	}
+	var images blobstore.Store
+	if cfg.Ingest.Images.Enabled {
+		images, err = blobstore.NewLocalStore(cfg.Ingest.Images.StoreDir)
+		if err != nil {
+			return err
+		}
+		imageProcessor := imaging.New(cfg.Ingest.Images, images, log)
+		// Страницы новостей загружаются только вместе с поиском og:image,
+		// той же загрузкой канонизатор находит <link rel="canonical">.
+		if cfg.Ingest.Images.FetchPage {
+			canonical.UsePages(imageProcessor)
+		}
+		stages = append(stages, imageProcessor)
+	}
 
-	// Каналы для обработки новостей
-	newsStream := make(chan []models.NewsFullDetailed)
-	errorStream := make(chan error)
+	feedNames := make(map[string]string, len(cfg.App.FeedURLs))
+	for _, feed := range cfg.App.FeedURLs {
+		feedNames[feed.URL] = feed.Name
+	}
+	registry := sources.NewRegistry(cfg.App.FeedURLs, sourceStore)
+	processor := usecase.NewFeedProsessingUseCase(fetcher.New(time.Duration(cfg.App.FetchTimeout)*time.Second, cfg.App.AllowPrivateFeeds, log), parser.New(log), db, log, feedNames, stages...)
+	go runScheduler(ctx, processor, registry, cfg.GetAppProcesingInterval(), log)
 
-	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
-		Level: slog.LevelDebug,
-	}))
+	if cfg.HTTP.CursorSecret == "" {
+		log.Warn("Cursor secret is not configured, cursors will not survive a restart")
+	}
+	cursors := pagination.NewCursorCodec([]byte(cfg.HTTP.CursorSecret))
+	apiInstance := api.NewApi(db, cursors, log)
+	if images != nil {
+		apiInstance.Handle(models.ImagesPath, transport.HandleImages(images))
+	}
+	apiInstance.Handle("/admin/filter/dry-run", transport.HandleFilterDryRun(filter))
+	apiInstance.Handle("/admin/feeds/stats", transport.HandleFeedStats(processor))
+	apiInstance.Handle("/admin/tagging/reload", transport.HandleReloadRules(tagger))
+	apiInstance.Handle("/admin/debug/vars", expvar.Handler())
 
-	// Запуск парсеров RSS
-	for _, source := range config.RSSsources {
-		go asynParser(ctxmain, source, pool, newsStream, errorStream, config.Interval)
+	var index *search.LocalIndex
+	switch cfg.Search.Backend {
+	case "", "postgres":
+	case "local":
+		index, err = search.OpenLocalIndex(cfg.Search.IndexDir)
+		if err != nil {
+			return err
+		}
+		defer index.Close()
+		go index.AutoFlush(ctx, cfg.Search.FlushInterval, log)
+		apiInstance.UseSearcher(index)
+		log.Info("Using local search index", slog.String("dir", cfg.Search.IndexDir), slog.Int("documents", index.Len()))
+	default:
+		return fmt.Errorf("unknown search backend %q", cfg.Search.Backend)
 	}
 
-	// Горутина для добавления новостей в БД
-	go func() {
-		for new := range errorStream {
-			log.Println("Error:", err)
+	indexFed := false
+	if len(cfg.Kafka.Brokers) > 0 {
+		publisher := broker.NewKafkaPublisher(cfg.Kafka.Brokers)
+		defer publisher.Close()
+		if outboxStore, ok := db.(storage.OutboxStorage); ok {
+			go outbox.NewRelay(cfg.Outbox, outboxStore, publisher, log).Run(ctx)
+		} else {
+			log.Warn("Storage has no outbox, news events are not published", slog.String("driver", cfg.DB.Driver))
 		}
-	}()
 
-	// Горутина для обработки Kafka сообщений
-	go func() {
-		for {
-			log.Println("Start getting message and redirecting")
-			msg, err := consumer.GetMessages(ctxmain)
+		if topic := cfg.Kafka.Topics.NewsRequests; topic != "" {
+			consumer, err := broker.NewKafkaTopicSubscriber(cfg.Kafka.Brokers, topic)
 			if err != nil {
-				log.Printf("Failed to read message fron Kafka: %v\n", err)
+				return err
 			}
-			data, err := sendRequestToLocalhost(string(msg.Value))
+			deadLetters := dlq.NewProcessor("kafka-gateway", cfg.Kafka.Topics.DeadLetter, publisher, cfg.Kafka.MaxAttempts, cfg.Kafka.RetryBackoff, log)
+			gw := gateway.NewGateway(consumer, publisher, usecase.NewNewsQueryUseCase(db, cursors), cfg.Kafka.Topics.NewsReplies, cfg.Kafka.ReplyTopics, deadLetters, log)
+			go gw.Run(ctx)
+		}
+		keys, ok := db.(storage.IdempotencyStorage)
+		if topic := cfg.Kafka.Topics.NewsInput; ok && topic != "" {
+			consumer, err := broker.NewKafkaTopicSubscriber(cfg.Kafka.Brokers, topic)
 			if err != nil {
-				log.Printf("Failed to read data from Kafka message: %v\n", err)
-			}
-			// Маршрутизация по типам запросов
-			if strings.Contains(string(msg.Value), "/newsdetail") {
-				err := producer.SendMessage(ctxmain, config.Topic[1], data)
-				if err != nil {
-					log.Printf("Failedto write message to Kafka: %v\n", err)
-					return
-				}
-			}
-			if strings.Contains(string(msg.Value), "/newslist/?n=") {
-				err := producer.SendMessage(ctxmain, config.Topic[2], data)
-				if err != nil {
-					log.Printf("Failed to write message to Kafka: %v\n", err)
-					return
-				}
+				return err
 			}
-			if strings.Contains(string(msg.Value), "/newslist/filtered/?category=") {
-				err := producer.SendMessage(ctxmain, config.Topic[3], data)
-				if err != nil {
-					log.Printf("Failed to write message to Kafka: %v\n", err)
-					return
-				}
-			}
-			if strings.Contains(string(msg.Value), "newslist/filtered/date/?date=") {
-				err := producer.SendMessage(ctxmain, config.Topic[4], data)
-				if err != nil {
-					log.Printf("Failed to write message to Kafka: %v\n", err)
-					return
-				}
+			deadLetters := dlq.NewProcessor("ingest", cfg.Kafka.Topics.DeadLetter, publisher, cfg.Kafka.MaxAttempts, cfg.Kafka.RetryBackoff, log)
+			go ingest.NewConsumer(consumer, processor, registry, keys, deadLetters, log).Run(ctx)
+		}
+		documents, ok := db.(search.DocumentSource)
+		if topic := cfg.Kafka.Topics.NewsEvents; ok && index != nil && topic != "" {
+			consumer, err := broker.NewKafkaTopicSubscriber(cfg.Kafka.Brokers, topic)
+			if err != nil {
+				return err
 			}
+			deadLetters := dlq.NewProcessor("search-indexer", cfg.Kafka.Topics.DeadLetter, publisher, cfg.Kafka.MaxAttempts, cfg.Kafka.RetryBackoff, log)
+			go search.NewIndexer(consumer, documents, index, deadLetters, log).Run(ctx)
+			indexFed = true
 		}
-	}()
+	}
+	if index != nil && !indexFed {
+		log.Warn("Local search index is not fed by news events, run reindex to update it")
+	}
 
-	// Настройка роутера и middleware
+	if cfg.HTTP.AdminToken == "" {
+		log.Warn("Admin token is not configured, admin endpoints are disabled")
+	}
 	var handler http.Handler = apiInstance.Router()
-	handler = transport.RequestIDMiddleware(handler)
+	handler = transport.AdminAuthMiddleware(cfg.HTTP.AdminToken)(handler)
 	handler = transport.LoggingMiddleware(log)(handler)
+	handler = transport.RequestIDMiddleware(handler)
 
-	err = godotenv.Load()
-	if err != nil {
-		log.Fatalf("Failed to load .env file")
-		return err
+	server := &http.Server{
+		Addr:         fmt.Sprintf("%s:%d", cfg.GetHTTPHost(), cfg.GetHTTPPort()),
+		Handler:      handler,
+		ReadTimeout:  time.Duration(cfg.App.ReadTimeout) * time.Second,
+		WriteTimeout: time.Duration(cfg.App.WriteTimeout) * time.Second,
 	}
-	port := os.Getenv("PORT")
-
-	log.Printf("Server newsservice APP start working at port %v\n", port)
-	return http.ListenAndServe(port, handler)
-}
+	serveErr := make(chan error, 1)
+	go func() {
+		log.Info("HTTP server started", slog.String("addr", server.Addr))
+		serveErr <- server.ListenAndServe()
+	}()
 
-// parseConfigFile парсит JSON файл с настройками
-func parseConfigFile(filename string) (Config, error) {
-	var data []byte
-	configFile, err := os.Open(filename)
-	if err != nil {
-		log.Printf("Failed to open config file: %v\n", err)
-		return Config{}, err
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("http server failed: %w", err)
+	case <-ctx.Done():
 	}
-	defer configFile.Close()
 
-	data, err = os.ReadFile("./config.json")
-	if err != nil {
-		log.Fatal(err)
-	}
-	var config Config
-	if err := json.Unmarshal(data, &config); err != nil {
-		log.Printf("Failed to unmarshal config: %v\n", err)
-		return Config{}, err
+	log.Info("Shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to shut down http server: %w", err)
 	}
-	return config, nil
+	return nil
 }
 
-// asynParser асинхронно обрабатывает RSS-ленты
-func asynParser(ctx context.Context, source string, db DB.DBInterface, news chan<- []models.NewsFullDetailed, errs chan<- error, interval int) {
+// runScheduler обрабатывает все активные фиды реестра сразу и затем через
+// каждый interval. Список фидов перечитывается перед каждым проходом.
+func runScheduler(ctx context.Context, processor *usecase.FeedProcessingUseCase, registry *sources.Registry, interval time.Duration, log *slog.Logger) {
+	if interval <= 0 {
+		interval = defaultProcessingInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
 	for {
-		rssnews, err := rss.Parse(source)
+		active, err := registry.Active(ctx)
 		if err != nil {
-			errs <- err
-			continue
+			log.Error("Failed to list sources", slog.Any("error", err))
+		}
+		for _, src := range active {
+			if ctx.Err() != nil {
+				return
+			}
+			// Ошибка уже залогирована в ProcessSource; остальные фиды обрабатываются дальше.
+			_ = processor.ProcessSource(ctx, src)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
 		}
-		news <- rssnews
-		time.Sleep(time.Duration(interval) * time.Minute)
 	}
 }
 
-// sendRequestToLocalhost выполняет HTTP запрос к локальному сервису
-func sendRequestToLocalhost(path string) ([]byte, error) {
-	if path == "" {
-		return nil, fmt.Errorf("path cannot be empty")
-	}
-	url := fmt.Sprintf("http://localhost:6000%s", path)
-	req, err := http.NewRequest(http.MethodGet, url, nil)
-	if err != nil {
-		log.Printf("Failed to create request: %v\n", err)
-		return nil, err
-	}
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-	}
-	resp, err := client.Do(req)
-	if err != nil {
-		log.Printf("Failed to send request: %v\n", err)
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body) // вместо ioutil.ReadAll Стоит ли ограничить разер файла?
-	if err != nil {
-		log.Printf("Failed to read response: %v\n", err)
-		return nil, err
+// newLogger создает логгер по настройкам уровня и формата.
+func newLogger(cfg config.LoggingConfig) *slog.Logger {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(cfg.Level)); err != nil {
+		level = slog.LevelInfo
 	}
-	if resp.StatusCode != http.StatusOK {
-		log.Printf("Unexpected response code: %d\n", resp.StatusCode)
-		// Нужен ли return?
+	opts := &slog.HandlerOptions{Level: level}
+	if strings.EqualFold(cfg.Format, "json") {
+		return slog.New(slog.NewJSONHandler(os.Stdout, opts))
 	}
-	return body, nil
+	return slog.New(slog.NewTextHandler(os.Stdout, opts))
 }
@@ -0,0 +1,22 @@
+// Package blobstore описывает хранилище двоичных объектов (изображений)
+// и его реализацию на локальном диске.
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrNotFound возвращается, если объект с указанным ключом отсутствует.
+var ErrNotFound = errors.New("blob not found")
+
+// ErrInvalidKey возвращается для ключей, недопустимых в хранилище.
+var ErrInvalidKey = errors.New("invalid blob key")
+
+// Store — хранилище двоичных объектов с доступом по ключу вида "a/b/c.jpg".
+type Store interface {
+	Put(ctx context.Context, key string, data []byte) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Exists(ctx context.Context, key string) (bool, error)
+}
@@ -0,0 +1,36 @@
+// Package broker описывает контракт издателя и подписчика брокера сообщений.
+// Kafka подключается через адаптеры из kafka.go, для тестов и локального
+// запуска есть брокер в памяти процесса (Memory).
+package broker
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrClosed возвращается подписчиком после Close.
+var ErrClosed = errors.New("subscriber closed")
+
+// Message — сообщение топика. Offset — позиция сообщения в партиции.
+type Message struct {
+	Topic     string
+	Partition int
+	Offset    int64
+	Key       []byte
+	Value     []byte
+	Time      time.Time
+}
+
+// Publisher отправляет сообщения. Сообщения с одним ключом попадают в одну
+// партицию и читаются в порядке отправки; nil-ключ распределяет сообщения
+// по партициям произвольно.
+type Publisher interface {
+	Publish(ctx context.Context, topic string, key, value []byte) error
+}
+
+// Subscriber читает сообщения топика в составе группы потребителей. Смещение
+// фиксируется при получении сообщения, как в kafkawrapper.
+type Subscriber interface {
+	Receive(ctx context.Context) (Message, error)
+}
@@ -0,0 +1,80 @@
+package broker
+
+import (
+	"context"
+	"fmt"
+
+	kfk "github.com/Fau1con/kafkawrapper"
+	"github.com/segmentio/kafka-go"
+)
+
+var (
+	_ Publisher  = (*KafkaPublisher)(nil)
+	_ Subscriber = (*KafkaSubscriber)(nil)
+)
+
+// KafkaPublisher отправляет сообщения в Kafka. Сообщения с ключом распределяются
+// по партициям по хешу ключа, поэтому события одной новости читаются по порядку.
+type KafkaPublisher struct {
+	writer *kafka.Writer
+}
+
+func NewKafkaPublisher(brokers []string) *KafkaPublisher {
+	return &KafkaPublisher{
+		writer: &kafka.Writer{
+			Addr:         kafka.TCP(brokers...),
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: kafka.RequireAll,
+		},
+	}
+}
+
+// Publish отправляет сообщение с ключом партиционирования.
+func (p *KafkaPublisher) Publish(ctx context.Context, topic string, key, value []byte) error {
+	err := p.writer.WriteMessages(ctx, kafka.Message{
+		Topic: topic,
+		Key:   key,
+		Value: value,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write message to %s: %w", topic, err)
+	}
+	return nil
+}
+
+func (p *KafkaPublisher) Close() error {
+	return p.writer.Close()
+}
+
+// KafkaSubscriber читает сообщения через потребителя kafkawrapper.
+type KafkaSubscriber struct {
+	consumer kfk.Cons
+}
+
+func NewKafkaSubscriber(consumer kfk.Cons) *KafkaSubscriber {
+	return &KafkaSubscriber{consumer: consumer}
+}
+
+// NewKafkaTopicSubscriber создает потребителя kafkawrapper для топика.
+func NewKafkaTopicSubscriber(brokers []string, topic string) (*KafkaSubscriber, error) {
+	consumer, err := kfk.NewConsumer(brokers, topic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kafka consumer for %s: %w", topic, err)
+	}
+	return NewKafkaSubscriber(consumer), nil
+}
+
+func (s *KafkaSubscriber) Receive(ctx context.Context) (Message, error) {
+	msg, err := s.consumer.GetMessages(ctx)
+	if err != nil {
+		return Message{}, err
+	}
+	return Message{
+		Topic:     msg.Topic,
+		Partition: msg.Partition,
+		Offset:    msg.Offset,
+		Key:       msg.Key,
+		Value:     msg.Value,
+		Time:      msg.Time,
+	}, nil
+}
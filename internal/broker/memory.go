@@ -0,0 +1,221 @@
+package broker
+
+import (
+	"context"
+	"hash/fnv"
+	"slices"
+	"sync"
+	"time"
+)
+
+var (
+	_ Publisher  = (*Memory)(nil)
+	_ Subscriber = (*MemorySubscriber)(nil)
+)
+
+// Memory — брокер в памяти процесса с семантикой Kafka: топики разбиты на
+// партиции, группа потребителей делит партиции между участниками и хранит
+// смещения. Новая группа читает топик с начала. Сообщения не удаляются.
+type Memory struct {
+	mu         sync.Mutex
+	partitions int
+	topics     map[string]*memoryTopic
+	// changed закрывается и заменяется при каждой публикации и смене состава групп.
+	changed chan struct{}
+}
+
+type memoryTopic struct {
+	partitions [][]Message
+	groups     map[string]*memoryGroup
+	next       int
+}
+
+type memoryGroup struct {
+	offsets []int64
+	members []*MemorySubscriber
+}
+
+// MemorySubscriber — участник группы потребителей брокера Memory.
+type MemorySubscriber struct {
+	broker *Memory
+	topic  string
+	group  string
+	closed bool
+	cursor int
+}
+
+// NewMemory создает брокер, в котором у каждого топика partitions партиций.
+func NewMemory(partitions int) *Memory {
+	if partitions <= 0 {
+		partitions = 1
+	}
+	return &Memory{
+		partitions: partitions,
+		topics:     make(map[string]*memoryTopic),
+		changed:    make(chan struct{}),
+	}
+}
+
+// Publish добавляет сообщение в партицию, выбранную по хешу ключа.
+func (m *Memory) Publish(ctx context.Context, topic string, key, value []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t := m.topic(topic)
+	var p int
+	if key != nil {
+		h := fnv.New32a()
+		h.Write(key)
+		p = int(h.Sum32() % uint32(len(t.partitions)))
+	} else {
+		p = t.next
+		t.next = (t.next + 1) % len(t.partitions)
+	}
+	t.partitions[p] = append(t.partitions[p], Message{
+		Topic:     topic,
+		Partition: p,
+		Offset:    int64(len(t.partitions[p])),
+		Key:       slices.Clone(key),
+		Value:     slices.Clone(value),
+		Time:      time.Now(),
+	})
+	m.notify()
+	return nil
+}
+
+// Subscribe добавляет участника в группу потребителей топика. Партиции
+// перераспределяются между участниками группы.
+func (m *Memory) Subscribe(topic, group string) *MemorySubscriber {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	g := m.group(topic, group)
+	s := &MemorySubscriber{broker: m, topic: topic, group: group}
+	g.members = append(g.members, s)
+	m.notify()
+	return s
+}
+
+// Messages возвращает все сообщения топика по партициям и смещениям.
+func (m *Memory) Messages(topic string) []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var out []Message
+	if t, ok := m.topics[topic]; ok {
+		for _, p := range t.partitions {
+			out = append(out, p...)
+		}
+	}
+	return out
+}
+
+// Lag возвращает число сообщений топика, еще не прочитанных группой.
+func (m *Memory) Lag(topic, group string) int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	g := m.group(topic, group)
+	t := m.topics[topic]
+	var lag int64
+	for p, msgs := range t.partitions {
+		lag += int64(len(msgs)) - g.offsets[p]
+	}
+	return lag
+}
+
+// Receive возвращает следующее сообщение из партиций, назначенных участнику,
+// и сдвигает смещение группы. Если сообщений нет, ждет их или отмены ctx.
+func (s *MemorySubscriber) Receive(ctx context.Context) (Message, error) {
+	m := s.broker
+	for {
+		m.mu.Lock()
+		if s.closed {
+			m.mu.Unlock()
+			return Message{}, ErrClosed
+		}
+		if msg, ok := s.next(); ok {
+			m.mu.Unlock()
+			return msg, nil
+		}
+		changed := m.changed
+		m.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return Message{}, ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+// Close выводит участника из группы; его партиции достаются остальным.
+func (s *MemorySubscriber) Close() error {
+	m := s.broker
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	g := m.group(s.topic, s.group)
+	g.members = slices.DeleteFunc(g.members, func(member *MemorySubscriber) bool { return member == s })
+	m.notify()
+	return nil
+}
+
+// next выбирает сообщение из назначенных партиций по кругу. Вызывается под m.mu.
+func (s *MemorySubscriber) next() (Message, bool) {
+	m := s.broker
+	t := m.topic(s.topic)
+	g := m.group(s.topic, s.group)
+	index := slices.Index(g.members, s)
+	n := len(t.partitions)
+	for i := 0; i < n; i++ {
+		p := (s.cursor + i) % n
+		// Партиция p принадлежит участнику с номером p mod число участников.
+		if p%len(g.members) != index {
+			continue
+		}
+		if g.offsets[p] < int64(len(t.partitions[p])) {
+			msg := t.partitions[p][g.offsets[p]]
+			g.offsets[p]++
+			s.cursor = p + 1
+			return msg, true
+		}
+	}
+	return Message{}, false
+}
+
+// topic возвращает топик, создавая его при первом обращении. Вызывается под m.mu.
+func (m *Memory) topic(name string) *memoryTopic {
+	t, ok := m.topics[name]
+	if !ok {
+		t = &memoryTopic{
+			partitions: make([][]Message, m.partitions),
+			groups:     make(map[string]*memoryGroup),
+		}
+		m.topics[name] = t
+	}
+	return t
+}
+
+// group возвращает группу потребителей топика. Вызывается под m.mu.
+func (m *Memory) group(topic, name string) *memoryGroup {
+	t := m.topic(topic)
+	g, ok := t.groups[name]
+	if !ok {
+		g = &memoryGroup{offsets: make([]int64, len(t.partitions))}
+		t.groups[name] = g
+	}
+	return g
+}
+
+func (m *Memory) notify() {
+	close(m.changed)
+	m.changed = make(chan struct{})
+}
@@ -0,0 +1,148 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func receive(t *testing.T, s Subscriber) Message {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	msg, err := s.Receive(ctx)
+	if err != nil {
+		t.Fatalf("receive: %v", err)
+	}
+	return msg
+}
+
+func publish(t *testing.T, m *Memory, topic, key, value string) {
+	t.Helper()
+	var k []byte
+	if key != "" {
+		k = []byte(key)
+	}
+	if err := m.Publish(context.Background(), topic, k, []byte(value)); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+}
+
+func TestMemoryKeyKeepsOrderWithinPartition(t *testing.T) {
+	m := NewMemory(4)
+	for i := 0; i < 10; i++ {
+		publish(t, m, "events", "news-1", fmt.Sprint(i))
+	}
+	msgs := m.Messages("events")
+	if len(msgs) != 10 {
+		t.Fatalf("got %d messages, want 10", len(msgs))
+	}
+	for i, msg := range msgs {
+		if msg.Partition != msgs[0].Partition {
+			t.Fatalf("message %d in partition %d, want %d", i, msg.Partition, msgs[0].Partition)
+		}
+		if msg.Offset != int64(i) || string(msg.Value) != fmt.Sprint(i) {
+			t.Fatalf("message %d = offset %d value %q", i, msg.Offset, msg.Value)
+		}
+	}
+}
+
+func TestMemoryGroupsReadIndependently(t *testing.T) {
+	m := NewMemory(2)
+	publish(t, m, "news", "", "a")
+	publish(t, m, "news", "", "b")
+
+	for _, group := range []string{"indexer", "notifier"} {
+		s := m.Subscribe("news", group)
+		got := map[string]bool{}
+		got[string(receive(t, s).Value)] = true
+		got[string(receive(t, s).Value)] = true
+		if !got["a"] || !got["b"] {
+			t.Fatalf("group %s received %v, want a and b", group, got)
+		}
+		if lag := m.Lag("news", group); lag != 0 {
+			t.Fatalf("group %s lag = %d, want 0", group, lag)
+		}
+	}
+}
+
+func TestMemoryMembersSplitPartitions(t *testing.T) {
+	m := NewMemory(4)
+	first := m.Subscribe("news", "workers")
+	second := m.Subscribe("news", "workers")
+	for i := 0; i < 40; i++ {
+		publish(t, m, "news", fmt.Sprint("key-", i), fmt.Sprint(i))
+	}
+
+	seen := map[string]int{}
+	owners := map[int]*MemorySubscriber{}
+	for m.Lag("news", "workers") > 0 {
+		for _, s := range []*MemorySubscriber{first, second} {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+			msg, err := s.Receive(ctx)
+			cancel()
+			if err != nil {
+				continue
+			}
+			if owner, ok := owners[msg.Partition]; ok && owner != s {
+				t.Fatalf("partition %d consumed by both members", msg.Partition)
+			}
+			owners[msg.Partition] = s
+			seen[string(msg.Value)]++
+		}
+	}
+	if len(seen) != 40 {
+		t.Fatalf("received %d distinct messages, want 40", len(seen))
+	}
+	for value, n := range seen {
+		if n != 1 {
+			t.Fatalf("message %s received %d times", value, n)
+		}
+	}
+	if owners[0] == owners[1] {
+		t.Fatalf("partitions 0 and 1 assigned to the same member")
+	}
+}
+
+func TestMemoryOffsetsSurviveResubscribe(t *testing.T) {
+	m := NewMemory(1)
+	publish(t, m, "news", "", "a")
+	publish(t, m, "news", "", "b")
+
+	s := m.Subscribe("news", "api")
+	if got := string(receive(t, s).Value); got != "a" {
+		t.Fatalf("first message = %q, want a", got)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if _, err := s.Receive(context.Background()); !errors.Is(err, ErrClosed) {
+		t.Fatalf("receive after close = %v, want ErrClosed", err)
+	}
+
+	s = m.Subscribe("news", "api")
+	if got := string(receive(t, s).Value); got != "b" {
+		t.Fatalf("message after resubscribe = %q, want b", got)
+	}
+}
+
+func TestMemoryReceiveWaitsForPublish(t *testing.T) {
+	m := NewMemory(2)
+	s := m.Subscribe("news", "api")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := s.Receive(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("receive on empty topic = %v, want deadline exceeded", err)
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		publish(t, m, "news", "k", "late")
+	}()
+	if got := string(receive(t, s).Value); got != "late" {
+		t.Fatalf("received %q, want late", got)
+	}
+}
@@ -0,0 +1,244 @@
+package canonicalizer
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/url"
+	"newsservice/internal/domain"
+	"newsservice/internal/infrastructure/config"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// maxRemembered ограничивает кэш ссылок, для которых страница уже загружалась.
+const maxRemembered = 10_000
+
+// DefaultStripParams — параметры запроса, удаляемые из ссылок, если список не задан в конфигурации.
+// Значение с завершающей звездочкой задает префикс.
+var DefaultStripParams = []string{
+	"utm_*",
+	"at_*",
+	"fbclid",
+	"gclid",
+	"yclid",
+	"dclid",
+	"msclkid",
+	"igshid",
+	"mc_cid",
+	"mc_eid",
+	"_ga",
+	"_gl",
+	"ocid",
+	"cmpid",
+	"smid",
+	"ref_src",
+}
+
+// PageFetcher загружает HTML-страницу новости.
+type PageFetcher interface {
+	FetchPage(ctx context.Context, url string) ([]byte, error)
+}
+
+type Canonicalizer struct {
+	log        *slog.Logger
+	exact      map[string]bool
+	prefixes   []string
+	forceHTTPS bool
+	pages      PageFetcher
+
+	mu       sync.Mutex
+	resolved map[string]string
+}
+
+func New(cfg config.CanonicalConfig, log *slog.Logger) *Canonicalizer {
+	params := cfg.StripParams
+	if len(params) == 0 {
+		params = DefaultStripParams
+	}
+	c := &Canonicalizer{
+		log:        log,
+		exact:      make(map[string]bool, len(params)),
+		forceHTTPS: cfg.ForceHTTPS,
+		resolved:   make(map[string]string),
+	}
+	for _, p := range params {
+		p = strings.ToLower(strings.TrimSpace(p))
+		if prefix, ok := strings.CutSuffix(p, "*"); ok {
+			c.prefixes = append(c.prefixes, prefix)
+			continue
+		}
+		c.exact[p] = true
+	}
+	return c
+}
+
+// Name возвращает имя этапа конвейера обработки.
+func (c *Canonicalizer) Name() string {
+	return "canonicalize"
+}
+
+// UsePages включает переход по <link rel="canonical"> страниц новостей,
+// загружаемых pages. Вызывается до запуска конвейера.
+func (c *Canonicalizer) UsePages(pages PageFetcher) {
+	c.pages = pages
+}
+
+// Process приводит ссылки элементов фида к каноническому виду, сохраняя исходную ссылку.
+// Если задан загрузчик страниц, ссылка заменяется на <link rel="canonical"> страницы.
+func (c *Canonicalizer) Process(ctx context.Context, feed *domain.Feed) error {
+	for i := range feed.Items {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		item := &feed.Items[i]
+		if item.OriginalLink == "" {
+			item.OriginalLink = item.Link
+		}
+		item.Link = c.Canonicalize(item.Link)
+		if c.pages != nil {
+			item.Link = c.follow(ctx, item.Link)
+		}
+	}
+	return nil
+}
+
+// follow загружает страницу новости и возвращает ее каноническую ссылку.
+// Результат запоминается, чтобы не загружать страницу при каждом опросе фида;
+// при ошибке загрузки ссылка остается прежней.
+func (c *Canonicalizer) follow(ctx context.Context, link string) string {
+	if link == "" {
+		return link
+	}
+	c.mu.Lock()
+	resolved, ok := c.resolved[link]
+	c.mu.Unlock()
+	if ok {
+		return resolved
+	}
+	resolved = link
+	page, err := c.pages.FetchPage(ctx, link)
+	if err != nil {
+		c.log.Debug("Failed to fetch page for canonical link", slog.String("link", link), slog.Any("error", err))
+	} else {
+		resolved = c.Resolve(link, bytes.NewReader(page))
+	}
+	c.mu.Lock()
+	if len(c.resolved) >= maxRemembered {
+		clear(c.resolved)
+	}
+	c.resolved[link] = resolved
+	c.mu.Unlock()
+	return resolved
+}
+
+// Canonicalize нормализует URL: схему и хост, порт по умолчанию, фрагмент,
+// завершающий слэш и трекинговые параметры запроса.
+func (c *Canonicalizer) Canonicalize(raw string) string {
+	raw = strings.TrimSpace(raw)
+	u, err := url.Parse(raw)
+	if err != nil || !u.IsAbs() || u.Host == "" {
+		return raw
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	if c.forceHTTPS && u.Scheme == "http" {
+		u.Scheme = "https"
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	port := u.Port()
+	if port == "80" || port == "443" || port == "" {
+		u.Host = host
+	} else {
+		u.Host = host + ":" + port
+	}
+
+	u.Fragment = ""
+	u.RawFragment = ""
+	u.Path = strings.TrimRight(u.Path, "/")
+	u.RawPath = ""
+
+	query := u.Query()
+	for key := range query {
+		if c.isStripped(key) {
+			query.Del(key)
+		}
+	}
+	u.RawQuery = query.Encode()
+
+	return u.String()
+}
+
+// Resolve возвращает каноническую ссылку загруженной страницы: если в ней
+// указан <link rel="canonical">, используется он, иначе сам pageURL.
+func (c *Canonicalizer) Resolve(pageURL string, page io.Reader) string {
+	if canonical, ok := ExtractCanonical(page, pageURL); ok {
+		return c.Canonicalize(canonical)
+	}
+	return c.Canonicalize(pageURL)
+}
+
+func (c *Canonicalizer) isStripped(key string) bool {
+	key = strings.ToLower(key)
+	if c.exact[key] {
+		return true
+	}
+	for _, prefix := range c.prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// ExtractCanonical ищет в HTML-странице <link rel="canonical"> и возвращает
+// его адрес, разрешенный относительно pageURL. Принимаются только http(s) ссылки.
+func ExtractCanonical(page io.Reader, pageURL string) (string, bool) {
+	z := html.NewTokenizer(page)
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return "", false
+		case html.EndTagToken:
+			if name, _ := z.TagName(); atom.Lookup(name) == atom.Head {
+				return "", false
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tok := z.Token()
+			if tok.DataAtom == atom.Body {
+				return "", false
+			}
+			if tok.DataAtom != atom.Link {
+				continue
+			}
+			var rel, href string
+			for _, a := range tok.Attr {
+				switch strings.ToLower(a.Key) {
+				case "rel":
+					rel = strings.ToLower(a.Val)
+				case "href":
+					href = strings.TrimSpace(a.Val)
+				}
+			}
+			if href == "" || !strings.Contains(" "+rel+" ", " canonical ") {
+				continue
+			}
+			ref, err := url.Parse(href)
+			if err != nil {
+				continue
+			}
+			if base, err := url.Parse(pageURL); err == nil {
+				ref = base.ResolveReference(ref)
+			}
+			if (ref.Scheme != "http" && ref.Scheme != "https") || ref.Host == "" {
+				continue
+			}
+			return ref.String(), true
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package canonicalizer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"newsservice/internal/domain"
+	"newsservice/internal/infrastructure/config"
+	"strings"
+	"testing"
+)
+
+func TestCanonicalize(t *testing.T) {
+	c := New(config.CanonicalConfig{ForceHTTPS: true}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	cases := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{"utm params", "https://example.com/a?utm_source=rss&utm_medium=feed&id=5", "https://example.com/a?id=5"},
+		{"click ids", "https://example.com/a?fbclid=abc&gclid=def&yclid=1", "https://example.com/a"},
+		{"param case", "https://example.com/a?UTM_Campaign=x&FBCLID=y&page=2", "https://example.com/a?page=2"},
+		{"prefix only", "https://example.com/a?utmost=1", "https://example.com/a?utmost=1"},
+		{"force https", "http://example.com/a", "https://example.com/a"},
+		{"scheme and host case", "HTTPS://Example.COM./News", "https://example.com/News"},
+		{"default https port", "https://example.com:443/a", "https://example.com/a"},
+		{"default http port", "http://example.com:80/a", "https://example.com/a"},
+		{"custom port", "https://example.com:8443/a", "https://example.com:8443/a"},
+		{"trailing slash", "https://example.com/news/item/", "https://example.com/news/item"},
+		{"root slash", "https://example.com/", "https://example.com"},
+		{"fragment", "https://example.com/a#comments", "https://example.com/a"},
+		{"query order", "https://example.com/a?b=2&a=1&c=3", "https://example.com/a?a=1&b=2&c=3"},
+		{"repeated param", "https://example.com/a?tag=b&tag=a", "https://example.com/a?tag=b&tag=a"},
+		{"encoded path", "https://example.com/%D0%BD%D0%BE%D0%B2%D0%BE%D1%81%D1%82%D0%B8/", "https://example.com/%D0%BD%D0%BE%D0%B2%D0%BE%D1%81%D1%82%D0%B8"},
+		{"whitespace", "  https://example.com/a?utm_source=x  ", "https://example.com/a"},
+		{"relative kept", "/news/1?utm_source=x", "/news/1?utm_source=x"},
+		{"invalid kept", "http://[::1", "http://[::1"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := c.Canonicalize(tc.raw); got != tc.want {
+				t.Fatalf("Canonicalize(%q) = %q, want %q", tc.raw, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCanonicalizeConfig(t *testing.T) {
+	c := New(config.CanonicalConfig{StripParams: []string{"ref", " Track_* "}}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	got := c.Canonicalize("http://example.com/a?ref=x&track_id=1&utm_source=rss")
+	if want := "http://example.com/a?utm_source=rss"; got != want {
+		t.Fatalf("Canonicalize() = %q, want %q", got, want)
+	}
+}
+
+func TestProcessKeepsOriginalLink(t *testing.T) {
+	c := New(config.CanonicalConfig{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	feed := &domain.Feed{Items: []domain.Item{
+		{Link: "https://example.com/a/?utm_source=rss"},
+		{Link: "https://example.com/b", OriginalLink: "https://example.com/b?from=feed"},
+	}}
+	if err := c.Process(context.Background(), feed); err != nil {
+		t.Fatal(err)
+	}
+	first, second := feed.Items[0], feed.Items[1]
+	if first.Link != "https://example.com/a" || first.OriginalLink != "https://example.com/a/?utm_source=rss" {
+		t.Fatalf("first item = %q, %q", first.Link, first.OriginalLink)
+	}
+	if second.OriginalLink != "https://example.com/b?from=feed" {
+		t.Fatalf("second original link = %q", second.OriginalLink)
+	}
+}
+
+func TestExtractCanonical(t *testing.T) {
+	cases := []struct {
+		name string
+		page string
+		want string
+	}{
+		{"absolute", `<html><head><link rel="canonical" href="https://example.com/news/1"></head></html>`, "https://example.com/news/1"},
+		{"relative", `<head><link href="/news/1?utm_source=x" rel="canonical"/></head>`, "https://example.com/news/1?utm_source=x"},
+		{"rel list", `<head><link rel="alternate canonical" href="https://example.com/c"></head>`, "https://example.com/c"},
+		{"other links", `<head><link rel="stylesheet" href="/s.css"><link rel="amphtml" href="/amp/1"></head>`, ""},
+		{"after head", `<head></head><body><link rel="canonical" href="https://example.com/late"></body>`, ""},
+		{"unsupported scheme", `<head><link rel="canonical" href="javascript:alert(1)"></head>`, ""},
+		{"empty href", `<head><link rel="canonical" href=" "></head>`, ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := ExtractCanonical(strings.NewReader(tc.page), "https://example.com/news/1?from=rss")
+			if got != tc.want || ok != (tc.want != "") {
+				t.Fatalf("ExtractCanonical() = %q, %v, want %q", got, ok, tc.want)
+			}
+		})
+	}
+}
+
+// fakePages отдает страницы по ссылке и считает загрузки.
+type fakePages struct {
+	pages   map[string]string
+	fetched []string
+}
+
+func (f *fakePages) FetchPage(ctx context.Context, url string) ([]byte, error) {
+	f.fetched = append(f.fetched, url)
+	page, ok := f.pages[url]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return []byte(page), nil
+}
+
+func TestProcessFollowsCanonical(t *testing.T) {
+	c := New(config.CanonicalConfig{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	pages := &fakePages{pages: map[string]string{
+		"https://example.com/amp/1":  `<head><link rel="canonical" href="/news/1/?utm_medium=amp"></head>`,
+		"https://example.com/news/2": `<head><title>No canonical</title></head>`,
+	}}
+	c.UsePages(pages)
+	items := func() *domain.Feed {
+		return &domain.Feed{Items: []domain.Item{
+			{Link: "https://example.com/amp/1?utm_source=rss"},
+			{Link: "https://example.com/news/2"},
+			{Link: "https://example.com/missing"},
+		}}
+	}
+	want := []string{"https://example.com/news/1", "https://example.com/news/2", "https://example.com/missing"}
+	for range 2 {
+		feed := items()
+		if err := c.Process(context.Background(), feed); err != nil {
+			t.Fatal(err)
+		}
+		for i, item := range feed.Items {
+			if item.Link != want[i] {
+				t.Fatalf("item %d link = %q, want %q", i, item.Link, want[i])
+			}
+		}
+		if feed.Items[0].OriginalLink != "https://example.com/amp/1?utm_source=rss" {
+			t.Fatalf("original link = %q", feed.Items[0].OriginalLink)
+		}
+	}
+	// Повторный опрос фида не загружает страницы снова.
+	if len(pages.fetched) != 3 {
+		t.Fatalf("fetched = %v, want each page once", pages.fetched)
+	}
+}
@@ -0,0 +1,236 @@
+// Package contentfilter отбрасывает элементы фида по правилам включения и
+// исключения (рекламные публикации, заглушки онлайн-трансляций и т.п.).
+package contentfilter
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"newsservice/internal/domain"
+	"newsservice/internal/infrastructure/config"
+	"regexp"
+	"strings"
+)
+
+// Поля элемента, доступные в правилах.
+const (
+	FieldTitle    = "title"
+	FieldLink     = "link"
+	FieldCategory = "category"
+	FieldAuthor   = "author"
+)
+
+// Области действия правил.
+const (
+	ScopeGlobal = "global"
+	ScopeSource = "source"
+)
+
+type rule struct {
+	name     string
+	field    string
+	contains string
+	regex    *regexp.Regexp
+}
+
+type scope struct {
+	include []rule
+	exclude []rule
+}
+
+type ruleSet struct {
+	global  scope
+	sources map[string]scope
+}
+
+// Decision — результат проверки элемента правилами.
+type Decision struct {
+	Keep  bool   `json:"keep"`
+	Scope string `json:"scope,omitempty"`
+	Rule  string `json:"rule,omitempty"`
+	// Reason — "exclude", если сработало правило исключения, или "include",
+	// если элемент не подошел ни под одно правило включения.
+	Reason string `json:"reason,omitempty"`
+}
+
+type Filter struct {
+	log   *slog.Logger
+	rules *ruleSet
+}
+
+func New(cfg config.FilterConfig, log *slog.Logger) (*Filter, error) {
+	set, err := compile(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Filter{log: log, rules: set}, nil
+}
+
+// Name возвращает имя этапа конвейера обработки.
+func (f *Filter) Name() string {
+	return "filter"
+}
+
+// Process удаляет из фида элементы, не прошедшие правила источника и общие правила.
+func (f *Filter) Process(ctx context.Context, feed *domain.Feed) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	set := f.rules
+	kept := feed.Items[:0]
+	for _, item := range feed.Items {
+		d := set.evaluate(feed.Source, item)
+		if d.Keep {
+			kept = append(kept, item)
+			continue
+		}
+		f.log.Debug("Item filtered out",
+			slog.String("feed", feed.Source),
+			slog.String("link", item.Link),
+			slog.String("scope", d.Scope),
+			slog.String("rule", d.Rule),
+			slog.String("reason", d.Reason),
+		)
+	}
+	clear(feed.Items[len(kept):])
+	feed.Items = kept
+	return nil
+}
+
+// Evaluate проверяет элемент источника действующими правилами без его удаления.
+func (f *Filter) Evaluate(source string, item domain.Item) Decision {
+	return f.rules.evaluate(source, item)
+}
+
+// Evaluate проверяет элементы правилами cfg, не меняя действующие правила.
+func Evaluate(cfg config.FilterConfig, source string, items []domain.Item) ([]Decision, error) {
+	set, err := compile(cfg)
+	if err != nil {
+		return nil, err
+	}
+	decisions := make([]Decision, len(items))
+	for i, item := range items {
+		decisions[i] = set.evaluate(source, item)
+	}
+	return decisions, nil
+}
+
+// evaluate применяет сначала правила источника, затем общие. В каждой области
+// элемент должен подойти хотя бы под одно правило включения (если они заданы)
+// и не подойти ни под одно правило исключения.
+func (s *ruleSet) evaluate(source string, item domain.Item) Decision {
+	if sc, ok := s.sources[source]; ok {
+		if d := sc.evaluate(item); !d.Keep {
+			d.Scope = ScopeSource
+			return d
+		}
+	}
+	if d := s.global.evaluate(item); !d.Keep {
+		d.Scope = ScopeGlobal
+		return d
+	}
+	return Decision{Keep: true}
+}
+
+func (sc scope) evaluate(item domain.Item) Decision {
+	for _, r := range sc.exclude {
+		if r.matches(item) {
+			return Decision{Rule: r.name, Reason: "exclude"}
+		}
+	}
+	if len(sc.include) == 0 {
+		return Decision{Keep: true}
+	}
+	for _, r := range sc.include {
+		if r.matches(item) {
+			return Decision{Keep: true}
+		}
+	}
+	return Decision{Reason: "include"}
+}
+
+func (r rule) matches(item domain.Item) bool {
+	for _, value := range fieldValues(item, r.field) {
+		if r.regex != nil && !r.regex.MatchString(value) {
+			continue
+		}
+		if r.contains != "" && !strings.Contains(strings.ToLower(value), r.contains) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+func fieldValues(item domain.Item, field string) []string {
+	switch field {
+	case FieldTitle:
+		return []string{item.Title}
+	case FieldLink:
+		return []string{item.Link}
+	case FieldAuthor:
+		return []string{item.Author}
+	case FieldCategory:
+		values := append([]string(nil), item.Categories...)
+		if item.Category != "" {
+			values = append(values, item.Category)
+		}
+		return values
+	}
+	return nil
+}
+
+func compile(cfg config.FilterConfig) (*ruleSet, error) {
+	set := &ruleSet{sources: make(map[string]scope, len(cfg.Sources))}
+	var err error
+	if set.global, err = compileScope(cfg.Global, ScopeGlobal); err != nil {
+		return nil, err
+	}
+	for source, rules := range cfg.Sources {
+		if set.sources[source], err = compileScope(rules, source); err != nil {
+			return nil, err
+		}
+	}
+	return set, nil
+}
+
+func compileScope(rules config.FilterRules, prefix string) (scope, error) {
+	var sc scope
+	var err error
+	if sc.include, err = compileRules(rules.Include, prefix+".include"); err != nil {
+		return scope{}, err
+	}
+	if sc.exclude, err = compileRules(rules.Exclude, prefix+".exclude"); err != nil {
+		return scope{}, err
+	}
+	return sc, nil
+}
+
+func compileRules(rules []config.FilterRule, prefix string) ([]rule, error) {
+	compiled := make([]rule, 0, len(rules))
+	for i, r := range rules {
+		name := r.Name
+		if name == "" {
+			name = fmt.Sprintf("%s[%d]", prefix, i)
+		}
+		field := strings.ToLower(strings.TrimSpace(r.Field))
+		switch field {
+		case FieldTitle, FieldLink, FieldCategory, FieldAuthor:
+		default:
+			return nil, fmt.Errorf("filter rule %s: unknown field %q", name, r.Field)
+		}
+		if r.Contains == "" && r.Regex == "" {
+			return nil, fmt.Errorf("filter rule %s: contains or regex is required", name)
+		}
+		c := rule{name: name, field: field, contains: strings.ToLower(r.Contains)}
+		if r.Regex != "" {
+			re, err := regexp.Compile(r.Regex)
+			if err != nil {
+				return nil, fmt.Errorf("filter rule %s: invalid regex: %w", name, err)
+			}
+			c.regex = re
+		}
+		compiled = append(compiled, c)
+	}
+	return compiled, nil
+}
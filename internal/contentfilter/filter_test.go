@@ -0,0 +1,156 @@
+package contentfilter
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"newsservice/internal/domain"
+	"newsservice/internal/infrastructure/config"
+	"strings"
+	"testing"
+)
+
+func newTestFilter(t *testing.T, cfg config.FilterConfig) *Filter {
+	t.Helper()
+	f, err := New(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func TestEvaluateOrder(t *testing.T) {
+	f := newTestFilter(t, config.FilterConfig{
+		Global: config.FilterRules{
+			Exclude: []config.FilterRule{{Name: "ads", Field: FieldTitle, Contains: "реклама"}},
+		},
+		Sources: map[string]config.FilterRules{
+			"lenta": {
+				Include: []config.FilterRule{{Name: "news-path", Field: FieldLink, Contains: "/news/"}},
+				Exclude: []config.FilterRule{{Name: "live", Field: FieldTitle, Regex: `(?i)^онлайн`}},
+			},
+		},
+	})
+	cases := []struct {
+		name   string
+		source string
+		item   domain.Item
+		want   Decision
+	}{
+		{"kept", "lenta", domain.Item{Title: "Новость", Link: "https://lenta.ru/news/1"},
+			Decision{Keep: true}},
+		{"source exclude before source include", "lenta", domain.Item{Title: "Онлайн-трансляция", Link: "https://lenta.ru/news/2"},
+			Decision{Scope: ScopeSource, Rule: "live", Reason: "exclude"}},
+		{"source include before global", "lenta", domain.Item{Title: "Реклама", Link: "https://lenta.ru/articles/3"},
+			Decision{Scope: ScopeSource, Reason: "include"}},
+		{"global after source", "lenta", domain.Item{Title: "Партнерская реклама", Link: "https://lenta.ru/news/4"},
+			Decision{Scope: ScopeGlobal, Rule: "ads", Reason: "exclude"}},
+		{"other source uses global only", "ria", domain.Item{Title: "Онлайн-трансляция", Link: "https://ria.ru/articles/5"},
+			Decision{Keep: true}},
+		{"other source global exclude", "ria", domain.Item{Title: "РЕКЛАМА"},
+			Decision{Scope: ScopeGlobal, Rule: "ads", Reason: "exclude"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := f.Evaluate(c.source, c.item); got != c.want {
+				t.Fatalf("Evaluate() = %+v, want %+v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestIncludeOnlyScope(t *testing.T) {
+	f := newTestFilter(t, config.FilterConfig{Sources: map[string]config.FilterRules{
+		"tass": {Include: []config.FilterRule{
+			{Field: FieldCategory, Contains: "политика"},
+			{Field: FieldAuthor, Contains: "редакция"},
+		}},
+	}})
+	feed := &domain.Feed{Source: "tass", Items: []domain.Item{
+		{Title: "a", Categories: []string{"Спорт", "Политика"}},
+		{Title: "b", Category: "politics", Author: "Редакция ТАСС"},
+		{Title: "c", Category: "Спорт"},
+		{Title: "d"},
+	}}
+	if err := f.Process(context.Background(), feed); err != nil {
+		t.Fatal(err)
+	}
+	var kept []string
+	for _, item := range feed.Items {
+		kept = append(kept, item.Title)
+	}
+	if got := strings.Join(kept, ","); got != "a,b" {
+		t.Fatalf("kept = %s, want a,b", got)
+	}
+	if d := f.Evaluate("tass", domain.Item{Title: "c"}); d.Rule != "" || d.Reason != "include" || d.Scope != ScopeSource {
+		t.Fatalf("decision = %+v", d)
+	}
+}
+
+func TestRegexAndContains(t *testing.T) {
+	f := newTestFilter(t, config.FilterConfig{Global: config.FilterRules{Exclude: []config.FilterRule{
+		{Name: "promo", Field: FieldLink, Contains: "promo", Regex: `/\d{4}/`},
+	}}})
+	cases := []struct {
+		link string
+		keep bool
+	}{
+		{"https://example.com/PROMO/2024/item", false},
+		{"https://example.com/promo/item", true},
+		{"https://example.com/news/2024/item", true},
+	}
+	for _, c := range cases {
+		if d := f.Evaluate("any", domain.Item{Link: c.link}); d.Keep != c.keep {
+			t.Fatalf("Evaluate(%s).Keep = %v, want %v", c.link, d.Keep, c.keep)
+		}
+	}
+}
+
+func TestCategoryMatchesCategoryAndCategories(t *testing.T) {
+	f := newTestFilter(t, config.FilterConfig{Global: config.FilterRules{Exclude: []config.FilterRule{
+		{Name: "horoscope", Field: FieldCategory, Regex: `^(?i)гороскоп`},
+	}}})
+	for _, item := range []domain.Item{
+		{Category: "Гороскопы"},
+		{Categories: []string{"Общество", "гороскоп на неделю"}},
+	} {
+		if d := f.Evaluate("any", item); d.Keep || d.Rule != "horoscope" {
+			t.Fatalf("Evaluate(%+v) = %+v, want excluded", item, d)
+		}
+	}
+	if d := f.Evaluate("any", domain.Item{Category: "Общество", Categories: []string{"Экономика"}}); !d.Keep {
+		t.Fatalf("decision = %+v, want kept", d)
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		cfg  config.FilterConfig
+		want string
+	}{
+		{"unknown field", config.FilterConfig{Global: config.FilterRules{
+			Include: []config.FilterRule{{Field: "body", Contains: "x"}},
+		}}, `filter rule global.include[0]: unknown field "body"`},
+		{"invalid regex", config.FilterConfig{Sources: map[string]config.FilterRules{
+			"ria": {Exclude: []config.FilterRule{{Name: "broken", Field: FieldTitle, Regex: "("}}},
+		}}, "filter rule broken: invalid regex"},
+		{"empty condition", config.FilterConfig{Sources: map[string]config.FilterRules{
+			"ria": {Exclude: []config.FilterRule{{}, {Field: FieldTitle}}},
+		}}, "filter rule ria.exclude[0]: unknown field"},
+		{"missing condition", config.FilterConfig{Global: config.FilterRules{
+			Exclude: []config.FilterRule{{Field: FieldTitle}},
+		}}, "filter rule global.exclude[0]: contains or regex is required"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := New(c.cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+			if err == nil || !strings.Contains(err.Error(), c.want) {
+				t.Fatalf("New() error = %v, want %q", err, c.want)
+			}
+			if _, err := Evaluate(c.cfg, "ria", nil); err == nil {
+				t.Fatal("Evaluate() accepted invalid rules")
+			}
+		})
+	}
+}
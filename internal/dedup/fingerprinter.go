@@ -0,0 +1,34 @@
+package dedup
+
+import (
+	"context"
+	"newsservice/internal/domain"
+)
+
+// Fingerprinter — этап конвейера, вычисляющий отпечатки заголовка и текста новостей.
+type Fingerprinter struct{}
+
+func NewFingerprinter() *Fingerprinter {
+	return &Fingerprinter{}
+}
+
+// Name возвращает имя этапа конвейера обработки.
+func (f *Fingerprinter) Name() string {
+	return "fingerprint"
+}
+
+// Process заполняет Fingerprint у каждого элемента фида.
+func (f *Fingerprinter) Process(ctx context.Context, feed *domain.Feed) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	for i := range feed.Items {
+		item := &feed.Items[i]
+		body := item.ContentText
+		if body == "" {
+			body = item.DescriptionText
+		}
+		item.Fingerprint = SimHash(item.Title + "\n" + body)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package dedup
+
+import (
+	"hash/fnv"
+	"math/bits"
+	"strings"
+	"unicode"
+)
+
+// shingleSize — количество слов в одном признаке (шингле) SimHash.
+const shingleSize = 2
+
+// Bands — число 16-битных полос отпечатка. Отпечатки на расстоянии меньше
+// Bands совпадают хотя бы в одной полосе, поэтому кандидатов можно искать по
+// индексу полос.
+const Bands = 4
+
+// SimHash вычисляет 64-битный отпечаток текста по словесным шинглам.
+// Для пустого текста возвращает 0.
+func SimHash(text string) uint64 {
+	features := shingles(tokenize(text))
+	if len(features) == 0 {
+		return 0
+	}
+
+	var weights [64]int
+	for _, f := range features {
+		h := fnv.New64a()
+		h.Write([]byte(f))
+		sum := h.Sum64()
+		for i := 0; i < 64; i++ {
+			if sum&(1<<uint(i)) != 0 {
+				weights[i]++
+			} else {
+				weights[i]--
+			}
+		}
+	}
+
+	var fp uint64
+	for i, w := range weights {
+		if w > 0 {
+			fp |= 1 << uint(i)
+		}
+	}
+	return fp
+}
+
+// Distance возвращает расстояние Хэмминга между двумя отпечатками.
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// Band возвращает i-ю 16-битную полосу отпечатка.
+func Band(fp uint64, i int) int64 {
+	return int64(fp >> (16 * uint(i)) & 0xffff)
+}
+
+// Nearest возвращает индекс ближайшего к fp отпечатка из candidates на
+// расстоянии не больше maxDistance или -1. При равном расстоянии выбирается
+// первый кандидат.
+func Nearest(fp uint64, candidates []uint64, maxDistance int) int {
+	best, bestDistance := -1, maxDistance+1
+	for i, c := range candidates {
+		if d := Distance(fp, c); d < bestDistance {
+			best, bestDistance = i, d
+		}
+	}
+	return best
+}
+
+// tokenize приводит текст к нижнему регистру и разбивает его на слова.
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// shingles строит признаки из последовательностей соседних слов.
+// Короткие тексты представляются отдельными словами.
+func shingles(tokens []string) []string {
+	if len(tokens) < shingleSize {
+		return tokens
+	}
+	out := make([]string, 0, len(tokens)-shingleSize+1)
+	for i := 0; i+shingleSize <= len(tokens); i++ {
+		out = append(out, strings.Join(tokens[i:i+shingleSize], " "))
+	}
+	return out
+}
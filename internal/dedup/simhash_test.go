@@ -0,0 +1,104 @@
+package dedup
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSimHashNearDuplicates(t *testing.T) {
+	const original = "Центральный банк сохранил ключевую ставку на уровне 16 процентов годовых. " +
+		"Регулятор отметил замедление инфляции и допустил снижение ставки на следующих заседаниях в этом году. " +
+		"По словам председателя, устойчивое снижение цен пока не подтверждено, а ожидания населения остаются высокими. " +
+		"Аналитики крупнейших банков ожидали такого решения: большинство опрошенных экономистов прогнозировали " +
+		"сохранение ставки и ужесточение риторики. Рубль после публикации решения укрепился к доллару и евро, " +
+		"а индекс Московской биржи вырос на полпроцента. Следующее заседание совета директоров по ставке " +
+		"запланировано на конец октября, к нему регулятор обновит макроэкономический прогноз."
+	cases := []struct {
+		name string
+		a, b string
+		near bool
+	}{
+		{"identical", original, original, true},
+		{"case and punctuation", original, strings.ToUpper(strings.NewReplacer(". ", "! ", ",", " —").Replace(original)), true},
+		{"one word edited", original, strings.Replace(original, "следующих", "ближайших", 1), true},
+		{"unrelated", original, "Сборная Бразилии обыграла Аргентину в финале турнира и завоевала кубок " +
+			"после серии пенальти на переполненном стадионе в Рио-де-Жанейро.", false},
+		{"unrelated english", "Oil prices rise as OPEC extends production cuts into next year",
+			"Local council approves new cycling lanes in the city centre after public consultation", false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			d := Distance(SimHash(c.a), SimHash(c.b))
+			if c.near && d > 3 {
+				t.Fatalf("distance = %d, want at most 3", d)
+			}
+			if !c.near && d <= 10 {
+				t.Fatalf("distance = %d, want more than 10", d)
+			}
+		})
+	}
+}
+
+func TestSimHashEmpty(t *testing.T) {
+	if fp := SimHash(" ,.- "); fp != 0 {
+		t.Fatalf("SimHash() = %x, want 0", fp)
+	}
+}
+
+func TestDistance(t *testing.T) {
+	cases := []struct {
+		a, b uint64
+		want int
+	}{
+		{0, 0, 0},
+		{0b1011, 0b0001, 2},
+		{0, ^uint64(0), 64},
+	}
+	for _, c := range cases {
+		if got := Distance(c.a, c.b); got != c.want {
+			t.Fatalf("Distance(%b, %b) = %d, want %d", c.a, c.b, got, c.want)
+		}
+	}
+}
+
+func TestBandsShareWithinDistance(t *testing.T) {
+	const fp = 0x0123456789abcdef
+	for _, flips := range [][]int{{0}, {1, 17}, {2, 20, 40}, {15, 31, 47}} {
+		other := uint64(fp)
+		for _, bit := range flips {
+			other ^= 1 << uint(bit)
+		}
+		shared := false
+		for i := 0; i < Bands; i++ {
+			if Band(fp, i) == Band(other, i) {
+				shared = true
+			}
+		}
+		if !shared {
+			t.Fatalf("fingerprints at distance %d share no band", Distance(fp, other))
+		}
+	}
+	if got := Band(fp, 3); got != 0x0123 {
+		t.Fatalf("Band(3) = %x, want 123", got)
+	}
+}
+
+func TestNearest(t *testing.T) {
+	const fp = 0b1111_0000
+	cases := []struct {
+		name       string
+		candidates []uint64
+		want       int
+	}{
+		{"no candidates", nil, -1},
+		{"too far", []uint64{0b0000_1111}, -1},
+		{"closest", []uint64{0b1100_0000, 0b1111_0001, 0b1110_0000}, 1},
+		{"first of equal", []uint64{0b1111_0010, 0b1111_0001}, 0},
+		{"exact limit", []uint64{0b1000_0011}, 0},
+	}
+	for _, c := range cases {
+		if got := Nearest(fp, c.candidates, 5); got != c.want {
+			t.Fatalf("%s: Nearest() = %d, want %d", c.name, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+// Package dlq обеспечивает ограниченное число попыток обработки сообщений
+// Kafka и перенос сообщений, которые обработать не удалось, в dead-letter топик.
+package dlq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"newsservice/internal/broker"
+	"runtime/debug"
+	"time"
+)
+
+// MessageVersion — версия формата сообщений dead-letter топика.
+const MessageVersion = 1
+
+const (
+	defaultMaxAttempts = 3
+	defaultBackoff     = 500 * time.Millisecond
+)
+
+// Message — сообщение dead-letter топика: исходное сообщение и сведения об ошибке.
+type Message struct {
+	Version       int       `json:"version"`
+	Consumer      string    `json:"consumer"`
+	OriginalTopic string    `json:"original_topic"`
+	Partition     int       `json:"partition"`
+	Offset        int64     `json:"offset"`
+	Key           []byte    `json:"key,omitempty"`
+	Payload       []byte    `json:"payload"`
+	Error         string    `json:"error"`
+	Permanent     bool      `json:"permanent"`
+	Attempts      int       `json:"attempts"`
+	FailedAt      time.Time `json:"failed_at"`
+}
+
+// HandlerFunc обрабатывает одно сообщение. Ошибка, обернутая Permanent,
+// означает, что повторные попытки бессмысленны.
+type HandlerFunc func(ctx context.Context, msg broker.Message) error
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent помечает ошибку как неустранимую повторными попытками.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent сообщает, помечена ли ошибка как неустранимая.
+func IsPermanent(err error) bool {
+	var p *permanentError
+	return errors.As(err, &p)
+}
+
+// Processor вызывает обработчик с ограниченным числом попыток и отправляет
+// необработанные сообщения в dead-letter топик.
+type Processor struct {
+	consumer    string
+	topic       string
+	producer    broker.Publisher
+	maxAttempts int
+	backoff     time.Duration
+	log         *slog.Logger
+}
+
+func NewProcessor(consumer, deadLetterTopic string, producer broker.Publisher, maxAttempts int, backoff time.Duration, log *slog.Logger) *Processor {
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+	if backoff <= 0 {
+		backoff = defaultBackoff
+	}
+	return &Processor{
+		consumer:    consumer,
+		topic:       deadLetterTopic,
+		producer:    producer,
+		maxAttempts: maxAttempts,
+		backoff:     backoff,
+		log:         log.With(slog.String("component", "dlq"), slog.String("consumer", consumer)),
+	}
+}
+
+// Process обрабатывает сообщение. Возвращает ошибку, только если сообщение не
+// удалось ни обработать, ни отправить в dead-letter топик.
+func (p *Processor) Process(ctx context.Context, msg broker.Message, handle HandlerFunc) error {
+	var err error
+	attempts := 0
+	for attempts < p.maxAttempts {
+		attempts++
+		err = safeCall(ctx, msg, handle)
+		if err == nil {
+			return nil
+		}
+		if IsPermanent(err) || ctx.Err() != nil {
+			break
+		}
+		p.log.Warn("Message processing failed, retrying",
+			slog.String("topic", msg.Topic),
+			slog.Int64("offset", msg.Offset),
+			slog.Int("attempt", attempts),
+			slog.Any("error", err),
+		)
+		if attempts < p.maxAttempts {
+			select {
+			case <-ctx.Done():
+			case <-time.After(p.backoff * time.Duration(1<<(attempts-1))):
+			}
+		}
+	}
+	return p.deadLetter(ctx, msg, err, attempts)
+}
+
+func (p *Processor) deadLetter(ctx context.Context, msg broker.Message, cause error, attempts int) error {
+	log := p.log.With(
+		slog.String("topic", msg.Topic),
+		slog.Int("partition", msg.Partition),
+		slog.Int64("offset", msg.Offset),
+	)
+	if p.topic == "" {
+		log.Error("Message dropped: dead-letter topic is not configured", slog.Any("error", cause))
+		return fmt.Errorf("message dropped after %d attempts: %w", attempts, cause)
+	}
+	payload, err := json.Marshal(Message{
+		Version:       MessageVersion,
+		Consumer:      p.consumer,
+		OriginalTopic: msg.Topic,
+		Partition:     msg.Partition,
+		Offset:        msg.Offset,
+		Key:           msg.Key,
+		Payload:       msg.Value,
+		Error:         cause.Error(),
+		Permanent:     IsPermanent(cause),
+		Attempts:      attempts,
+		FailedAt:      time.Now().UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode dead-letter message: %w", err)
+	}
+	// Сообщение должно попасть в dead-letter топик и при отмене основного контекста.
+	sendCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+	if err := p.producer.Publish(sendCtx, p.topic, msg.Key, payload); err != nil {
+		log.Error("Failed to send message to dead-letter topic", slog.Any("error", err))
+		return fmt.Errorf("failed to send message to dead-letter topic: %w", err)
+	}
+	log.Warn("Message moved to dead-letter topic",
+		slog.String("dead_letter_topic", p.topic),
+		slog.Int("attempts", attempts),
+		slog.Any("error", cause),
+	)
+	return nil
+}
+
+// safeCall вызывает обработчик, превращая панику в ошибку.
+func safeCall(ctx context.Context, msg broker.Message, handle HandlerFunc) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = Permanent(fmt.Errorf("panic: %v\n%s", r, debug.Stack()))
+		}
+	}()
+	return handle(ctx, msg)
+}
@@ -0,0 +1,144 @@
+package dlq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"newsservice/internal/broker"
+	"strings"
+	"testing"
+	"time"
+)
+
+const deadLetterTopic = "dead"
+
+func newTestProcessor(mem *broker.Memory, maxAttempts int) *Processor {
+	return NewProcessor("ingest", deadLetterTopic, mem, maxAttempts, time.Millisecond, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func testMessage() broker.Message {
+	return broker.Message{Topic: "news", Partition: 1, Offset: 42, Key: []byte("k"), Value: []byte(`{"id":1}`)}
+}
+
+// deadLetters возвращает сообщения, отправленные в dead-letter топик.
+func deadLetters(t *testing.T, mem *broker.Memory) []Message {
+	t.Helper()
+	var out []Message
+	for _, raw := range mem.Messages(deadLetterTopic) {
+		var m Message
+		if err := json.Unmarshal(raw.Value, &m); err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+func TestProcess(t *testing.T) {
+	cases := []struct {
+		name         string
+		handle       func(calls int) error
+		wantCalls    int
+		wantDead     bool
+		wantPerm     bool
+		wantErrMatch string
+	}{
+		{"success", func(int) error { return nil }, 1, false, false, ""},
+		{"retry then success", func(calls int) error {
+			if calls < 3 {
+				return errors.New("temporary")
+			}
+			return nil
+		}, 3, false, false, ""},
+		{"retries exhausted", func(int) error { return errors.New("temporary") }, 3, true, false, "temporary"},
+		{"permanent error", func(int) error { return Permanent(errors.New("bad payload")) }, 1, true, true, "bad payload"},
+		{"panic", func(int) error { panic("boom") }, 1, true, true, "panic: boom"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mem := broker.NewMemory(1)
+			p := newTestProcessor(mem, 3)
+			calls := 0
+			err := p.Process(context.Background(), testMessage(), func(ctx context.Context, msg broker.Message) error {
+				calls++
+				return c.handle(calls)
+			})
+			if err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+			if calls != c.wantCalls {
+				t.Fatalf("calls = %d, want %d", calls, c.wantCalls)
+			}
+			dead := deadLetters(t, mem)
+			if !c.wantDead {
+				if len(dead) != 0 {
+					t.Fatalf("dead letters = %+v, want none", dead)
+				}
+				return
+			}
+			if len(dead) != 1 {
+				t.Fatalf("dead letters = %d, want 1", len(dead))
+			}
+			m := dead[0]
+			if m.Consumer != "ingest" || m.OriginalTopic != "news" || m.Partition != 1 || m.Offset != 42 ||
+				string(m.Key) != "k" || string(m.Payload) != `{"id":1}` || m.Version != MessageVersion {
+				t.Fatalf("dead letter = %+v", m)
+			}
+			if m.Attempts != c.wantCalls || m.Permanent != c.wantPerm || !strings.Contains(m.Error, c.wantErrMatch) {
+				t.Fatalf("dead letter attempts = %d, permanent = %v, error = %q", m.Attempts, m.Permanent, m.Error)
+			}
+		})
+	}
+}
+
+type failingPublisher struct{}
+
+func (failingPublisher) Publish(ctx context.Context, topic string, key, value []byte) error {
+	return errors.New("broker unavailable")
+}
+
+func TestProcessReturnsErrorWhenMessageIsLost(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	handle := func(ctx context.Context, msg broker.Message) error { return Permanent(errors.New("bad payload")) }
+
+	p := NewProcessor("ingest", deadLetterTopic, failingPublisher{}, 1, time.Millisecond, log)
+	if err := p.Process(context.Background(), testMessage(), handle); err == nil {
+		t.Fatal("Process() succeeded although dead-letter publish failed")
+	}
+	p = NewProcessor("ingest", "", broker.NewMemory(1), 1, time.Millisecond, log)
+	if err := p.Process(context.Background(), testMessage(), handle); err == nil {
+		t.Fatal("Process() succeeded without dead-letter topic")
+	}
+}
+
+func TestFilterMatch(t *testing.T) {
+	since := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	entry := func(offset int64, consumer string, failedAt time.Time) Entry {
+		return Entry{Offset: offset, Message: Message{Consumer: consumer, FailedAt: failedAt}}
+	}
+	cases := []struct {
+		name   string
+		filter Filter
+		entry  Entry
+		want   bool
+	}{
+		{"empty filter", Filter{}, entry(0, "ingest", since), true},
+		{"consumer", Filter{Consumer: "ingest"}, entry(0, "ingest", since), true},
+		{"other consumer", Filter{Consumer: "ingest"}, entry(0, "search-indexer", since), false},
+		{"since boundary", Filter{Since: since}, entry(0, "ingest", since), true},
+		{"before since", Filter{Since: since}, entry(0, "ingest", since.Add(-time.Second)), false},
+		{"from offset", Filter{FromOffset: 10}, entry(10, "ingest", since), true},
+		{"before offset", Filter{FromOffset: 10}, entry(9, "ingest", since), false},
+		{"raw without filter", Filter{}, Entry{Raw: []byte("x")}, true},
+		{"raw with consumer", Filter{Consumer: "ingest"}, Entry{Raw: []byte("x")}, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := c.filter.Match(c.entry); got != c.want {
+				t.Fatalf("Match() = %v, want %v", got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package dlq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Entry — сообщение dead-letter топика вместе с его позицией в топике.
+type Entry struct {
+	Partition int     `json:"dlq_partition"`
+	Offset    int64   `json:"dlq_offset"`
+	Message   Message `json:"message"`
+	// Raw заполняется, если сообщение не удалось разобрать.
+	Raw []byte `json:"raw,omitempty"`
+}
+
+// ReadAll читает все сообщения dead-letter топика, накопленные к моменту вызова.
+// limit ограничивает число сообщений на партицию, 0 — без ограничения.
+func ReadAll(ctx context.Context, brokers []string, topic string, limit int) ([]Entry, error) {
+	if len(brokers) == 0 {
+		return nil, errors.New("no kafka brokers configured")
+	}
+	conn, err := kafka.DialContext(ctx, "tcp", brokers[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to kafka: %w", err)
+	}
+	partitions, err := conn.ReadPartitions(topic)
+	conn.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read partitions of %s: %w", topic, err)
+	}
+
+	var entries []Entry
+	for _, p := range partitions {
+		part, err := readPartition(ctx, brokers, p, limit)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, part...)
+	}
+	return entries, nil
+}
+
+func readPartition(ctx context.Context, brokers []string, p kafka.Partition, limit int) ([]Entry, error) {
+	leader := net.JoinHostPort(p.Leader.Host, strconv.Itoa(p.Leader.Port))
+	conn, err := kafka.DialLeader(ctx, "tcp", leader, p.Topic, p.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to partition %d leader: %w", p.ID, err)
+	}
+	first, last, err := conn.ReadOffsets()
+	conn.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read offsets of partition %d: %w", p.ID, err)
+	}
+	if first >= last {
+		return nil, nil
+	}
+
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:   brokers,
+		Topic:     p.Topic,
+		Partition: p.ID,
+		MaxBytes:  10e6,
+	})
+	defer reader.Close()
+	if err := reader.SetOffset(first); err != nil {
+		return nil, fmt.Errorf("failed to seek partition %d: %w", p.ID, err)
+	}
+
+	var entries []Entry
+	for {
+		if limit > 0 && len(entries) >= limit {
+			return entries, nil
+		}
+		msg, err := reader.ReadMessage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read partition %d: %w", p.ID, err)
+		}
+		entry := Entry{Partition: msg.Partition, Offset: msg.Offset}
+		if err := json.Unmarshal(msg.Value, &entry.Message); err != nil {
+			entry.Raw = msg.Value
+		}
+		entries = append(entries, entry)
+		if msg.Offset >= last-1 {
+			return entries, nil
+		}
+	}
+}
+
+// Filter отбирает сообщения dead-letter топика для повторной отправки.
+// Нулевые поля не ограничивают выборку.
+type Filter struct {
+	// Consumer — имя потребителя, не справившегося с сообщением.
+	Consumer string
+	// Since — нижняя граница времени ошибки.
+	Since time.Time
+	// FromOffset — нижняя граница смещения в каждой партиции dead-letter топика.
+	FromOffset int64
+}
+
+// Match сообщает, попадает ли сообщение в выборку. Неразобранные сообщения
+// не попадают в выборку с ограничением по потребителю или времени.
+func (f Filter) Match(e Entry) bool {
+	if e.Offset < f.FromOffset {
+		return false
+	}
+	if e.Raw != nil {
+		return f.Consumer == "" && f.Since.IsZero()
+	}
+	if f.Consumer != "" && e.Message.Consumer != f.Consumer {
+		return false
+	}
+	return f.Since.IsZero() || !e.Message.FailedAt.Before(f.Since)
+}
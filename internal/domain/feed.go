@@ -3,15 +3,95 @@ package domain
 import "time"
 
 type Item struct {
-	Title       string
-	Link        string
-	Description string
-	PubDate     time.Time
+	Title           string
+	Link            string
+	OriginalLink    string
+	Author          string
+	Description     string
+	DescriptionText string
+	Content         string
+	ContentText     string
+	PubDate         time.Time
+	Fingerprint     uint64
+	Language        string
+	Categories      []string
+	Category        string
+	Tags            []string
+	Keywords        []string
+	Entities        []Entity
+
+	// DescriptionGenerated — описание составлено автоматически из текста статьи.
+	DescriptionGenerated bool
+
+	// Images — изображения, объявленные в фиде; Image — выбранное и сохраненное.
+	Images []ImageCandidate
+	Image  *Image
+
+	// ID и Status заполняются хранилищем после сохранения элемента.
+	ID     int64
+	Status SaveStatus
+}
+
+// SaveStatus — результат сохранения элемента фида.
+type SaveStatus string
+
+const (
+	StatusUnchanged SaveStatus = ""
+	StatusCreated   SaveStatus = "created"
+	StatusUpdated   SaveStatus = "updated"
+)
+
+// Источники, из которых может быть взято изображение новости.
+const (
+	ImageOriginEnclosure      = "enclosure"
+	ImageOriginMediaContent   = "media:content"
+	ImageOriginMediaThumbnail = "media:thumbnail"
+	ImageOriginOpenGraph      = "og:image"
+	ImageOriginBody           = "body"
+)
+
+// ImageCandidate — изображение-кандидат, найденное в фиде или на странице.
+type ImageCandidate struct {
+	URL    string
+	Type   string
+	Width  int
+	Height int
+	Origin string
+}
+
+// Image — ведущее изображение новости, сохраненное в хранилище объектов.
+type Image struct {
+	SourceURL  string
+	Origin     string
+	Key        string
+	Width      int
+	Height     int
+	Thumbnails []Thumbnail
+}
+
+// Thumbnail — уменьшенная копия ведущего изображения.
+type Thumbnail struct {
+	Key    string
+	Width  int
+	Height int
+}
+
+// Entity — именованная сущность, упомянутая в новости.
+type Entity struct {
+	Kind     string
+	Name     string
+	Mentions int
 }
 
 type Feed struct {
 	Title       string
 	Link        string
 	Description string
+	Language    string
+	Source      string
 	Items       []Item
+
+	// IdempotencyKey — ключ входящего сообщения, из которого получен фид.
+	// Хранилище отмечает ключ обработанным в транзакции сохранения новостей.
+	IdempotencyKey string
 }
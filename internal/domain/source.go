@@ -0,0 +1,11 @@
+package domain
+
+import "time"
+
+// Source — RSS-фид, на который подписан сервис.
+type Source struct {
+	URL       string
+	Name      string
+	Active    bool
+	UpdatedAt time.Time
+}
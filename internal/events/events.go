@@ -0,0 +1,84 @@
+// Package events описывает доменные события о новостях, публикуемые через outbox.
+package events
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"newsservice/internal/domain"
+	"time"
+)
+
+// SchemaVersion — версия схемы событий о новостях.
+const SchemaVersion = 1
+
+// Типы событий.
+const (
+	TypeNewsCreated = "news.created"
+	TypeNewsUpdated = "news.updated"
+)
+
+// NewsEvent — событие о создании или изменении новости.
+type NewsEvent struct {
+	SchemaVersion int         `json:"schema_version"`
+	EventID       string      `json:"event_id"`
+	Type          string      `json:"type"`
+	OccurredAt    time.Time   `json:"occurred_at"`
+	News          NewsPayload `json:"news"`
+}
+
+// NewsPayload — сведения о новости в событии.
+type NewsPayload struct {
+	ID          int64     `json:"id"`
+	Source      string    `json:"source"`
+	Title       string    `json:"title"`
+	Link        string    `json:"link"`
+	Language    string    `json:"lang,omitempty"`
+	Category    string    `json:"category,omitempty"`
+	Tags        []string  `json:"tags"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+// FromFeed строит события для элементов фида, созданных или измененных при сохранении.
+func FromFeed(feed *domain.Feed, now time.Time) []NewsEvent {
+	var events []NewsEvent
+	for _, item := range feed.Items {
+		var eventType string
+		switch item.Status {
+		case domain.StatusCreated:
+			eventType = TypeNewsCreated
+		case domain.StatusUpdated:
+			eventType = TypeNewsUpdated
+		default:
+			continue
+		}
+		tags := item.Tags
+		if tags == nil {
+			tags = []string{}
+		}
+		events = append(events, NewsEvent{
+			SchemaVersion: SchemaVersion,
+			EventID:       newEventID(),
+			Type:          eventType,
+			OccurredAt:    now.UTC(),
+			News: NewsPayload{
+				ID:          item.ID,
+				Source:      feed.Source,
+				Title:       item.Title,
+				Link:        item.Link,
+				Language:    item.Language,
+				Category:    item.Category,
+				Tags:        tags,
+				PublishedAt: item.PubDate.UTC(),
+			},
+		})
+	}
+	return events
+}
+
+func newEventID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,116 @@
+package extraction
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"newsservice/internal/domain"
+	"newsservice/internal/infrastructure/config"
+	"newsservice/internal/models"
+	"newsservice/internal/textutil"
+)
+
+// defaultMaxKeywords — количество ключевых слов новости по умолчанию.
+const defaultMaxKeywords = 8
+
+type Extractor struct {
+	log         *slog.Logger
+	corpus      *corpus
+	gazetteer   *gazetteer
+	maxKeywords int
+}
+
+// New создает этап извлечения ключевых слов и сущностей. Справочник
+// сущностей загружается из cfg.GazetteerPath, если путь задан.
+func New(cfg config.ExtractionConfig, log *slog.Logger) (*Extractor, error) {
+	maxDocs := cfg.CorpusDocs
+	if maxDocs <= 0 {
+		maxDocs = defaultCorpusDocs
+	}
+	e := &Extractor{
+		log:         log,
+		corpus:      newCorpus(maxDocs),
+		maxKeywords: cfg.MaxKeywords,
+	}
+	if e.maxKeywords <= 0 {
+		e.maxKeywords = defaultMaxKeywords
+	}
+	if cfg.GazetteerPath != "" {
+		g, err := LoadGazetteer(cfg.GazetteerPath)
+		if err != nil {
+			return nil, err
+		}
+		e.gazetteer = g
+	}
+	return e, nil
+}
+
+// Name возвращает имя этапа конвейера обработки.
+func (e *Extractor) Name() string {
+	return "extract"
+}
+
+// Process извлекает ключевые слова и именованные сущности каждого элемента фида.
+func (e *Extractor) Process(ctx context.Context, feed *domain.Feed) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	for i := range feed.Items {
+		item := &feed.Items[i]
+		tokens := itemTokens(item.Title, item.ContentText, item.DescriptionText)
+
+		item.Keywords = item.Keywords[:0]
+		for _, k := range e.corpus.keywords(tokens, e.maxKeywords) {
+			item.Keywords = append(item.Keywords, k.surface)
+		}
+
+		item.Entities = item.Entities[:0]
+		for _, m := range e.gazetteer.match(tokens) {
+			item.Entities = append(item.Entities, domain.Entity{
+				Kind:     m.kind,
+				Name:     m.name,
+				Mentions: m.mentions,
+			})
+		}
+	}
+	return nil
+}
+
+// FeedSaved учитывает в корпусе новые сохраненные новости. Повторно
+// полученные и обновленные новости уже учтены и пропускаются.
+func (e *Extractor) FeedSaved(ctx context.Context, feed *domain.Feed) {
+	for _, item := range feed.Items {
+		if item.Status == domain.StatusCreated {
+			e.corpus.add(itemTokens(item.Title, item.ContentText, item.DescriptionText))
+		}
+	}
+}
+
+// NewsSource — источник сохраненных новостей для наполнения корпуса.
+type NewsSource interface {
+	GetNewsByFilter(ctx context.Context, filter models.NewsFilter) ([]models.NewsFullDetailed, error)
+}
+
+// Warm наполняет корпус последними сохраненными новостями, чтобы после
+// перезапуска IDF вычислялся не по пустой статистике.
+func (e *Extractor) Warm(ctx context.Context, source NewsSource) error {
+	news, err := source.GetNewsByFilter(ctx, models.NewsFilter{Limit: e.corpus.maxDocs})
+	if err != nil {
+		return fmt.Errorf("failed to load news for keyword corpus: %w", err)
+	}
+	for _, n := range news {
+		e.corpus.add(itemTokens(n.Title, n.ContentText, n.DescriptionText))
+	}
+	e.log.Info("Keyword corpus loaded", slog.Int("documents", len(news)))
+	return nil
+}
+
+// itemTokens разбивает на слова заголовок и текст новости, а если текста
+// нет — описание.
+func itemTokens(title, content, description string) []textutil.Token {
+	body := content
+	if body == "" {
+		body = description
+	}
+	return textutil.Tokenize(title + "\n" + body)
+}
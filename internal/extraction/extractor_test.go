@@ -0,0 +1,118 @@
+package extraction
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"newsservice/internal/domain"
+	"newsservice/internal/infrastructure/config"
+	"newsservice/internal/models"
+	"newsservice/internal/textutil"
+	"slices"
+	"testing"
+)
+
+func newTestExtractor(t *testing.T, corpusDocs int) *Extractor {
+	t.Helper()
+	e, err := New(config.ExtractionConfig{MaxKeywords: 3, CorpusDocs: corpusDocs}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+func newsFeed(items ...domain.Item) *domain.Feed {
+	return &domain.Feed{Source: "test", Items: items}
+}
+
+func TestRepeatedItemDoesNotChangeIDF(t *testing.T) {
+	e := newTestExtractor(t, 0)
+	ctx := context.Background()
+	item := domain.Item{Title: "Нефть дорожает", ContentText: "Цены на нефть выросли после решения картеля о сокращении добычи нефти."}
+
+	feed := newsFeed(item)
+	if err := e.Process(ctx, feed); err != nil {
+		t.Fatal(err)
+	}
+	feed.Items[0].Status = domain.StatusCreated
+	e.FeedSaved(ctx, feed)
+	docs, idf := e.corpus.docs, e.corpus.idf("нефт")
+	first := feed.Items[0].Keywords
+
+	// Тот же элемент при следующем опросе фида не сохраняется повторно.
+	for range 3 {
+		feed := newsFeed(item)
+		if err := e.Process(ctx, feed); err != nil {
+			t.Fatal(err)
+		}
+		e.FeedSaved(ctx, feed)
+		if !slices.Equal(feed.Items[0].Keywords, first) {
+			t.Fatalf("keywords = %v, want %v", feed.Items[0].Keywords, first)
+		}
+	}
+	if e.corpus.docs != docs || e.corpus.idf("нефт") != idf {
+		t.Fatalf("corpus changed: docs %d -> %d, idf %v -> %v", docs, e.corpus.docs, idf, e.corpus.idf("нефт"))
+	}
+}
+
+func TestCorpusCountsOnlyCreatedItems(t *testing.T) {
+	e := newTestExtractor(t, 0)
+	feed := newsFeed(
+		domain.Item{Title: "Новая", ContentText: "Первая новость про выборы", Status: domain.StatusCreated},
+		domain.Item{Title: "Обновленная", ContentText: "Вторая новость про выборы", Status: domain.StatusUpdated},
+		domain.Item{Title: "Прежняя", ContentText: "Третья новость про выборы"},
+	)
+	e.FeedSaved(context.Background(), feed)
+	if e.corpus.docs != 1 || e.corpus.df["выбор"] != 1 {
+		t.Fatalf("docs = %d, df = %d, want 1 and 1", e.corpus.docs, e.corpus.df["выбор"])
+	}
+}
+
+func TestCorpusIsBounded(t *testing.T) {
+	c := newCorpus(4)
+	for _, text := range []string{"альфа общий", "бета общий", "гамма общий", "дельта общий", "эпсилон общий"} {
+		c.add(textutil.Tokenize(text))
+	}
+	if c.docs != 2 {
+		t.Fatalf("docs = %d, want 2", c.docs)
+	}
+	if len(c.df) != 1 || c.df["общ"] != 2 {
+		t.Fatalf("df = %v, want only the common stem", c.df)
+	}
+}
+
+func TestKeywordsPreferRareWords(t *testing.T) {
+	e := newTestExtractor(t, 0)
+	ctx := context.Background()
+	for _, text := range []string{"Правительство обсудило бюджет", "Правительство обсудило налоги", "Правительство обсудило пенсии"} {
+		e.FeedSaved(ctx, newsFeed(domain.Item{Title: text, Status: domain.StatusCreated}))
+	}
+	feed := newsFeed(domain.Item{Title: "Правительство обсудило вулкан вулкан"})
+	if err := e.Process(ctx, feed); err != nil {
+		t.Fatal(err)
+	}
+	if got := feed.Items[0].Keywords; len(got) == 0 || got[0] != "вулкан" {
+		t.Fatalf("keywords = %v, want вулкан first", got)
+	}
+}
+
+// fakeNews — источник сохраненных новостей для наполнения корпуса.
+type fakeNews []models.NewsFullDetailed
+
+func (f fakeNews) GetNewsByFilter(ctx context.Context, filter models.NewsFilter) ([]models.NewsFullDetailed, error) {
+	return f[:min(filter.Limit, len(f))], nil
+}
+
+func TestWarm(t *testing.T) {
+	e := newTestExtractor(t, 10)
+	news := fakeNews{
+		{Title: "Выборы", ContentText: "Итоги выборов подведены"},
+		{Title: "Погода", DescriptionText: "Ожидаются дожди"},
+	}
+	if err := e.Warm(context.Background(), news); err != nil {
+		t.Fatal(err)
+	}
+	if e.corpus.docs != 2 || e.corpus.df["дожд"] != 1 {
+		t.Fatalf("docs = %d, df = %v", e.corpus.docs, e.corpus.df)
+	}
+}
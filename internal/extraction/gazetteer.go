@@ -0,0 +1,168 @@
+package extraction
+
+import (
+	"fmt"
+	"newsservice/internal/textutil"
+	"os"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Виды именованных сущностей
+const (
+	KindPerson       = "person"
+	KindPlace        = "place"
+	KindOrganization = "organization"
+)
+
+// GazetteerEntry — сущность справочника и ее варианты написания на разных языках.
+type GazetteerEntry struct {
+	Name    string   `yaml:"name"`
+	Aliases []string `yaml:"aliases"`
+}
+
+// GazetteerFile — формат файла справочника именованных сущностей.
+type GazetteerFile struct {
+	People        []GazetteerEntry `yaml:"people"`
+	Places        []GazetteerEntry `yaml:"places"`
+	Organizations []GazetteerEntry `yaml:"organizations"`
+}
+
+// alias — вариант написания сущности в виде последовательности основ.
+// Аббревиатуры (US, UN, ЕС) сопоставляются с учетом регистра, чтобы не
+// совпадать с обычными словами.
+type alias struct {
+	stems    []string
+	surfaces []string
+	exact    bool
+	kind     string
+	name     string
+}
+
+// gazetteer — индекс вариантов написания по основе первого слова.
+type gazetteer struct {
+	byFirst map[string][]alias
+}
+
+// LoadGazetteer читает справочник сущностей из YAML-файла.
+func LoadGazetteer(path string) (*gazetteer, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read gazetteer: %w", err)
+	}
+	var file GazetteerFile
+	if err := yaml.Unmarshal(raw, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse gazetteer: %w", err)
+	}
+	return newGazetteer(file), nil
+}
+
+func newGazetteer(file GazetteerFile) *gazetteer {
+	g := &gazetteer{byFirst: make(map[string][]alias)}
+	add := func(kind string, entries []GazetteerEntry) {
+		for _, e := range entries {
+			for _, variant := range append([]string{e.Name}, e.Aliases...) {
+				tokens := textutil.Tokenize(variant)
+				if len(tokens) == 0 {
+					continue
+				}
+				stems := make([]string, len(tokens))
+				surfaces := make([]string, len(tokens))
+				for i, t := range tokens {
+					stems[i] = t.Stem
+					surfaces[i] = t.Surface
+				}
+				g.byFirst[stems[0]] = append(g.byFirst[stems[0]], alias{
+					stems:    stems,
+					surfaces: surfaces,
+					exact:    isAbbreviation(variant),
+					kind:     kind,
+					name:     e.Name,
+				})
+			}
+		}
+	}
+	add(KindPerson, file.People)
+	add(KindPlace, file.Places)
+	add(KindOrganization, file.Organizations)
+
+	// Более длинные варианты проверяются первыми, чтобы "New York Times"
+	// не распознавалась как "New York".
+	for first := range g.byFirst {
+		sort.SliceStable(g.byFirst[first], func(i, j int) bool {
+			return len(g.byFirst[first][i].stems) > len(g.byFirst[first][j].stems)
+		})
+	}
+	return g
+}
+
+// entityMatch — найденная сущность и количество упоминаний.
+type entityMatch struct {
+	kind     string
+	name     string
+	mentions int
+}
+
+// match находит в тексте упоминания сущностей справочника.
+func (g *gazetteer) match(tokens []textutil.Token) []entityMatch {
+	if g == nil {
+		return nil
+	}
+	found := make(map[string]*entityMatch)
+	var order []string
+	for i := 0; i < len(tokens); {
+		matched := 0
+		for _, a := range g.byFirst[tokens[i].Stem] {
+			if !a.matches(tokens[i:]) {
+				continue
+			}
+			key := a.kind + "\x00" + a.name
+			if m, ok := found[key]; ok {
+				m.mentions++
+			} else {
+				found[key] = &entityMatch{kind: a.kind, name: a.name, mentions: 1}
+				order = append(order, key)
+			}
+			matched = len(a.stems)
+			break
+		}
+		if matched == 0 {
+			matched = 1
+		}
+		i += matched
+	}
+
+	out := make([]entityMatch, 0, len(order))
+	for _, key := range order {
+		out = append(out, *found[key])
+	}
+	return out
+}
+
+// matches проверяет, начинается ли последовательность слов с варианта написания.
+func (a alias) matches(tokens []textutil.Token) bool {
+	if len(tokens) < len(a.stems) {
+		return false
+	}
+	for i := range a.stems {
+		if a.exact {
+			if tokens[i].Surface != a.surfaces[i] {
+				return false
+			}
+			continue
+		}
+		if tokens[i].Stem != a.stems[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// isAbbreviation определяет, что вариант написания является аббревиатурой.
+func isAbbreviation(variant string) bool {
+	return utf8.RuneCountInString(variant) <= 5 && strings.ToUpper(variant) == variant &&
+		strings.ToLower(variant) != variant
+}
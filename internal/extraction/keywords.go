@@ -0,0 +1,133 @@
+package extraction
+
+import (
+	"math"
+	"newsservice/internal/textutil"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// minKeywordRunes — минимальная длина слова-кандидата в ключевые слова.
+const minKeywordRunes = 3
+
+// defaultCorpusDocs — число документов, после которого статистика корпуса
+// уменьшается вдвое.
+const defaultCorpusDocs = 20000
+
+// corpus накапливает документные частоты основ для вычисления IDF. Когда число
+// документов превышает maxDocs, все частоты уменьшаются вдвое, а редкие основы
+// удаляются, поэтому размер корпуса ограничен, а более новые документы весят больше.
+type corpus struct {
+	mutex   sync.RWMutex
+	maxDocs int
+	docs    int
+	df      map[string]int
+}
+
+func newCorpus(maxDocs int) *corpus {
+	return &corpus{maxDocs: maxDocs, df: make(map[string]int)}
+}
+
+// add учитывает в статистике основы сохраненного документа.
+func (c *corpus) add(tokens []textutil.Token) {
+	stems := make(map[string]bool)
+	for _, t := range tokens {
+		if isKeywordCandidate(t) {
+			stems[t.Stem] = true
+		}
+	}
+	if len(stems) == 0 {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.docs++
+	for s := range stems {
+		c.df[s]++
+	}
+	if c.docs <= c.maxDocs {
+		return
+	}
+	c.docs /= 2
+	for s, n := range c.df {
+		if n /= 2; n == 0 {
+			delete(c.df, s)
+		} else {
+			c.df[s] = n
+		}
+	}
+}
+
+// idf возвращает сглаженную обратную документную частоту основы.
+func (c *corpus) idf(stem string) float64 {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return math.Log(float64(c.docs+1)/float64(c.df[stem]+1)) + 1
+}
+
+// keywordCandidate — кандидат в ключевые слова и его вес TF-IDF.
+type keywordCandidate struct {
+	stem    string
+	surface string
+	score   float64
+}
+
+// keywords выбирает до limit ключевых слов документа по TF-IDF. Статистика
+// корпуса не меняется: документ учитывается в ней только после сохранения.
+func (c *corpus) keywords(tokens []textutil.Token, limit int) []keywordCandidate {
+	tf := make(map[string]int)
+	surfaces := make(map[string]map[string]int)
+	for _, t := range tokens {
+		if !isKeywordCandidate(t) {
+			continue
+		}
+		lower := strings.ToLower(t.Surface)
+		tf[t.Stem]++
+		if surfaces[t.Stem] == nil {
+			surfaces[t.Stem] = make(map[string]int)
+		}
+		surfaces[t.Stem][lower]++
+	}
+	if len(tf) == 0 {
+		return nil
+	}
+
+	candidates := make([]keywordCandidate, 0, len(tf))
+	for s, n := range tf {
+		candidates = append(candidates, keywordCandidate{
+			stem:    s,
+			surface: mostFrequent(surfaces[s]),
+			score:   float64(n) / float64(len(tokens)) * c.idf(s),
+		})
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].score == candidates[j].score {
+			return candidates[i].stem < candidates[j].stem
+		}
+		return candidates[i].score > candidates[j].score
+	})
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	return candidates
+}
+
+// isKeywordCandidate проверяет, может ли слово быть ключевым.
+func isKeywordCandidate(t textutil.Token) bool {
+	lower := strings.ToLower(t.Surface)
+	return !textutil.IsStopword(lower) && !textutil.IsNumeric(lower) && utf8.RuneCountInString(lower) >= minKeywordRunes
+}
+
+// mostFrequent возвращает самую частую словоформу основы.
+func mostFrequent(forms map[string]int) string {
+	best, bestCount := "", 0
+	for f, n := range forms {
+		if n > bestCount || (n == bestCount && f < best) {
+			best, bestCount = f, n
+		}
+	}
+	return best
+}
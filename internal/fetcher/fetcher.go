@@ -6,16 +6,27 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+	"newsservice/internal/netguard"
+	"time"
 )
 
+// defaultTimeout — время загрузки фида, если оно не задано.
+const defaultTimeout = 30 * time.Second
+
 type HTTPFetcher struct {
 	client *http.Client
 	log    *slog.Logger
 }
 
-func New(log *slog.Logger) *HTTPFetcher {
+// New создает загрузчик фидов. Адреса фидов приходят в том числе из команд
+// source.subscribe, поэтому соединения с внутренними адресами запрещены,
+// если allowPrivate не задан.
+func New(timeout time.Duration, allowPrivate bool, log *slog.Logger) *HTTPFetcher {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
 	return &HTTPFetcher{
-		client: http.DefaultClient,
+		client: netguard.NewClient(timeout, allowPrivate),
 		log:    log,
 	}
 }
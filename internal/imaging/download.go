@@ -0,0 +1,70 @@
+package imaging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+)
+
+// allowedTypes — поддерживаемые форматы изображений и расширения для хранения.
+var allowedTypes = map[string]string{
+	"image/jpeg": "jpg",
+	"image/png":  "png",
+	"image/gif":  "gif",
+}
+
+// formatTypes сопоставляет имя формата из image.DecodeConfig с MIME-типом.
+var formatTypes = map[string]string{
+	"jpeg": "image/jpeg",
+	"png":  "image/png",
+	"gif":  "image/gif",
+}
+
+var (
+	errTooLarge        = errors.New("image exceeds size limit")
+	errUnsupportedType = errors.New("unsupported image type")
+	errNotHTML         = errors.New("page is not html")
+)
+
+// download загружает ресурс, проверяя код ответа, тип содержимого и размер.
+// allowed == nil отключает проверку типа, а лишние данные сверх limit
+// отбрасываются без ошибки (так читаются HTML-страницы).
+func (p *Processor) download(ctx context.Context, url string, limit int64, allowed map[string]string) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create request for url %s: %w", url, err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch url %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("unexpected status code %d for url %s", resp.StatusCode, url)
+	}
+	if allowed != nil && resp.ContentLength > limit {
+		return nil, "", fmt.Errorf("%w: %d bytes", errTooLarge, resp.ContentLength)
+	}
+	contentType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read url %s: %w", url, err)
+	}
+	if allowed == nil {
+		return data[:min(int64(len(data)), limit)], contentType, nil
+	}
+	if int64(len(data)) > limit {
+		return nil, "", errTooLarge
+	}
+	if contentType == "" || contentType == "application/octet-stream" {
+		contentType, _, _ = mime.ParseMediaType(http.DetectContentType(data))
+	}
+	if _, ok := allowed[contentType]; !ok {
+		return nil, "", fmt.Errorf("%w: %q", errUnsupportedType, contentType)
+	}
+	return data, contentType, nil
+}
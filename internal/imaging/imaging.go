@@ -0,0 +1,346 @@
+// Package imaging выбирает ведущее изображение новости, загружает его,
+// строит миниатюры и сохраняет их в хранилище объектов.
+package imaging
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"log/slog"
+	"net/http"
+	"newsservice/internal/blobstore"
+	"newsservice/internal/domain"
+	"newsservice/internal/infrastructure/config"
+	"newsservice/internal/netguard"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	userAgent        = "newsservice-imaging/1.0"
+	defaultMaxBytes  = 5 << 20
+	defaultMaxPixels = 24_000_000
+	defaultTimeout   = 10 * time.Second
+	// maxRemembered ограничивает кэш уже обработанных ссылок.
+	maxRemembered = 10_000
+	// maxCachedPages ограничивает кэш заголовков загруженных страниц.
+	maxCachedPages = 256
+)
+
+var defaultThumbnailWidths = []int{320, 640}
+
+// Processor — этап конвейера, сохраняющий ведущие изображения новостей.
+type Processor struct {
+	store     blobstore.Store
+	client    *http.Client
+	log       *slog.Logger
+	maxBytes  int64
+	maxPixels int
+	widths    []int
+	fetchPage bool
+
+	mu    sync.Mutex
+	seen  map[string]*domain.Image
+	pages map[string][]byte
+}
+
+func New(cfg config.ImagesConfig, store blobstore.Store, log *slog.Logger) *Processor {
+	p := &Processor{
+		store:     store,
+		log:       log,
+		maxBytes:  cfg.MaxBytes,
+		maxPixels: cfg.MaxPixels,
+		widths:    append([]int(nil), cfg.ThumbnailWidths...),
+		fetchPage: cfg.FetchPage,
+		seen:      make(map[string]*domain.Image),
+		pages:     make(map[string][]byte),
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	p.client = netguard.NewClient(timeout, cfg.AllowPrivateNetworks)
+	if p.maxBytes <= 0 {
+		p.maxBytes = defaultMaxBytes
+	}
+	if p.maxPixels <= 0 {
+		p.maxPixels = defaultMaxPixels
+	}
+	if len(p.widths) == 0 {
+		p.widths = append(p.widths, defaultThumbnailWidths...)
+	}
+	sort.Ints(p.widths)
+	return p
+}
+
+// Name возвращает имя этапа конвейера обработки.
+func (p *Processor) Name() string {
+	return "images"
+}
+
+// Process подбирает и сохраняет изображение для каждого элемента фида.
+// Ошибки загрузки отдельных изображений не прерывают обработку фида.
+func (p *Processor) Process(ctx context.Context, feed *domain.Feed) error {
+	stored := 0
+	for i := range feed.Items {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		item := &feed.Items[i]
+		if img, ok := p.remembered(item.Link); ok {
+			item.Image = img
+			continue
+		}
+		img := p.leadImage(ctx, item)
+		p.remember(item.Link, img)
+		if img != nil {
+			item.Image = img
+			stored++
+		}
+	}
+	if stored > 0 {
+		p.log.Debug("Lead images stored",
+			slog.String("feed", feed.Source),
+			slog.Int("items", stored),
+		)
+	}
+	return nil
+}
+
+// leadImage перебирает кандидатов в порядке приоритета: enclosure,
+// media:content, media:thumbnail, og:image страницы, первое изображение в тексте.
+func (p *Processor) leadImage(ctx context.Context, item *domain.Item) *domain.Image {
+	candidates := rankCandidates(item.Images)
+	tried := make(map[string]bool)
+	try := func(c domain.ImageCandidate) *domain.Image {
+		if tried[c.URL] {
+			return nil
+		}
+		tried[c.URL] = true
+		img, err := p.Store(ctx, c.URL)
+		if err != nil {
+			p.log.Debug("Image candidate rejected",
+				slog.String("link", item.Link),
+				slog.String("image", c.URL),
+				slog.String("origin", c.Origin),
+				slog.Any("error", err),
+			)
+			return nil
+		}
+		img.Origin = c.Origin
+		return img
+	}
+	for _, c := range candidates {
+		if img := try(c); img != nil {
+			return img
+		}
+	}
+	if p.fetchPage && item.Link != "" {
+		if src, ok := p.openGraphImage(ctx, item.Link); ok {
+			if img := try(domain.ImageCandidate{URL: src, Origin: domain.ImageOriginOpenGraph}); img != nil {
+				return img
+			}
+		}
+	}
+	for _, body := range []string{item.Content, item.Description} {
+		if src, _, _, ok := findBodyImage(body, item.Link); ok {
+			if img := try(domain.ImageCandidate{URL: src, Origin: domain.ImageOriginBody}); img != nil {
+				return img
+			}
+		}
+	}
+	return nil
+}
+
+// rankCandidates упорядочивает кандидатов из фида: по источнику, затем по площади.
+func rankCandidates(candidates []domain.ImageCandidate) []domain.ImageCandidate {
+	priority := map[string]int{
+		domain.ImageOriginEnclosure:      0,
+		domain.ImageOriginMediaContent:   1,
+		domain.ImageOriginMediaThumbnail: 2,
+	}
+	ranked := append([]domain.ImageCandidate(nil), candidates...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		pi, pj := priority[ranked[i].Origin], priority[ranked[j].Origin]
+		if pi != pj {
+			return pi < pj
+		}
+		return ranked[i].Width*ranked[i].Height > ranked[j].Width*ranked[j].Height
+	})
+	return ranked
+}
+
+// openGraphImage загружает страницу новости и извлекает из нее og:image.
+func (p *Processor) openGraphImage(ctx context.Context, pageURL string) (string, bool) {
+	page, err := p.FetchPage(ctx, pageURL)
+	if err != nil {
+		p.log.Debug("Failed to fetch article page", slog.String("link", pageURL), slog.Any("error", err))
+		return "", false
+	}
+	return findOpenGraphImage(bytes.NewReader(page), pageURL)
+}
+
+// FetchPage загружает HTML-страницу новости с теми же ограничениями адресов,
+// что и изображения, и возвращает ее часть до тега body. Результат кэшируется,
+// чтобы канонизатор ссылок и поиск og:image загружали страницу один раз.
+func (p *Processor) FetchPage(ctx context.Context, pageURL string) ([]byte, error) {
+	p.mu.Lock()
+	head, ok := p.pages[pageURL]
+	p.mu.Unlock()
+	if ok {
+		return head, nil
+	}
+	page, contentType, err := p.download(ctx, pageURL, maxPageBytes, nil)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" && contentType != "text/html" && contentType != "application/xhtml+xml" {
+		return nil, fmt.Errorf("%w: %q", errNotHTML, contentType)
+	}
+	head = pageHead(page)
+	p.mu.Lock()
+	if len(p.pages) >= maxCachedPages {
+		clear(p.pages)
+	}
+	p.pages[pageURL] = head
+	p.mu.Unlock()
+	return head, nil
+}
+
+// Store загружает изображение по ссылке, строит миниатюры и сохраняет все
+// в хранилище. Уже сохраненные изображения повторно не загружаются.
+func (p *Processor) Store(ctx context.Context, src string) (*domain.Image, error) {
+	base := blobKey(src)
+	if img, err := p.existing(ctx, src, base); err != nil || img != nil {
+		return img, err
+	}
+	data, _, err := p.download(ctx, src, p.maxBytes, allowedTypes)
+	if err != nil {
+		return nil, err
+	}
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image config: %w", err)
+	}
+	contentType, ok := formatTypes[format]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", errUnsupportedType, format)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > p.maxPixels {
+		return nil, fmt.Errorf("%w: %dx%d pixels", errTooLarge, cfg.Width, cfg.Height)
+	}
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	img := &domain.Image{
+		SourceURL: src,
+		Key:       base + "/original." + allowedTypes[contentType],
+		Width:     cfg.Width,
+		Height:    cfg.Height,
+	}
+	flat := flatten(decoded)
+	for _, w := range p.widths {
+		if w >= cfg.Width {
+			break
+		}
+		thumb := downscale(flat, w)
+		encoded, err := encodeJPEG(thumb)
+		if err != nil {
+			return nil, err
+		}
+		key := thumbnailKey(base, w)
+		if err := p.store.Put(ctx, key, encoded); err != nil {
+			return nil, err
+		}
+		img.Thumbnails = append(img.Thumbnails, domain.Thumbnail{
+			Key:    key,
+			Width:  w,
+			Height: thumb.Rect.Dy(),
+		})
+	}
+	// Оригинал сохраняется последним: его наличие означает, что миниатюры готовы.
+	if err := p.store.Put(ctx, img.Key, data); err != nil {
+		return nil, err
+	}
+	return img, nil
+}
+
+// existing восстанавливает описание ранее сохраненного изображения.
+func (p *Processor) existing(ctx context.Context, src, base string) (*domain.Image, error) {
+	for _, ext := range allowedTypes {
+		key := base + "/original." + ext
+		ok, err := p.store.Exists(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		r, err := p.store.Get(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		cfg, _, err := image.DecodeConfig(r)
+		r.Close()
+		if err != nil {
+			return nil, nil
+		}
+		img := &domain.Image{SourceURL: src, Key: key, Width: cfg.Width, Height: cfg.Height}
+		for _, w := range p.widths {
+			if w >= cfg.Width {
+				break
+			}
+			tk := thumbnailKey(base, w)
+			if ok, err := p.store.Exists(ctx, tk); err != nil || !ok {
+				return nil, err
+			}
+			img.Thumbnails = append(img.Thumbnails, domain.Thumbnail{
+				Key:    tk,
+				Width:  w,
+				Height: thumbnailHeight(cfg.Width, cfg.Height, w),
+			})
+		}
+		return img, nil
+	}
+	return nil, nil
+}
+
+func (p *Processor) remembered(link string) (*domain.Image, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	img, ok := p.seen[link]
+	return img, ok
+}
+
+// remember запоминает результат для ссылки, чтобы не загружать страницу и
+// изображение повторно при каждом опросе фида.
+func (p *Processor) remember(link string, img *domain.Image) {
+	if link == "" {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.seen) >= maxRemembered {
+		clear(p.seen)
+	}
+	p.seen[link] = img
+}
+
+// blobKey строит каталог объекта по хешу исходной ссылки: "ab/abcdef...".
+func blobKey(src string) string {
+	sum := sha256.Sum256([]byte(src))
+	h := hex.EncodeToString(sum[:16])
+	return h[:2] + "/" + h
+}
+
+func thumbnailKey(base string, width int) string {
+	return fmt.Sprintf("%s/w%d.jpg", base, width)
+}
@@ -0,0 +1,276 @@
+package imaging
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"newsservice/internal/blobstore"
+	"newsservice/internal/domain"
+	"newsservice/internal/infrastructure/config"
+	"newsservice/internal/netguard"
+	"strings"
+	"testing"
+)
+
+func encodePNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// newTestServer отдает изображения, страницу с og:image и ответы, которые
+// должны быть отвергнуты.
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	large := encodePNG(t, 1000, 500)
+	small := encodePNG(t, 200, 100)
+	huge := encodePNG(t, 1200, 1000)
+	mux := http.NewServeMux()
+	serve := func(path, contentType string, body []byte) {
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", contentType)
+			w.Write(body)
+		})
+	}
+	serve("/large.png", "image/png", large)
+	serve("/small.png", "image/png", small)
+	serve("/untyped.png", "", small)
+	serve("/huge.png", "image/png", huge)
+	serve("/text.png", "text/plain", []byte("not an image"))
+	serve("/heavy.png", "image/png", append(bytes.Clone(small), make([]byte, 4096)...))
+	serve("/article", "text/html; charset=utf-8", []byte(`<html><head>
+		<meta name="twitter:image" content="/small.png">
+		<meta property="og:image" content="/large.png">
+		</head><body><img src="/huge.png"></body></html>`))
+	mux.HandleFunc("/missing.png", http.NotFound)
+	mux.HandleFunc("/loop", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop", http.StatusFound)
+	})
+	mux.HandleFunc("/to-file", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "file:///etc/passwd", http.StatusFound)
+	})
+	mux.HandleFunc("/moved.png", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/small.png", http.StatusMovedPermanently)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newTestProcessor(t *testing.T, cfg config.ImagesConfig) (*Processor, blobstore.Store) {
+	t.Helper()
+	store, err := blobstore.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.AllowPrivateNetworks = true
+	if cfg.MaxBytes == 0 {
+		cfg.MaxBytes = 1 << 20
+	}
+	return New(cfg, store, slog.New(slog.NewTextHandler(io.Discard, nil))), store
+}
+
+func TestRankCandidates(t *testing.T) {
+	ranked := rankCandidates([]domain.ImageCandidate{
+		{URL: "thumb", Origin: domain.ImageOriginMediaThumbnail, Width: 2000, Height: 2000},
+		{URL: "content-small", Origin: domain.ImageOriginMediaContent, Width: 100, Height: 100},
+		{URL: "content-large", Origin: domain.ImageOriginMediaContent, Width: 800, Height: 600},
+		{URL: "enclosure", Origin: domain.ImageOriginEnclosure},
+	})
+	var got []string
+	for _, c := range ranked {
+		got = append(got, c.URL)
+	}
+	if want := "enclosure content-large content-small thumb"; strings.Join(got, " ") != want {
+		t.Fatalf("ranked = %v, want %s", got, want)
+	}
+}
+
+func TestLeadImageSkipsRejectedCandidates(t *testing.T) {
+	srv := newTestServer(t)
+	p, _ := newTestProcessor(t, config.ImagesConfig{})
+	feed := &domain.Feed{Source: "test", Items: []domain.Item{{
+		Link: srv.URL + "/article",
+		Images: []domain.ImageCandidate{
+			{URL: srv.URL + "/small.png", Origin: domain.ImageOriginMediaThumbnail},
+			{URL: srv.URL + "/missing.png", Origin: domain.ImageOriginEnclosure},
+			{URL: srv.URL + "/text.png", Origin: domain.ImageOriginMediaContent},
+		},
+	}}}
+	if err := p.Process(context.Background(), feed); err != nil {
+		t.Fatal(err)
+	}
+	img := feed.Items[0].Image
+	if img == nil || img.SourceURL != srv.URL+"/small.png" || img.Origin != domain.ImageOriginMediaThumbnail {
+		t.Fatalf("image = %+v, want media:thumbnail small.png", img)
+	}
+}
+
+func TestLeadImageFromPage(t *testing.T) {
+	srv := newTestServer(t)
+	p, _ := newTestProcessor(t, config.ImagesConfig{FetchPage: true})
+	item := &domain.Item{Link: srv.URL + "/article", Content: `<p><img src="/small.png"></p>`}
+	img := p.leadImage(context.Background(), item)
+	if img == nil || img.SourceURL != srv.URL+"/large.png" || img.Origin != domain.ImageOriginOpenGraph {
+		t.Fatalf("image = %+v, want og:image large.png", img)
+	}
+
+	p, _ = newTestProcessor(t, config.ImagesConfig{})
+	img = p.leadImage(context.Background(), item)
+	if img == nil || img.SourceURL != srv.URL+"/small.png" || img.Origin != domain.ImageOriginBody {
+		t.Fatalf("image = %+v, want body small.png", img)
+	}
+}
+
+func TestStoreRejects(t *testing.T) {
+	srv := newTestServer(t)
+	p, _ := newTestProcessor(t, config.ImagesConfig{MaxBytes: 2048, MaxPixels: 1_000_000})
+	cases := []struct {
+		path string
+		want error
+	}{
+		{"/text.png", errUnsupportedType},
+		{"/heavy.png", errTooLarge},
+		{"/huge.png", errTooLarge},
+	}
+	for _, c := range cases {
+		if _, err := p.Store(context.Background(), srv.URL+c.path); !errors.Is(err, c.want) {
+			t.Fatalf("Store(%s) error = %v, want %v", c.path, err, c.want)
+		}
+	}
+	if _, err := p.Store(context.Background(), srv.URL+"/missing.png"); err == nil {
+		t.Fatal("Store(missing.png) succeeded")
+	}
+}
+
+func TestStoreDetectsUntypedImage(t *testing.T) {
+	srv := newTestServer(t)
+	p, _ := newTestProcessor(t, config.ImagesConfig{})
+	img, err := p.Store(context.Background(), srv.URL+"/untyped.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(img.Key, "/original.png") {
+		t.Fatalf("key = %q, want png original", img.Key)
+	}
+}
+
+func TestStoreThumbnails(t *testing.T) {
+	srv := newTestServer(t)
+	p, store := newTestProcessor(t, config.ImagesConfig{ThumbnailWidths: []int{640, 320, 1000, 2000}})
+	ctx := context.Background()
+	img, err := p.Store(ctx, srv.URL+"/large.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Width != 1000 || img.Height != 500 || len(img.Thumbnails) != 2 {
+		t.Fatalf("image = %+v, want 1000x500 with two thumbnails", img)
+	}
+	for i, want := range []domain.Thumbnail{{Width: 320, Height: 160}, {Width: 640, Height: 320}} {
+		thumb := img.Thumbnails[i]
+		if thumb.Width != want.Width || thumb.Height != want.Height {
+			t.Fatalf("thumbnail %d = %dx%d, want %dx%d", i, thumb.Width, thumb.Height, want.Width, want.Height)
+		}
+		r, err := store.Get(ctx, thumb.Key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cfg, format, err := image.DecodeConfig(r)
+		r.Close()
+		if err != nil || format != "jpeg" || cfg.Width != want.Width || cfg.Height != want.Height {
+			t.Fatalf("stored thumbnail %d = %s %dx%d, %v", i, format, cfg.Width, cfg.Height, err)
+		}
+	}
+
+	// Повторное сохранение восстанавливает описание без загрузки.
+	srv.Close()
+	again, err := p.Store(ctx, srv.URL+"/large.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Key != img.Key || len(again.Thumbnails) != 2 || again.Thumbnails[1] != img.Thumbnails[1] {
+		t.Fatalf("existing image = %+v, want %+v", again, img)
+	}
+}
+
+func TestRedirects(t *testing.T) {
+	srv := newTestServer(t)
+	p, _ := newTestProcessor(t, config.ImagesConfig{})
+	ctx := context.Background()
+	if img, err := p.Store(ctx, srv.URL+"/moved.png"); err != nil || img.Width != 200 {
+		t.Fatalf("Store(moved.png) = %+v, %v", img, err)
+	}
+	for _, path := range []string{"/loop", "/to-file"} {
+		if _, err := p.Store(ctx, srv.URL+path); err == nil || !strings.Contains(err.Error(), "redirect") {
+			t.Fatalf("Store(%s) error = %v, want redirect error", path, err)
+		}
+	}
+}
+
+func TestPrivateAddressesBlocked(t *testing.T) {
+	srv := newTestServer(t)
+	store, err := blobstore.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := New(config.ImagesConfig{FetchPage: true}, store, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if _, err := p.Store(context.Background(), srv.URL+"/small.png"); !errors.Is(err, netguard.ErrBlockedAddress) {
+		t.Fatalf("Store(loopback) error = %v, want ErrBlockedAddress", err)
+	}
+	if _, ok := p.openGraphImage(context.Background(), srv.URL+"/article"); ok {
+		t.Fatal("article page on loopback was fetched")
+	}
+	if _, err := p.FetchPage(context.Background(), srv.URL+"/article"); !errors.Is(err, netguard.ErrBlockedAddress) {
+		t.Fatalf("FetchPage(loopback) error = %v, want ErrBlockedAddress", err)
+	}
+}
+
+func TestFetchPage(t *testing.T) {
+	hits := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/article", func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><HEAD><link rel="canonical" href="/a"></HEAD><BODY>` + strings.Repeat("text ", 1000) + `</BODY></html>`))
+	})
+	mux.HandleFunc("/feed.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	p, _ := newTestProcessor(t, config.ImagesConfig{})
+	ctx := context.Background()
+
+	for range 2 {
+		head, err := p.FetchPage(ctx, srv.URL+"/article")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := `<html><HEAD><link rel="canonical" href="/a"></HEAD>`; string(head) != want {
+			t.Fatalf("head = %q, want %q", head, want)
+		}
+	}
+	if hits != 1 {
+		t.Fatalf("hits = %d, want the page fetched once", hits)
+	}
+	if _, err := p.FetchPage(ctx, srv.URL+"/feed.json"); !errors.Is(err, errNotHTML) {
+		t.Fatalf("FetchPage(json) error = %v, want errNotHTML", err)
+	}
+}
@@ -0,0 +1,113 @@
+package imaging
+
+import (
+	"bytes"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// maxPageBytes ограничивает объем страницы, читаемой при поиске og:image.
+const maxPageBytes = 1 << 20
+
+// pageHead возвращает начало страницы до тега body: метаданные, нужные
+// конвейеру, находятся в заголовке.
+func pageHead(page []byte) []byte {
+	if i := bytes.Index(bytes.ToLower(page), []byte("<body")); i >= 0 {
+		return page[:i]
+	}
+	return page
+}
+
+// findOpenGraphImage ищет og:image (или twitter:image) в заголовке страницы.
+// Разбор прекращается на теге body.
+func findOpenGraphImage(page io.Reader, pageURL string) (string, bool) {
+	z := html.NewTokenizer(io.LimitReader(page, maxPageBytes))
+	fallback := ""
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return resolveImageURL(fallback, pageURL)
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tok := z.Token()
+			if tok.DataAtom == atom.Body {
+				return resolveImageURL(fallback, pageURL)
+			}
+			if tok.DataAtom != atom.Meta {
+				continue
+			}
+			var key, content string
+			for _, a := range tok.Attr {
+				switch strings.ToLower(a.Key) {
+				case "property", "name":
+					key = strings.ToLower(strings.TrimSpace(a.Val))
+				case "content":
+					content = strings.TrimSpace(a.Val)
+				}
+			}
+			switch key {
+			case "og:image", "og:image:url", "og:image:secure_url":
+				if content != "" {
+					return resolveImageURL(content, pageURL)
+				}
+			case "twitter:image", "twitter:image:src":
+				if fallback == "" {
+					fallback = content
+				}
+			}
+		}
+	}
+}
+
+// findBodyImage возвращает первое изображение из HTML тела новости.
+func findBodyImage(body, baseURL string) (string, int, int, bool) {
+	z := html.NewTokenizer(strings.NewReader(body))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return "", 0, 0, false
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tok := z.Token()
+			if tok.DataAtom != atom.Img {
+				continue
+			}
+			var src string
+			var width, height int
+			for _, a := range tok.Attr {
+				switch a.Key {
+				case "src":
+					src = strings.TrimSpace(a.Val)
+				case "width":
+					width, _ = strconv.Atoi(a.Val)
+				case "height":
+					height, _ = strconv.Atoi(a.Val)
+				}
+			}
+			if resolved, ok := resolveImageURL(src, baseURL); ok {
+				return resolved, width, height, true
+			}
+		}
+	}
+}
+
+// resolveImageURL приводит ссылку на изображение к абсолютному http(s) URL.
+func resolveImageURL(raw, baseURL string) (string, bool) {
+	if raw == "" || strings.HasPrefix(raw, "data:") {
+		return "", false
+	}
+	ref, err := url.Parse(raw)
+	if err != nil {
+		return "", false
+	}
+	if base, err := url.Parse(baseURL); err == nil {
+		ref = base.ResolveReference(ref)
+	}
+	if ref.Scheme != "http" && ref.Scheme != "https" {
+		return "", false
+	}
+	return ref.String(), true
+}
@@ -0,0 +1,74 @@
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+)
+
+const thumbnailQuality = 85
+
+// thumbnailHeight вычисляет высоту миниатюры с сохранением пропорций.
+func thumbnailHeight(srcWidth, srcHeight, width int) int {
+	h := (srcHeight*width + srcWidth/2) / srcWidth
+	if h < 1 {
+		h = 1
+	}
+	return h
+}
+
+// flatten переводит изображение в RGBA, подкладывая белый фон под прозрачные
+// области, так как миниатюры сохраняются в JPEG.
+func flatten(src image.Image) *image.RGBA {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), &image.Uniform{C: color.White}, image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Over)
+	return dst
+}
+
+// downscale уменьшает изображение до ширины width усреднением по областям
+// (box filter): каждый пиксель результата — среднее покрываемых им пикселей.
+func downscale(src *image.RGBA, width int) *image.RGBA {
+	sw, sh := src.Rect.Dx(), src.Rect.Dy()
+	height := thumbnailHeight(sw, sh, width)
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0 := y * sh / height
+		y1 := max((y+1)*sh/height, y0+1)
+		for x := 0; x < width; x++ {
+			x0 := x * sw / width
+			x1 := max((x+1)*sw/width, x0+1)
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					p := row[sx*4 : sx*4+4]
+					r += uint64(p[0])
+					g += uint64(p[1])
+					b += uint64(p[2])
+					a += uint64(p[3])
+					n++
+				}
+			}
+			o := dst.PixOffset(x, y)
+			dst.Pix[o] = uint8(r / n)
+			dst.Pix[o+1] = uint8(g / n)
+			dst.Pix[o+2] = uint8(b / n)
+			dst.Pix[o+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
+
+// encodeJPEG кодирует миниатюру в JPEG.
+func encodeJPEG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: thumbnailQuality}); err != nil {
+		return nil, fmt.Errorf("failed to encode thumbnail: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -9,11 +9,16 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
+// AppConfig — общие настройки сервиса. FetchTimeout — время загрузки фида
+// в секундах. AllowPrivateFeeds разрешает загрузку фидов с внутренних
+// адресов и нужен только для разработки.
 type AppConfig struct {
 	Name               string    `yaml:"name"`
 	ReadTimeout        int       `yaml:"read_timeout"`
-	WriteTimeout       int       `yaml:"writetimeout"`
+	WriteTimeout       int       `yaml:"write_timeout"`
 	ConnectTimeout     int       `yaml:"connect_timeout"`
+	FetchTimeout       int       `yaml:"fetch_timeout"`
+	AllowPrivateFeeds  bool      `yaml:"allow_private_feeds"`
 	ProcessingInterval int       `yaml:"processing_interval"`
 	FeedURLs           []FeedURL `yaml:"feed_urls"`
 }
@@ -23,9 +28,15 @@ type FeedURL struct {
 	URL  string `yaml:"url"`
 }
 
+// HTTPConfig — настройки HTTP API. CursorSecret — ключ подписи токенов
+// курсоров; без него ключ генерируется при запуске и токены не переживают
+// перезапуск сервиса. AdminToken — токен доступа к маршрутам /admin/;
+// без него эти маршруты отключены.
 type HTTPConfig struct {
-	Host string `yaml:"host"`
-	Port int    `yaml:"port"`
+	Host         string `yaml:"host"`
+	Port         int    `yaml:"port"`
+	CursorSecret string `yaml:"cursor_secret"`
+	AdminToken   string `yaml:"admin_token"`
 }
 
 type LoggingConfig struct {
@@ -33,18 +44,33 @@ type LoggingConfig struct {
 	Format string `yaml:"format"`
 }
 
+// DBConfig — настройки хранилища новостей. Driver — postgres (по умолчанию)
+// или sqlite; для SQLite используется только Path — путь к файлу БД.
+// Возможности, которых нет у SQLite, перечислены у storage.SQLiteStorage.
 type DBConfig struct {
+	Driver   string `yaml:"driver"`
+	Path     string `yaml:"path"`
 	Host     string `yaml:"host"`
 	Port     string `yaml:"port"`
 	UserName string `yaml:"username"`
-	Password int    `yaml:"password"`
+	Password string `yaml:"password"`
 	DBName   string `yaml:"db_name"`
 	SSLMode  string `yaml:"sslmode"`
 }
 
 type KafkaTopics struct {
+	// NewsInput — статьи и команды управления источниками, см. пакет ingest.
 	NewsInput string `yaml:"news_input"`
 
+	// NewsRequests — запросы шлюза в JSON-конверте, NewsReplies — ответы,
+	// если в запросе не указан reply_to.
+	NewsRequests string `yaml:"news_requests"`
+	NewsReplies  string `yaml:"news_replies"`
+	// NewsEvents — события news.created и news.updated.
+	NewsEvents string `yaml:"news_events"`
+	// DeadLetter — сообщения, которые не удалось обработать за отведенное число попыток.
+	DeadLetter string `yaml:"dead_letter"`
+
 	NewsDetail      string `yaml:"news_detail"`
 	NewsList        string `yaml:"news_list"`
 	FilteredContent string `yaml:"filtered_content"`
@@ -55,6 +81,120 @@ type KafkaConfig struct {
 	Brokers        []string          `yaml:"brokers"`
 	Topics         KafkaTopics       `yaml:"topics"`
 	ConsumerGroups map[string]string `yaml:"consumer_groups"`
+	// MaxAttempts — число попыток обработки сообщения перед отправкой в dead-letter топик.
+	MaxAttempts  int           `yaml:"max_attempts"`
+	RetryBackoff time.Duration `yaml:"retry_backoff"`
+	// ReplyTopics — топики, которые запросы шлюза могут указать в reply_to.
+	// Значение с завершающей звездочкой задает префикс.
+	ReplyTopics []string `yaml:"reply_topics"`
+}
+
+// OutboxConfig — настройки реле, публикующего сообщения из таблицы outbox.
+// После MaxAttempts неудачных попыток сообщение больше не отправляется.
+type OutboxConfig struct {
+	PollInterval time.Duration `yaml:"poll_interval"`
+	BatchSize    int           `yaml:"batch_size"`
+	Retention    time.Duration `yaml:"retention"`
+	MaxBackoff   time.Duration `yaml:"max_backoff"`
+	MaxAttempts  int           `yaml:"max_attempts"`
+}
+
+// SearchConfig — настройки полнотекстового поиска. Backend — postgres
+// (поиск средствами БД) или local (встроенный индекс в IndexDir, который
+// обновляется по событиям news_events).
+type SearchConfig struct {
+	Backend       string        `yaml:"backend"`
+	IndexDir      string        `yaml:"index_dir"`
+	FlushInterval time.Duration `yaml:"flush_interval"`
+}
+
+// CanonicalConfig — настройки канонизации ссылок новостей. <link rel="canonical">
+// страницы учитывается, если страницы загружаются (images.fetch_page).
+type CanonicalConfig struct {
+	StripParams []string `yaml:"strip_params"`
+	ForceHTTPS  bool     `yaml:"force_https"`
+}
+
+// LanguageConfig — настройки определения языка новостей. MinConfidence —
+// уверенность классификатора, начиная с которой определенный язык заменяет
+// язык, заявленный в фиде (по умолчанию 0.15).
+type LanguageConfig struct {
+	MinConfidence float64 `yaml:"min_confidence"`
+}
+
+// DedupConfig — настройки группировки почти одинаковых новостей в сюжеты.
+type DedupConfig struct {
+	Window      time.Duration `yaml:"window"`
+	MaxDistance int           `yaml:"max_distance"`
+}
+
+// TaggingConfig — настройки правил категоризации и тегирования.
+// RulesSource принимает значения "file" (правила из RulesPath) или "db".
+type TaggingConfig struct {
+	RulesSource    string        `yaml:"rules_source"`
+	RulesPath      string        `yaml:"rules_path"`
+	ReloadInterval time.Duration `yaml:"reload_interval"`
+}
+
+// ExtractionConfig — настройки извлечения ключевых слов и именованных сущностей.
+// CorpusDocs ограничивает число документов в статистике IDF.
+type ExtractionConfig struct {
+	GazetteerPath string `yaml:"gazetteer_path"`
+	MaxKeywords   int    `yaml:"max_keywords"`
+	CorpusDocs    int    `yaml:"corpus_docs"`
+}
+
+// SummaryConfig — настройки автоматического составления описаний.
+type SummaryConfig struct {
+	MaxSentences        int `yaml:"max_sentences"`
+	MinDescriptionWords int `yaml:"min_description_words"`
+}
+
+// ImagesConfig — настройки загрузки ведущих изображений и миниатюр.
+// StoreDir — каталог локального хранилища объектов. AllowPrivateNetworks
+// разрешает загрузку с внутренних адресов и нужен только для разработки.
+type ImagesConfig struct {
+	Enabled              bool          `yaml:"enabled"`
+	StoreDir             string        `yaml:"store_dir"`
+	MaxBytes             int64         `yaml:"max_bytes"`
+	MaxPixels            int           `yaml:"max_pixels"`
+	ThumbnailWidths      []int         `yaml:"thumbnail_widths"`
+	FetchPage            bool          `yaml:"fetch_page"`
+	Timeout              time.Duration `yaml:"timeout"`
+	AllowPrivateNetworks bool          `yaml:"allow_private_networks"`
+}
+
+// FilterRule — условие на поле элемента фида: title, link, category или author.
+// Contains — подстрока без учета регистра, Regex — регулярное выражение.
+type FilterRule struct {
+	Name     string `yaml:"name"`
+	Field    string `yaml:"field"`
+	Contains string `yaml:"contains"`
+	Regex    string `yaml:"regex"`
+}
+
+// FilterRules — правила включения и исключения одной области (глобальной или источника).
+type FilterRules struct {
+	Include []FilterRule `yaml:"include"`
+	Exclude []FilterRule `yaml:"exclude"`
+}
+
+// FilterConfig — правила отбора новостей: общие и для отдельных источников.
+type FilterConfig struct {
+	Global  FilterRules            `yaml:"global"`
+	Sources map[string]FilterRules `yaml:"sources"`
+}
+
+// IngestConfig — настройки этапов конвейера обработки фидов.
+type IngestConfig struct {
+	Filter     FilterConfig     `yaml:"filter"`
+	Canonical  CanonicalConfig  `yaml:"canonical"`
+	Language   LanguageConfig   `yaml:"language"`
+	Dedup      DedupConfig      `yaml:"dedup"`
+	Tagging    TaggingConfig    `yaml:"tagging"`
+	Extraction ExtractionConfig `yaml:"extraction"`
+	Summary    SummaryConfig    `yaml:"summary"`
+	Images     ImagesConfig     `yaml:"images"`
 }
 
 type Route struct {
@@ -68,6 +208,9 @@ type Config struct {
 	Logging LoggingConfig `yaml:"logging"`
 	DB      DBConfig      `yaml:"db"`
 	Kafka   KafkaConfig   `yaml:"kafka"`
+	Outbox  OutboxConfig  `yaml:"outbox"`
+	Search  SearchConfig  `yaml:"search"`
+	Ingest  IngestConfig  `yaml:"ingest"`
 	Routes  []Route       `yaml:"routes"`
 }
 
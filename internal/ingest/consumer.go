@@ -0,0 +1,222 @@
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"newsservice/internal/broker"
+	"newsservice/internal/dlq"
+	"newsservice/internal/domain"
+	"newsservice/internal/sources"
+	"newsservice/storage"
+	"strings"
+	"time"
+)
+
+const (
+	// keyRetention — срок хранения ключей идемпотентности; повторная доставка
+	// сообщения позже этого срока обрабатывается заново.
+	keyRetention    = 7 * 24 * time.Hour
+	cleanupInterval = time.Hour
+)
+
+// FeedProcessor — обработка фидов и уже разобранных статей.
+type FeedProcessor interface {
+	ProcessSource(ctx context.Context, source domain.Source) error
+	ProcessItems(ctx context.Context, feed *domain.Feed) (int, error)
+}
+
+// Consumer читает сообщения news_input и передает их в конвейер обработки
+// новостей или в реестр источников.
+type Consumer struct {
+	consumer    broker.Subscriber
+	processor   FeedProcessor
+	sources     *sources.Registry
+	keys        storage.IdempotencyStorage
+	deadLetters *dlq.Processor
+	log         *slog.Logger
+}
+
+func NewConsumer(consumer broker.Subscriber, processor FeedProcessor, sources *sources.Registry, keys storage.IdempotencyStorage, deadLetters *dlq.Processor, log *slog.Logger) *Consumer {
+	return &Consumer{
+		consumer:    consumer,
+		processor:   processor,
+		sources:     sources,
+		keys:        keys,
+		deadLetters: deadLetters,
+		log:         log.With(slog.String("component", "ingest")),
+	}
+}
+
+// Run обрабатывает сообщения, пока не будет отменен ctx.
+func (c *Consumer) Run(ctx context.Context) error {
+	c.log.Info("Ingest consumer started")
+	go c.cleanup(ctx)
+	for {
+		msg, err := c.consumer.Receive(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				c.log.Info("Ingest consumer stopped")
+				return nil
+			}
+			c.log.Error("Failed to read message from Kafka", slog.Any("error", err))
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+		if err := c.deadLetters.Process(ctx, msg, c.handleMessage); err != nil {
+			c.log.Error("Ingest message lost", slog.Int64("offset", msg.Offset), slog.Any("error", err))
+		}
+	}
+}
+
+func (c *Consumer) handleMessage(ctx context.Context, msg broker.Message) error {
+	return c.Handle(ctx, msg.Value)
+}
+
+// Handle проверяет и применяет одно сообщение. Ошибки, помеченные
+// dlq.Permanent, означают, что сообщение не соответствует схеме или
+// ссылается на неизвестный источник.
+//
+// Сообщения доставляются по крайней мере один раз. Ключ сообщения со
+// статьями отмечается в той же транзакции, что и сами статьи (через
+// domain.Feed.IdempotencyKey), поэтому сбой после сохранения не приводит
+// к повторной обработке. Команды управления источниками идемпотентны:
+// повторная подписка или отписка не меняет реестр, а повторное обновление
+// фида сохраняет только изменившиеся новости, поэтому их ключ отмечается
+// после применения.
+func (c *Consumer) Handle(ctx context.Context, raw []byte) error {
+	var msg Message
+	if err := json.Unmarshal(raw, &msg); err != nil {
+		return dlq.Permanent(fmt.Errorf("%w: %v", ErrInvalidMessage, err))
+	}
+	if err := msg.Validate(); err != nil {
+		return dlq.Permanent(err)
+	}
+
+	log := c.log.With(
+		slog.String("type", msg.Type),
+		slog.String("idempotency_key", msg.IdempotencyKey),
+	)
+	processed, err := c.keys.IsMessageProcessed(ctx, msg.IdempotencyKey)
+	if err != nil {
+		return err
+	}
+	if processed {
+		log.Info("Duplicate message skipped")
+		return nil
+	}
+
+	if err := c.apply(ctx, msg, log); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return dlq.Permanent(err)
+		}
+		return err
+	}
+	return c.keys.MarkMessageProcessed(ctx, msg.IdempotencyKey)
+}
+
+func (c *Consumer) apply(ctx context.Context, msg Message, log *slog.Logger) error {
+	switch msg.Type {
+	case TypeArticles:
+		feed := toFeed(msg)
+		saved, err := c.processor.ProcessItems(ctx, feed)
+		if err != nil {
+			return err
+		}
+		log.Info("Articles ingested",
+			slog.String("source", feed.Source),
+			slog.Int("received", len(msg.Articles)),
+			slog.Int("saved", saved),
+		)
+	case TypeSourceSubscribe:
+		src, err := c.sources.Subscribe(ctx, msg.Feed.URL, msg.Feed.Name)
+		if err != nil {
+			return err
+		}
+		log.Info("Source subscribed", slog.String("url", src.URL))
+		// Недоступный фид не отменяет подписку: планировщик повторит обработку.
+		if err := c.processor.ProcessSource(ctx, src); err != nil {
+			log.Warn("Initial processing of subscribed source failed", slog.Any("error", err))
+		}
+	case TypeSourceUnsubscribe:
+		if err := c.sources.Unsubscribe(ctx, msg.Feed.URL); err != nil {
+			return err
+		}
+		log.Info("Source unsubscribed", slog.String("url", msg.Feed.URL))
+	case TypeSourceRefresh:
+		src, err := c.sources.Get(ctx, strings.TrimSpace(msg.Feed.URL))
+		if err != nil {
+			return err
+		}
+		if !src.Active {
+			return fmt.Errorf("source %s is unsubscribed: %w", src.URL, storage.ErrNotFound)
+		}
+		if err := c.processor.ProcessSource(ctx, src); err != nil {
+			return err
+		}
+		log.Info("Source refreshed", slog.String("url", src.URL))
+	}
+	return nil
+}
+
+// toFeed переводит статьи сообщения в доменную модель фида.
+func toFeed(msg Message) *domain.Feed {
+	now := time.Now().UTC()
+	feed := &domain.Feed{
+		Source:         strings.TrimSpace(msg.Source),
+		Items:          make([]domain.Item, 0, len(msg.Articles)),
+		IdempotencyKey: msg.IdempotencyKey,
+	}
+	for _, a := range msg.Articles {
+		item := domain.Item{
+			Title:       strings.TrimSpace(a.Title),
+			Link:        strings.TrimSpace(a.Link),
+			Author:      strings.TrimSpace(a.Author),
+			Description: a.Description,
+			Content:     a.Content,
+			PubDate:     now,
+			Categories:  a.Categories,
+		}
+		if a.PublishedAt != nil {
+			item.PubDate = a.PublishedAt.UTC()
+		}
+		for _, img := range a.Images {
+			item.Images = append(item.Images, domain.ImageCandidate{
+				URL:    strings.TrimSpace(img.URL),
+				Type:   img.Type,
+				Width:  img.Width,
+				Height: img.Height,
+				Origin: domain.ImageOriginEnclosure,
+			})
+		}
+		feed.Items = append(feed.Items, item)
+	}
+	return feed
+}
+
+// cleanup периодически удаляет устаревшие ключи идемпотентности.
+func (c *Consumer) cleanup(ctx context.Context) {
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		deleted, err := c.keys.DeleteProcessedMessages(ctx, time.Now().Add(-keyRetention))
+		if err != nil {
+			c.log.Error("Failed to delete old idempotency keys", slog.Any("error", err))
+			continue
+		}
+		if deleted > 0 {
+			c.log.Debug("Old idempotency keys deleted", slog.Int64("deleted", deleted))
+		}
+	}
+}
@@ -0,0 +1,320 @@
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"newsservice/internal/broker"
+	"newsservice/internal/dlq"
+	"newsservice/internal/domain"
+	"newsservice/internal/infrastructure/config"
+	"newsservice/internal/sources"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	inputTopic      = "news_input"
+	deadLetterTopic = "news_dead_letter"
+)
+
+// fakeKeys — ключи идемпотентности в памяти.
+type fakeKeys struct {
+	keys    map[string]bool
+	markErr error
+}
+
+func (f *fakeKeys) IsMessageProcessed(ctx context.Context, key string) (bool, error) {
+	return f.keys[key], nil
+}
+
+func (f *fakeKeys) MarkMessageProcessed(ctx context.Context, key string) error {
+	if f.markErr != nil {
+		return f.markErr
+	}
+	f.keys[key] = true
+	return nil
+}
+
+func (f *fakeKeys) DeleteProcessedMessages(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+// fakeProcessor запоминает обработанные фиды и, как хранилище, отмечает
+// ключ сообщения со статьями при сохранении.
+type fakeProcessor struct {
+	keys      *fakeKeys
+	feeds     []*domain.Feed
+	refreshed []string
+	err       error
+}
+
+func (f *fakeProcessor) ProcessSource(ctx context.Context, source domain.Source) error {
+	f.refreshed = append(f.refreshed, source.URL)
+	return f.err
+}
+
+func (f *fakeProcessor) ProcessItems(ctx context.Context, feed *domain.Feed) (int, error) {
+	if f.err != nil {
+		return 0, f.err
+	}
+	f.feeds = append(f.feeds, feed)
+	if feed.IdempotencyKey != "" {
+		f.keys.keys[feed.IdempotencyKey] = true
+	}
+	return len(feed.Items), nil
+}
+
+// fakeSources — реестр источников в памяти.
+type fakeSources struct {
+	sources map[string]domain.Source
+}
+
+func (f *fakeSources) ListSources(ctx context.Context) ([]domain.Source, error) {
+	out := make([]domain.Source, 0, len(f.sources))
+	for _, src := range f.sources {
+		out = append(out, src)
+	}
+	return out, nil
+}
+
+func (f *fakeSources) SaveSource(ctx context.Context, source domain.Source) error {
+	if prev, ok := f.sources[source.URL]; ok && source.Name == "" {
+		source.Name = prev.Name
+	}
+	f.sources[source.URL] = source
+	return nil
+}
+
+type testEnv struct {
+	consumer    *Consumer
+	deadLetters *dlq.Processor
+	mem         *broker.Memory
+	keys        *fakeKeys
+	processor   *fakeProcessor
+	sources     *fakeSources
+	offset      int64
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	env := &testEnv{
+		mem:     broker.NewMemory(1),
+		keys:    &fakeKeys{keys: make(map[string]bool)},
+		sources: &fakeSources{sources: make(map[string]domain.Source)},
+	}
+	env.processor = &fakeProcessor{keys: env.keys}
+	registry := sources.NewRegistry([]config.FeedURL{{Name: "bbc", URL: "https://bbc.com/rss"}}, env.sources)
+	env.deadLetters = dlq.NewProcessor("ingest", deadLetterTopic, env.mem, 2, time.Millisecond, log)
+	env.consumer = NewConsumer(nil, env.processor, registry, env.keys, env.deadLetters, log)
+	return env
+}
+
+// deliver передает сообщение обработчику так же, как Consumer.Run.
+func (e *testEnv) deliver(t *testing.T, raw string) {
+	t.Helper()
+	e.offset++
+	msg := broker.Message{Topic: inputTopic, Offset: e.offset, Value: []byte(raw)}
+	if err := e.deadLetters.Process(context.Background(), msg, e.consumer.handleMessage); err != nil {
+		t.Fatalf("message lost: %v", err)
+	}
+}
+
+func (e *testEnv) deadLettered(t *testing.T) []dlq.Message {
+	t.Helper()
+	var out []dlq.Message
+	for _, msg := range e.mem.Messages(deadLetterTopic) {
+		var dead dlq.Message
+		if err := json.Unmarshal(msg.Value, &dead); err != nil {
+			t.Fatalf("invalid dead letter: %v", err)
+		}
+		out = append(out, dead)
+	}
+	return out
+}
+
+func TestValidate(t *testing.T) {
+	article := `{"title":"Oil","link":"https://example.com/1"}`
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{"articles", `{"version":1,"type":"articles","idempotency_key":"k","source":"wire","articles":[` + article + `]}`, ""},
+		{"subscribe", `{"version":1,"type":"source.subscribe","idempotency_key":"k","feed":{"url":"https://example.com/rss"}}`, ""},
+		{"version", `{"version":2,"type":"articles","idempotency_key":"k"}`, "version 2"},
+		{"key", `{"version":1,"type":"articles","idempotency_key":" "}`, "idempotency_key"},
+		{"type", `{"version":1,"type":"delete","idempotency_key":"k"}`, "unknown type"},
+		{"source", `{"version":1,"type":"articles","idempotency_key":"k","articles":[` + article + `]}`, "source is required"},
+		{"no articles", `{"version":1,"type":"articles","idempotency_key":"k","source":"wire"}`, "articles are required"},
+		{"title", `{"version":1,"type":"articles","idempotency_key":"k","source":"wire","articles":[{"link":"https://example.com/1"}]}`, "articles[0]: title"},
+		{"link", `{"version":1,"type":"articles","idempotency_key":"k","source":"wire","articles":[{"title":"Oil","link":"/news/1"}]}`, "articles[0]: link"},
+		{"image", `{"version":1,"type":"articles","idempotency_key":"k","source":"wire","articles":[{"title":"Oil","link":"https://example.com/1","images":[{"url":"file:///etc/passwd"}]}]}`, "articles[0].images[0]"},
+		{"feed url", `{"version":1,"type":"source.refresh","idempotency_key":"k","feed":{"url":"ftp://example.com/rss"}}`, "feed.url"},
+		{"feed missing", `{"version":1,"type":"source.unsubscribe","idempotency_key":"k"}`, "feed.url"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var msg Message
+			if err := json.Unmarshal([]byte(tt.raw), &msg); err != nil {
+				t.Fatal(err)
+			}
+			err := msg.Validate()
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("Validate() error = %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidMessage) || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Validate() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	var many Message
+	many.Version, many.Type, many.IdempotencyKey, many.Source = MessageVersion, TypeArticles, "k", "wire"
+	for range MaxArticles + 1 {
+		many.Articles = append(many.Articles, Article{Title: "Oil", Link: "https://example.com/1"})
+	}
+	if err := many.Validate(); !errors.Is(err, ErrInvalidMessage) {
+		t.Fatalf("Validate(%d articles) error = %v, want ErrInvalidMessage", len(many.Articles), err)
+	}
+}
+
+func TestConsumerRejectsInvalidMessages(t *testing.T) {
+	env := newTestEnv(t)
+	for _, raw := range []string{
+		`{"version":`,
+		`{"version":1,"type":"articles","idempotency_key":"k1","source":"wire"}`,
+	} {
+		env.deliver(t, raw)
+	}
+
+	dead := env.deadLettered(t)
+	if len(dead) != 2 {
+		t.Fatalf("dead letters = %d, want 2", len(dead))
+	}
+	for _, d := range dead {
+		if !d.Permanent || d.Attempts != 1 || d.OriginalTopic != inputTopic || d.Consumer != "ingest" {
+			t.Fatalf("dead letter = %+v, want a permanent failure after one attempt", d)
+		}
+	}
+	if len(env.processor.feeds) != 0 || len(env.keys.keys) != 0 {
+		t.Fatalf("invalid messages were processed: feeds %d, keys %v", len(env.processor.feeds), env.keys.keys)
+	}
+}
+
+func TestConsumerSkipsRedelivery(t *testing.T) {
+	env := newTestEnv(t)
+	const raw = `{"version":1,"type":"articles","idempotency_key":"wire-1","source":" wire ",
+		"articles":[{"title":" Oil ","link":"https://example.com/1","published_at":"2024-05-01T10:00:00+03:00",
+		"images":[{"url":"https://example.com/1.jpg"}]}]}`
+
+	env.deliver(t, raw)
+	env.deliver(t, raw)
+	if len(env.processor.feeds) != 1 {
+		t.Fatalf("articles processed %d times, want once", len(env.processor.feeds))
+	}
+	feed := env.processor.feeds[0]
+	if feed.Source != "wire" || feed.IdempotencyKey != "wire-1" || len(feed.Items) != 1 {
+		t.Fatalf("feed = %+v", feed)
+	}
+	item := feed.Items[0]
+	want := time.Date(2024, 5, 1, 7, 0, 0, 0, time.UTC)
+	if item.Title != "Oil" || !item.PubDate.Equal(want) || item.PubDate.Location() != time.UTC ||
+		len(item.Images) != 1 || item.Images[0].Origin != domain.ImageOriginEnclosure {
+		t.Fatalf("item = %+v", item)
+	}
+	if dead := env.deadLettered(t); len(dead) != 0 {
+		t.Fatalf("dead letters = %+v, want none", dead)
+	}
+}
+
+func TestConsumerKeyMarkedWithArticles(t *testing.T) {
+	env := newTestEnv(t)
+	// Ключ сохраняется вместе со статьями, поэтому сбой отдельной отметки
+	// после сохранения не приводит к повторной обработке при повторной попытке.
+	env.keys.markErr = errors.New("connection reset")
+	env.deliver(t, `{"version":1,"type":"articles","idempotency_key":"wire-2","source":"wire",
+		"articles":[{"title":"Oil","link":"https://example.com/2"}]}`)
+
+	if len(env.processor.feeds) != 1 {
+		t.Fatalf("articles processed %d times, want once", len(env.processor.feeds))
+	}
+	if dead := env.deadLettered(t); len(dead) != 0 {
+		t.Fatalf("dead letters = %+v, want none", dead)
+	}
+}
+
+func TestConsumerRetriesFailedProcessing(t *testing.T) {
+	env := newTestEnv(t)
+	env.processor.err = errors.New("database is unavailable")
+	env.deliver(t, `{"version":1,"type":"articles","idempotency_key":"wire-3","source":"wire",
+		"articles":[{"title":"Oil","link":"https://example.com/3"}]}`)
+
+	dead := env.deadLettered(t)
+	if len(dead) != 1 || dead[0].Permanent || dead[0].Attempts != 2 {
+		t.Fatalf("dead letters = %+v, want one transient failure after 2 attempts", dead)
+	}
+	if env.keys.keys["wire-3"] {
+		t.Fatal("key of a failed message was marked as processed")
+	}
+}
+
+func TestConsumerSourceCommands(t *testing.T) {
+	env := newTestEnv(t)
+	const feedURL = "https://example.com/rss"
+
+	env.deliver(t, `{"version":1,"type":"source.subscribe","idempotency_key":"s1","feed":{"url":" `+feedURL+` ","name":"example"}}`)
+	if src := env.sources.sources[feedURL]; !src.Active || src.Name != "example" {
+		t.Fatalf("subscribed source = %+v", src)
+	}
+	if len(env.processor.refreshed) != 1 || env.processor.refreshed[0] != feedURL {
+		t.Fatalf("refreshed = %v, want the new source processed once", env.processor.refreshed)
+	}
+
+	env.deliver(t, `{"version":1,"type":"source.refresh","idempotency_key":"s2","feed":{"url":"https://bbc.com/rss"}}`)
+	if len(env.processor.refreshed) != 2 || env.processor.refreshed[1] != "https://bbc.com/rss" {
+		t.Fatalf("refreshed = %v, want the configured source refreshed", env.processor.refreshed)
+	}
+
+	env.deliver(t, `{"version":1,"type":"source.unsubscribe","idempotency_key":"s3","feed":{"url":"`+feedURL+`"}}`)
+	if src := env.sources.sources[feedURL]; src.Active || src.Name != "example" {
+		t.Fatalf("unsubscribed source = %+v", src)
+	}
+
+	env.deliver(t, `{"version":1,"type":"source.refresh","idempotency_key":"s4","feed":{"url":"`+feedURL+`"}}`)
+	env.deliver(t, `{"version":1,"type":"source.unsubscribe","idempotency_key":"s5","feed":{"url":"https://unknown.com/rss"}}`)
+	if len(env.processor.refreshed) != 2 {
+		t.Fatalf("refreshed = %v, want an unsubscribed source skipped", env.processor.refreshed)
+	}
+	dead := env.deadLettered(t)
+	if len(dead) != 2 || !dead[0].Permanent || !dead[1].Permanent {
+		t.Fatalf("dead letters = %+v, want refresh and unsubscribe of unknown sources rejected", dead)
+	}
+	for _, key := range []string{"s1", "s2", "s3"} {
+		if !env.keys.keys[key] {
+			t.Fatalf("key %s is not marked as processed", key)
+		}
+	}
+	if env.keys.keys["s4"] || env.keys.keys["s5"] {
+		t.Fatal("keys of rejected commands were marked as processed")
+	}
+}
+
+func TestConsumerSubscribeSurvivesUnavailableFeed(t *testing.T) {
+	env := newTestEnv(t)
+	env.processor.err = errors.New("timeout")
+	env.deliver(t, `{"version":1,"type":"source.subscribe","idempotency_key":"s1","feed":{"url":"https://example.com/rss"}}`)
+	if src := env.sources.sources["https://example.com/rss"]; !src.Active {
+		t.Fatalf("source = %+v, want it subscribed", src)
+	}
+	if dead := env.deadLettered(t); len(dead) != 0 || !env.keys.keys["s1"] {
+		t.Fatalf("dead letters = %+v, key marked = %v", dead, env.keys.keys["s1"])
+	}
+}
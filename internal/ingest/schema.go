@@ -0,0 +1,138 @@
+// Package ingest принимает новости и команды управления источниками из топика
+// news_input. Сообщение — JSON-объект версии MessageVersion:
+//
+//	{
+//	  "version": 1,
+//	  "type": "articles",
+//	  "idempotency_key": "wire-2024-05-01-0001",
+//	  "source": "example-wire",
+//	  "articles": [{
+//	    "title": "Заголовок",
+//	    "link": "https://example.com/news/1",
+//	    "author": "Редакция",
+//	    "description": "<p>Анонс</p>",
+//	    "content": "<p>Текст</p>",
+//	    "published_at": "2024-05-01T10:00:00Z",
+//	    "categories": ["Экономика"],
+//	    "images": [{"url": "https://example.com/1.jpg", "type": "image/jpeg"}]
+//	  }]
+//	}
+//
+//	{"version": 1, "type": "source.subscribe", "idempotency_key": "...",
+//	 "feed": {"url": "https://example.com/rss", "name": "example"}}
+//
+// Типы source.unsubscribe и source.refresh требуют только feed.url.
+// Сообщение с уже обработанным idempotency_key пропускается.
+package ingest
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// MessageVersion — поддерживаемая версия сообщений.
+const MessageVersion = 1
+
+// MaxArticles ограничивает число статей в одном сообщении.
+const MaxArticles = 100
+
+// Типы сообщений.
+const (
+	TypeArticles          = "articles"
+	TypeSourceSubscribe   = "source.subscribe"
+	TypeSourceUnsubscribe = "source.unsubscribe"
+	TypeSourceRefresh     = "source.refresh"
+)
+
+// ErrInvalidMessage возвращается, если сообщение не соответствует схеме.
+var ErrInvalidMessage = errors.New("invalid message")
+
+// Message — сообщение топика news_input.
+type Message struct {
+	Version        int       `json:"version"`
+	Type           string    `json:"type"`
+	IdempotencyKey string    `json:"idempotency_key"`
+	Source         string    `json:"source,omitempty"`
+	Articles       []Article `json:"articles,omitempty"`
+	Feed           *Feed     `json:"feed,omitempty"`
+}
+
+// Article — статья, уже разобранная отправителем.
+type Article struct {
+	Title       string     `json:"title"`
+	Link        string     `json:"link"`
+	Author      string     `json:"author,omitempty"`
+	Description string     `json:"description,omitempty"`
+	Content     string     `json:"content,omitempty"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+	Categories  []string   `json:"categories,omitempty"`
+	Images      []Image    `json:"images,omitempty"`
+}
+
+// Image — изображение статьи.
+type Image struct {
+	URL    string `json:"url"`
+	Type   string `json:"type,omitempty"`
+	Width  int    `json:"width,omitempty"`
+	Height int    `json:"height,omitempty"`
+}
+
+// Feed — RSS-фид в командах управления источниками.
+type Feed struct {
+	URL  string `json:"url"`
+	Name string `json:"name,omitempty"`
+}
+
+// Validate проверяет сообщение на соответствие схеме.
+func (m Message) Validate() error {
+	if m.Version != MessageVersion {
+		return invalid("version %d is not supported, expected %d", m.Version, MessageVersion)
+	}
+	if strings.TrimSpace(m.IdempotencyKey) == "" {
+		return invalid("idempotency_key is required")
+	}
+	switch m.Type {
+	case TypeArticles:
+		if strings.TrimSpace(m.Source) == "" {
+			return invalid("source is required")
+		}
+		if len(m.Articles) == 0 {
+			return invalid("articles are required")
+		}
+		if len(m.Articles) > MaxArticles {
+			return invalid("too many articles: %d, max %d", len(m.Articles), MaxArticles)
+		}
+		for i, a := range m.Articles {
+			if strings.TrimSpace(a.Title) == "" {
+				return invalid("articles[%d]: title is required", i)
+			}
+			if !isHTTPURL(a.Link) {
+				return invalid("articles[%d]: link must be an absolute http(s) URL", i)
+			}
+			for j, img := range a.Images {
+				if !isHTTPURL(img.URL) {
+					return invalid("articles[%d].images[%d]: url must be an absolute http(s) URL", i, j)
+				}
+			}
+		}
+	case TypeSourceSubscribe, TypeSourceUnsubscribe, TypeSourceRefresh:
+		if m.Feed == nil || !isHTTPURL(m.Feed.URL) {
+			return invalid("feed.url must be an absolute http(s) URL")
+		}
+	default:
+		return invalid("unknown type %q", m.Type)
+	}
+	return nil
+}
+
+func invalid(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrInvalidMessage, fmt.Sprintf(format, args...))
+}
+
+func isHTTPURL(raw string) bool {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
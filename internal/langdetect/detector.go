@@ -0,0 +1,272 @@
+package langdetect
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"newsservice/internal/domain"
+	"newsservice/internal/infrastructure/config"
+	"path"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+//go:embed profiles/*.txt
+var profileFS embed.FS
+
+const (
+	// maxNgram — максимальная длина n-граммы профиля.
+	maxNgram = 3
+	// profileSize — количество самых частых n-грамм в профиле.
+	profileSize = 300
+	// minLetters — минимальное количество букв в тексте для классификации.
+	minLetters = 20
+	// defaultMinConfidence — уверенность, при которой определенный язык
+	// считается надежнее языка, заявленного в фиде. Ошибки классификатора
+	// между близкими языками (ru и uk, en и de) на заголовке с анонсом дают
+	// уверенность ниже 0.05, а текст действительно на другом языке — от 0.12
+	// до 0.25, поэтому порог отсекает ошибки и оставляет явные расхождения.
+	defaultMinConfidence = 0.15
+)
+
+// Undetermined — код языка (ISO 639-2), который Detect возвращает для
+// текста, язык которого определить не удалось.
+const Undetermined = "und"
+
+// profile — ранги n-грамм языка: чем меньше ранг, тем чаще n-грамма.
+type profile map[string]int
+
+type Detector struct {
+	log           *slog.Logger
+	profiles      map[string]profile
+	minConfidence float64
+}
+
+// New строит n-граммные профили языков по встроенным обучающим текстам.
+func New(cfg config.LanguageConfig, log *slog.Logger) (*Detector, error) {
+	entries, err := fs.ReadDir(profileFS, "profiles")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read language profiles: %w", err)
+	}
+	d := &Detector{
+		log:           log,
+		profiles:      make(map[string]profile, len(entries)),
+		minConfidence: cfg.MinConfidence,
+	}
+	if d.minConfidence <= 0 {
+		d.minConfidence = defaultMinConfidence
+	}
+	for _, e := range entries {
+		body, err := fs.ReadFile(profileFS, path.Join("profiles", e.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read language profile %s: %w", e.Name(), err)
+		}
+		lang := strings.TrimSuffix(e.Name(), path.Ext(e.Name()))
+		d.profiles[lang] = buildProfile(string(body))
+	}
+	return d, nil
+}
+
+// Name возвращает имя этапа конвейера обработки.
+func (d *Detector) Name() string {
+	return "language"
+}
+
+// Process определяет язык каждого элемента фида с учетом языка, заявленного в самом фиде.
+func (d *Detector) Process(ctx context.Context, feed *domain.Feed) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	declared := Normalize(feed.Language)
+	for i := range feed.Items {
+		item := &feed.Items[i]
+		body := item.DescriptionText
+		if body == "" {
+			body = item.ContentText
+		}
+		item.Language = d.Resolve(declared, item.Title+"\n"+body)
+	}
+	return nil
+}
+
+// Resolve выбирает язык текста: заявленный язык используется, если он
+// не противоречит уверенному результату классификатора. Если язык неизвестен,
+// возвращается пустая строка.
+func (d *Detector) Resolve(declared, text string) string {
+	detected, confidence := d.Detect(text)
+	switch {
+	case detected == Undetermined:
+		return declared
+	case declared == "" || detected == declared:
+		return detected
+	case scriptMismatch(declared, text):
+		return detected
+	case d.profiles[declared] == nil:
+		return declared
+	case confidence >= d.minConfidence:
+		d.log.Debug("Declared feed language overridden",
+			slog.String("declared", declared),
+			slog.String("detected", detected),
+			slog.Float64("confidence", confidence),
+		)
+		return detected
+	default:
+		return declared
+	}
+}
+
+// Detect определяет язык текста и уверенность в результате от 0 до 1.
+// Для пустого или слишком короткого текста возвращает Undetermined.
+func (d *Detector) Detect(text string) (string, float64) {
+	letters := 0
+	for _, r := range text {
+		if unicode.IsLetter(r) {
+			letters++
+		}
+	}
+	if letters < minLetters {
+		return Undetermined, 0
+	}
+
+	doc := buildProfile(text)
+	type score struct {
+		lang     string
+		distance int
+	}
+	scores := make([]score, 0, len(d.profiles))
+	for lang, p := range d.profiles {
+		scores = append(scores, score{lang: lang, distance: distance(doc, p)})
+	}
+	if len(scores) == 0 {
+		return Undetermined, 0
+	}
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].distance == scores[j].distance {
+			return scores[i].lang < scores[j].lang
+		}
+		return scores[i].distance < scores[j].distance
+	})
+	if len(scores) == 1 || scores[1].distance == 0 {
+		return scores[0].lang, 1
+	}
+	confidence := float64(scores[1].distance-scores[0].distance) / float64(scores[1].distance)
+	return scores[0].lang, confidence
+}
+
+// Languages возвращает список языков, известных классификатору.
+func (d *Detector) Languages() []string {
+	langs := make([]string, 0, len(d.profiles))
+	for lang := range d.profiles {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// cyrillicLanguages — языки, использующие кириллицу.
+var cyrillicLanguages = map[string]bool{
+	"ru": true,
+	"uk": true,
+	"be": true,
+	"bg": true,
+	"sr": true,
+	"mk": true,
+	"kk": true,
+}
+
+// scriptMismatch определяет, что письменность текста не соответствует заявленному языку.
+func scriptMismatch(declared, text string) bool {
+	letters, cyrillic := 0, 0
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		if unicode.Is(unicode.Cyrillic, r) {
+			cyrillic++
+		}
+	}
+	if letters < minLetters {
+		return false
+	}
+	share := float64(cyrillic) / float64(letters)
+	if cyrillicLanguages[declared] {
+		return share < 0.1
+	}
+	return share > 0.5
+}
+
+// Normalize приводит код языка из фида (например, "en-US") к двухбуквенному виду.
+// Некорректный код превращается в пустую строку.
+func Normalize(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if i := strings.IndexAny(code, "-_"); i >= 0 {
+		code = code[:i]
+	}
+	if len(code) < 2 || len(code) > 3 {
+		return ""
+	}
+	for _, r := range code {
+		if r < 'a' || r > 'z' {
+			return ""
+		}
+	}
+	return code
+}
+
+// buildProfile строит ранжированный профиль n-грамм текста.
+func buildProfile(text string) profile {
+	counts := make(map[string]int)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	for _, w := range words {
+		runes := []rune("_" + w + "_")
+		for n := 1; n <= maxNgram; n++ {
+			for i := 0; i+n <= len(runes); i++ {
+				counts[string(runes[i:i+n])]++
+			}
+		}
+	}
+
+	grams := make([]string, 0, len(counts))
+	for g := range counts {
+		grams = append(grams, g)
+	}
+	sort.Slice(grams, func(i, j int) bool {
+		if counts[grams[i]] == counts[grams[j]] {
+			return grams[i] < grams[j]
+		}
+		return counts[grams[i]] > counts[grams[j]]
+	})
+	if len(grams) > profileSize {
+		grams = grams[:profileSize]
+	}
+
+	p := make(profile, len(grams))
+	for rank, g := range grams {
+		p[g] = rank
+	}
+	return p
+}
+
+// distance вычисляет расстояние "out-of-place" между профилем документа и языка.
+func distance(doc, lang profile) int {
+	total := 0
+	for g, rank := range doc {
+		langRank, ok := lang[g]
+		if !ok {
+			total += profileSize
+			continue
+		}
+		if rank > langRank {
+			total += rank - langRank
+		} else {
+			total += langRank - rank
+		}
+	}
+	return total
+}
@@ -0,0 +1,101 @@
+package langdetect
+
+import (
+	"io"
+	"log/slog"
+	"newsservice/internal/infrastructure/config"
+	"testing"
+)
+
+func newDetector(t *testing.T) *Detector {
+	t.Helper()
+	d, err := New(config.LanguageConfig{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestDetect(t *testing.T) {
+	d := newDetector(t)
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"В Москве открылась новая станция метро", "ru"},
+		{"Правительство утвердило бюджет на три года", "ru"},
+		{"Курс гривні до долара знизився", "uk"},
+		{"Зеленський провів переговори з Макроном", "uk"},
+		{"Putin meets Xi in Beijing for talks on trade", "en"},
+		{"Fed holds rates steady, signals cuts later", "en"},
+		{"Die Inflation in Deutschland sinkt weiter", "de"},
+		{"Scholz trifft Macron in Paris zu Gesprächen", "de"},
+		{"", Undetermined},
+		{"Курс рубля", Undetermined},
+		{"Breaking: 2024 — 15%", Undetermined},
+	}
+	for _, tt := range tests {
+		if got, _ := d.Detect(tt.text); got != tt.want {
+			t.Errorf("Detect(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	d := newDetector(t)
+	tests := []struct {
+		name     string
+		declared string
+		text     string
+		want     string
+	}{
+		{"no declared language", "", "Die Inflation in Deutschland sinkt weiter", "de"},
+		{"short text keeps declared", "en", "Oil prices", "en"},
+		{"short text without declared", "", "Oil prices", ""},
+		{"low confidence keeps declared", "en", "Manchester United sign striker in record deal", "en"},
+		{"confident mismatch overrides", "en", "Die Bundesregierung hat am Mittwoch den Haushalt für das kommende Jahr beschlossen", "de"},
+		{"script mismatch overrides", "en", "В Москве открылась новая станция метро", "ru"},
+		{"unknown declared language kept", "pt", "Putin meets Xi in Beijing for talks on trade", "pt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := d.Resolve(tt.declared, tt.text); got != tt.want {
+				t.Fatalf("Resolve(%q, %q) = %q, want %q", tt.declared, tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveMinConfidence(t *testing.T) {
+	const text = "Die Inflation in Deutschland sinkt weiter"
+	d, err := New(config.LanguageConfig{MinConfidence: 0.9}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := d.Resolve("en", text); got != "en" {
+		t.Fatalf("Resolve with min_confidence 0.9 = %q, want declared en", got)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		code string
+		want string
+	}{
+		{"en", "en"},
+		{"en-US", "en"},
+		{"pt_BR", "pt"},
+		{"RU", "ru"},
+		{" Uk-UA ", "uk"},
+		{"fil", "fil"},
+		{"", ""},
+		{"e", ""},
+		{"english", ""},
+		{"1a", ""},
+	}
+	for _, tt := range tests {
+		if got := Normalize(tt.code); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.code, got, tt.want)
+		}
+	}
+}
@@ -3,23 +3,202 @@ package models
 import "time"
 
 type NewsFullDetailed struct {
+	NewsID          int       `json:"news_id"`
+	Title           string    `json:"title"`
+	Description     string    `json:"description"`
+	DescriptionText string    `json:"description_text"`
+	Content         string    `json:"content"`
+	ContentText     string    `json:"content_text"`
+	Author          string    `json:"author"`
+	PublishedAt     time.Time `json:"published_at"`
+	Source          string    `json:"source"`
+	Link            string    `json:"link"`
+	OriginalLink    string    `json:"original_link"`
+	Language        string    `json:"lang"`
+	Category        string    `json:"category"`
+	Tag             []string  `json:"tag"`
+	FeedCategories  []string  `json:"feed_categories,omitempty"`
+	Keywords        []string  `json:"keywords,omitempty"`
+	Entities        []Entity  `json:"entities,omitempty"`
+
+	// DescriptionGenerated описание составлено автоматически из текста статьи
+	DescriptionGenerated bool `json:"description_generated"`
+
+	AlsoReportedBy []RelatedNews `json:"also_reported_by,omitempty"`
+	Image          *Image        `json:"image,omitempty"`
+}
+
+// ImagesPath префикс HTTP-пути, по которому отдаются сохраненные изображения
+const ImagesPath = "/images/"
+
+// ImageURL возвращает путь к объекту хранилища изображений
+func ImageURL(key string) string {
+	return ImagesPath + key
+}
+
+// Image ведущее изображение новости, сохраненное в локальном кэше
+type Image struct {
+	URL        string      `json:"url"`
+	SourceURL  string      `json:"source_url"`
+	Origin     string      `json:"origin,omitempty"`
+	Width      int         `json:"width"`
+	Height     int         `json:"height"`
+	Thumbnails []Thumbnail `json:"thumbnails,omitempty"`
+}
+
+// Thumbnail уменьшенная копия изображения
+type Thumbnail struct {
+	URL    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// Entity именованная сущность (персона, место, организация), упомянутая в новости
+type Entity struct {
+	ID       int    `json:"id"`
+	Kind     string `json:"kind"`
+	Name     string `json:"name"`
+	Mentions int    `json:"mentions"`
+}
+
+// RelatedNews краткие сведения о новости того же сюжета из другого источника
+type RelatedNews struct {
 	NewsID      int       `json:"news_id"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	Content     string    `json:"content"`
-	Author      string    `json:"author"`
-	PublishedAt time.Time `json:"published_at"`
 	Source      string    `json:"source"`
 	Link        string    `json:"link"`
-	Tag         []string  `json:"tag"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+// Режимы схлопывания сюжетов в списках новостей: из сюжета выводится самая
+// ранняя новость или основная — с самым длинным текстом. Сюжеты строит только
+// PostgreSQL, остальные хранилища отклоняют схлопывание.
+const (
+	CollapseNone     = ""
+	CollapseEarliest = "earliest"
+	CollapsePrimary  = "primary"
+)
+
+// Направления сортировки
+const (
+	SortAsc  = "asc"
+	SortDesc = "desc"
+)
+
+// Поля сортировки списков новостей. По релевантности можно сортировать только
+// результаты поиска по тексту.
+const (
+	SortPublishedAt = "published_at"
+	SortFetchedAt   = "fetched_at"
+	SortTitle       = "title"
+	SortSource      = "source"
+	SortRelevance   = "relevance"
+)
+
+// SortFields допустимые поля сортировки в порядке вывода в сообщениях об ошибках
+var SortFields = []string{SortPublishedAt, SortFetchedAt, SortTitle, SortSource, SortRelevance}
+
+// SortField поле сортировки и направление: SortAsc или SortDesc
+type SortField struct {
+	Field string `json:"field"`
+	Order string `json:"order"`
+}
+
+// Keyset позиция для постраничного вывода по ключу (published_at, id) в
+// порядке Order. Backward выбирает новости перед позицией, а не после нее;
+// нулевой ID означает начало списка.
+type Keyset struct {
+	PublishedAt time.Time
+	ID          int
+	Order       string
+	Backward    bool
+}
+
+// Tag тег новостей. NewsCount — число новостей с тегом.
+type Tag struct {
+	ID        int64  `json:"-"`
+	Slug      string `json:"slug"`
+	Name      string `json:"name"`
+	NewsCount int    `json:"news_count"`
+}
+
+// Category категория новостей. Names — названия по кодам языков, Name —
+// название на запрошенном языке; Children заполняются при выводе дерева.
+type Category struct {
+	Slug      string            `json:"slug"`
+	Parent    string            `json:"parent,omitempty"`
+	Name      string            `json:"name"`
+	Names     map[string]string `json:"names"`
+	NewsCount int               `json:"news_count"`
+	Children  []Category        `json:"children,omitempty"`
 }
 
-// NewsFilter структура фильтра для поиска новостей
+// NewsRevision предыдущая версия новости, замененная правкой издателя.
+// Diff содержит пословное сравнение измененных полей с последующей версией.
+type NewsRevision struct {
+	Revision    int                 `json:"revision"`
+	Title       string              `json:"title"`
+	Description string              `json:"description"`
+	Content     string              `json:"content"`
+	PublishedAt time.Time           `json:"published_at"`
+	ReplacedAt  time.Time           `json:"replaced_at"`
+	Diff        map[string][]DiffOp `json:"diff"`
+}
+
+// DiffOp фрагмент сравнения версий: equal, insert или delete
+type DiffOp struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// Режимы отбора по тегам: хотя бы один из тегов или все теги
+const (
+	TagsMatchAny = "any"
+	TagsMatchAll = "all"
+)
+
+// NewsFilter структура фильтра для поиска новостей. Пустые поля не
+// ограничивают выборку; Exclude* исключают перечисленные значения.
+// From включает границу, To — нет.
 type NewsFilter struct {
-	Category string    `json:"category,omitempty"`
-	Author   string    `json:"author,omitempty"`
-	Date     time.Time `json:"date,omitempty"`
-	Limit    int       `json:"limit,omitempty"`
-	Offset   int       `json:"offset,omitempty"`
-	OrderBy  string    `json:"order_by,omitempty"`
+	Sources           []string    `json:"sources,omitempty"`
+	ExcludeSources    []string    `json:"exclude_sources,omitempty"`
+	Categories        []string    `json:"categories,omitempty"`
+	ExcludeCategories []string    `json:"exclude_categories,omitempty"`
+	Tags              []string    `json:"tags,omitempty"`
+	TagsMatch         string      `json:"tags_match,omitempty"`
+	ExcludeTags       []string    `json:"exclude_tags,omitempty"`
+	Languages         []string    `json:"langs,omitempty"`
+	Author            string      `json:"author,omitempty"`
+	From              time.Time   `json:"from,omitempty"`
+	To                time.Time   `json:"to,omitempty"`
+	HasImage          *bool       `json:"has_image,omitempty"`
+	Limit             int         `json:"limit,omitempty"`
+	Offset            int         `json:"offset,omitempty"`
+	Sort              []SortField `json:"sort,omitempty"`
+	Collapse          string      `json:"collapse,omitempty"`
+	Entity            string      `json:"entity,omitempty"`
+	Query             string      `json:"q,omitempty"`
+	Keyset            *Keyset     `json:"-"`
+}
+
+// SearchResult — новость, найденная полнотекстовым поиском. В TitleHighlight и
+// Snippet совпадения выделены тегами <mark>, остальной текст экранирован.
+type SearchResult struct {
+	NewsID         int       `json:"news_id"`
+	Title          string    `json:"title"`
+	TitleHighlight string    `json:"title_highlight"`
+	Snippet        string    `json:"snippet"`
+	Link           string    `json:"link"`
+	Source         string    `json:"source"`
+	Language       string    `json:"lang"`
+	Category       string    `json:"category"`
+	PublishedAt    time.Time `json:"published_at"`
+	Rank           float64   `json:"rank"`
+}
+
+// SourceLanguageStats распределение новостей источника по языкам
+type SourceLanguageStats struct {
+	Source    string         `json:"source"`
+	Total     int            `json:"total"`
+	Languages map[string]int `json:"languages"`
 }
@@ -0,0 +1,92 @@
+// Package netguard создает HTTP-клиенты для загрузки адресов, полученных
+// извне (фиды, страницы и изображения новостей), которые не могут
+// соединиться с внутренними адресами сервиса.
+package netguard
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// maxRedirects — наибольшее число перенаправлений при загрузке.
+const maxRedirects = 5
+
+// ErrBlockedAddress возвращается при попытке соединиться с непубличным адресом.
+var ErrBlockedAddress = errors.New("address is not allowed")
+
+// blockedPrefixes — служебные диапазоны адресов, не покрытые методами netip.Addr.
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+	netip.MustParsePrefix("2001:db8::/32"),
+}
+
+// NewClient создает HTTP-клиент для загрузки фидов, страниц и изображений.
+// Ссылки задаются извне, поэтому соединения с внутренними адресами
+// запрещены: адрес проверяется после разрешения имени, в том числе при каждом
+// перенаправлении. allowPrivate снимает запрет для локальной разработки.
+func NewClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivate {
+		dialer.Control = rejectPrivate
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// Через прокси проверялся бы адрес прокси, а не адрес назначения.
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:       timeout,
+		Transport:     transport,
+		CheckRedirect: checkRedirect,
+	}
+}
+
+// checkRedirect ограничивает число перенаправлений и разрешает только http(s).
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxRedirects)
+	}
+	if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+		return fmt.Errorf("redirect to unsupported scheme %q", req.URL.Scheme)
+	}
+	return nil
+}
+
+// rejectPrivate запрещает соединение с адресом, не являющимся публичным.
+func rejectPrivate(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, address)
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil || !IsPublic(ip) {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, address)
+	}
+	return nil
+}
+
+// IsPublic проверяет, что адрес принадлежит публичному интернету: не
+// loopback, не link-local (в том числе 169.254.169.254), не из частных и
+// служебных диапазонов.
+func IsPublic(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if !ip.IsGlobalUnicast() || ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() {
+		return false
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,65 @@
+package netguard
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestClientBlocksPrivateAddresses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "file:///etc/passwd", http.StatusFound)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	if _, err := NewClient(time.Second, false).Get(srv.URL); !errors.Is(err, ErrBlockedAddress) {
+		t.Fatalf("Get(loopback) error = %v, want ErrBlockedAddress", err)
+	}
+	client := NewClient(time.Second, true)
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Get(loopback) with allowPrivate: %v", err)
+	}
+	resp.Body.Close()
+	if _, err := client.Get(srv.URL + "/redirect"); err == nil {
+		t.Fatal("redirect to file:// was followed")
+	}
+}
+
+func TestIsPublic(t *testing.T) {
+	cases := []struct {
+		addr string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:4700::1111", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"100.64.0.1", false},
+		{"0.0.0.0", false},
+		{"::", false},
+		{"fd00::1", false},
+		{"224.0.0.1", false},
+		{"255.255.255.255", false},
+		{"::ffff:127.0.0.1", false},
+		{"::ffff:10.0.0.1", false},
+		{"64:ff9b::a9fe:a9fe", false},
+	}
+	for _, c := range cases {
+		if got := IsPublic(netip.MustParseAddr(c.addr)); got != c.want {
+			t.Fatalf("IsPublic(%s) = %v, want %v", c.addr, got, c.want)
+		}
+	}
+}
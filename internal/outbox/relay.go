@@ -0,0 +1,209 @@
+// Package outbox публикует в брокер сообщения, записанные в таблицу outbox в
+// одной транзакции с изменениями данных.
+package outbox
+
+import (
+	"context"
+	"expvar"
+	"log/slog"
+	"newsservice/internal/broker"
+	"newsservice/internal/infrastructure/config"
+	"newsservice/storage"
+	"time"
+)
+
+const (
+	defaultPollInterval = time.Second
+	defaultBatchSize    = 100
+	defaultRetention    = 7 * 24 * time.Hour
+	defaultBaseBackoff  = time.Second
+	defaultMaxBackoff   = 5 * time.Minute
+	defaultMaxAttempts  = 10
+	cleanupInterval     = time.Hour
+)
+
+// Метрики отставания публикации, доступные через /admin/debug/vars.
+var (
+	pendingMetric = expvar.NewInt("outbox_pending")
+	lagMetric     = expvar.NewFloat("outbox_lag_seconds")
+	deadMetric    = expvar.NewInt("outbox_dead_total")
+)
+
+// Relay периодически публикует недоставленные сообщения outbox.
+// Сообщения с одним ключом публикуются строго по порядку записи: пока
+// предыдущее сообщение ключа не доставлено, следующие не отправляются.
+// Сообщение, не доставленное за maxAttempts попыток, отмечается мертвым и
+// больше не задерживает следующие сообщения ключа. Реле рассчитано на один
+// экземпляр; при повторной отправке после сбоя потребители распознают
+// дубликаты по event_id.
+type Relay struct {
+	store        storage.OutboxStorage
+	producer     broker.Publisher
+	log          *slog.Logger
+	pollInterval time.Duration
+	batchSize    int
+	retention    time.Duration
+	maxBackoff   time.Duration
+	maxAttempts  int
+}
+
+func NewRelay(cfg config.OutboxConfig, store storage.OutboxStorage, producer broker.Publisher, log *slog.Logger) *Relay {
+	r := &Relay{
+		store:        store,
+		producer:     producer,
+		log:          log.With(slog.String("component", "outbox-relay")),
+		pollInterval: cfg.PollInterval,
+		batchSize:    cfg.BatchSize,
+		retention:    cfg.Retention,
+		maxBackoff:   cfg.MaxBackoff,
+		maxAttempts:  cfg.MaxAttempts,
+	}
+	if r.pollInterval <= 0 {
+		r.pollInterval = defaultPollInterval
+	}
+	if r.batchSize <= 0 {
+		r.batchSize = defaultBatchSize
+	}
+	if r.retention <= 0 {
+		r.retention = defaultRetention
+	}
+	if r.maxBackoff <= 0 {
+		r.maxBackoff = defaultMaxBackoff
+	}
+	if r.maxAttempts <= 0 {
+		r.maxAttempts = defaultMaxAttempts
+	}
+	return r
+}
+
+// Run публикует сообщения и удаляет старые доставленные до отмены ctx.
+func (r *Relay) Run(ctx context.Context) {
+	r.log.Info("Outbox relay started")
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+	lastCleanup := time.Time{}
+	for {
+		for {
+			sent, err := r.RelayOnce(ctx)
+			if err != nil {
+				r.log.Error("Outbox relay iteration failed", slog.Any("error", err))
+				break
+			}
+			// Полная пачка означает, что в очереди могут оставаться сообщения.
+			if sent < r.batchSize || ctx.Err() != nil {
+				break
+			}
+		}
+		r.updateMetrics(ctx)
+		if time.Since(lastCleanup) >= cleanupInterval {
+			r.cleanup(ctx)
+			lastCleanup = time.Now()
+		}
+		select {
+		case <-ctx.Done():
+			r.log.Info("Outbox relay stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayOnce отправляет одну пачку сообщений и возвращает число доставленных.
+func (r *Relay) RelayOnce(ctx context.Context) (int, error) {
+	messages, err := r.store.FetchPendingOutbox(ctx, r.batchSize)
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	blocked := make(map[string]bool)
+	sent := 0
+	for _, m := range messages {
+		if ctx.Err() != nil {
+			return sent, nil
+		}
+		key := m.Topic + "/" + m.Key
+		if blocked[key] {
+			continue
+		}
+		if err := r.send(ctx, m); err != nil {
+			blocked[key] = true
+			if err := r.fail(ctx, m, err, now); err != nil {
+				return sent, err
+			}
+			continue
+		}
+		if err := r.store.MarkOutboxDelivered(ctx, m.ID); err != nil {
+			return sent, err
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+// fail записывает неудачную попытку публикации, а после maxAttempts попыток
+// отмечает сообщение мертвым.
+func (r *Relay) fail(ctx context.Context, m storage.OutboxMessage, sendErr error, now time.Time) error {
+	attempts := m.Attempts + 1
+	if attempts >= r.maxAttempts {
+		r.log.Error("Outbox message dropped after max attempts",
+			slog.Int64("id", m.ID),
+			slog.String("topic", m.Topic),
+			slog.String("key", m.Key),
+			slog.Int("attempts", attempts),
+			slog.Any("error", sendErr),
+		)
+		deadMetric.Add(1)
+		return r.store.MarkOutboxDead(ctx, m.ID, sendErr.Error())
+	}
+	next := now.Add(r.backoff(m.Attempts))
+	r.log.Warn("Outbox message publish failed",
+		slog.Int64("id", m.ID),
+		slog.String("topic", m.Topic),
+		slog.Int("attempts", attempts),
+		slog.Time("next_attempt", next),
+		slog.Any("error", sendErr),
+	)
+	return r.store.MarkOutboxFailed(ctx, m.ID, sendErr.Error(), next)
+}
+
+func (r *Relay) send(ctx context.Context, m storage.OutboxMessage) error {
+	var key []byte
+	if m.Key != "" {
+		key = []byte(m.Key)
+	}
+	return r.producer.Publish(ctx, m.Topic, key, m.Payload)
+}
+
+// backoff возвращает экспоненциальную задержку перед следующей попыткой.
+func (r *Relay) backoff(attempts int) time.Duration {
+	d := defaultBaseBackoff
+	for i := 0; i < attempts && d < r.maxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, r.maxBackoff)
+}
+
+func (r *Relay) updateMetrics(ctx context.Context) {
+	pending, oldest, err := r.store.GetOutboxLag(ctx)
+	if err != nil {
+		r.log.Error("Failed to read outbox lag", slog.Any("error", err))
+		return
+	}
+	pendingMetric.Set(int64(pending))
+	if oldest.IsZero() {
+		lagMetric.Set(0)
+		return
+	}
+	lagMetric.Set(time.Since(oldest).Seconds())
+}
+
+func (r *Relay) cleanup(ctx context.Context) {
+	deleted, err := r.store.DeleteDeliveredOutbox(ctx, time.Now().Add(-r.retention))
+	if err != nil {
+		r.log.Error("Outbox cleanup failed", slog.Any("error", err))
+		return
+	}
+	if deleted > 0 {
+		r.log.Info("Outbox cleaned up", slog.Int64("deleted", deleted))
+	}
+}
@@ -0,0 +1,267 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"newsservice/internal/broker"
+	"newsservice/internal/domain"
+	"newsservice/internal/events"
+	"newsservice/internal/infrastructure/config"
+	"newsservice/storage"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+)
+
+const eventsTopic = "news_events"
+
+// fakeOutbox — реализация storage.OutboxStorage в памяти.
+type fakeOutbox struct {
+	messages  []storage.OutboxMessage
+	delivered map[int64]bool
+	dead      map[int64]bool
+}
+
+func (f *fakeOutbox) enqueue(t *testing.T, feed *domain.Feed) {
+	t.Helper()
+	for _, event := range events.FromFeed(feed, time.Now()) {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.messages = append(f.messages, storage.OutboxMessage{
+			ID:      int64(len(f.messages) + 1),
+			Topic:   eventsTopic,
+			Key:     strconv.FormatInt(event.News.ID, 10),
+			Payload: payload,
+		})
+	}
+}
+
+// FetchPendingOutbox, как и Storage, выбирает сообщения ключей, для самого
+// раннего сообщения которых подошло время попытки.
+func (f *fakeOutbox) FetchPendingOutbox(ctx context.Context, limit int) ([]storage.OutboxMessage, error) {
+	now := time.Now()
+	ready := make(map[string]bool)
+	var pending []storage.OutboxMessage
+	for _, m := range f.messages {
+		if f.delivered[m.ID] || f.dead[m.ID] {
+			continue
+		}
+		key := m.Topic + "/" + m.Key
+		if _, ok := ready[key]; !ok {
+			ready[key] = !m.NextAttemptAt.After(now)
+		}
+		if ready[key] && len(pending) < limit {
+			pending = append(pending, m)
+		}
+	}
+	return pending, nil
+}
+
+func (f *fakeOutbox) MarkOutboxDelivered(ctx context.Context, id int64) error {
+	f.delivered[id] = true
+	return nil
+}
+
+func (f *fakeOutbox) MarkOutboxFailed(ctx context.Context, id int64, reason string, nextAttempt time.Time) error {
+	f.messages[id-1].Attempts++
+	f.messages[id-1].NextAttemptAt = nextAttempt
+	return nil
+}
+
+func (f *fakeOutbox) MarkOutboxDead(ctx context.Context, id int64, reason string) error {
+	f.messages[id-1].Attempts++
+	if f.dead == nil {
+		f.dead = make(map[int64]bool)
+	}
+	f.dead[id] = true
+	return nil
+}
+
+func (f *fakeOutbox) DeleteDeliveredOutbox(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (f *fakeOutbox) GetOutboxLag(ctx context.Context) (int, time.Time, error) {
+	return 0, time.Time{}, nil
+}
+
+// flakyPublisher отклоняет сообщения с ключом failKey.
+type flakyPublisher struct {
+	broker.Publisher
+	failKey string
+}
+
+func (p *flakyPublisher) Publish(ctx context.Context, topic string, key, value []byte) error {
+	if string(key) == p.failKey {
+		return errors.New("broker unavailable")
+	}
+	return p.Publisher.Publish(ctx, topic, key, value)
+}
+
+func newRelay(store storage.OutboxStorage, publisher broker.Publisher) *Relay {
+	return NewRelay(config.OutboxConfig{BatchSize: 10}, store, publisher, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func feed(items ...domain.Item) *domain.Feed {
+	return &domain.Feed{Source: "example", Items: items}
+}
+
+func readEvents(t *testing.T, sub broker.Subscriber, n int) []events.NewsEvent {
+	t.Helper()
+	var out []events.NewsEvent
+	for len(out) < n {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		msg, err := sub.Receive(ctx)
+		cancel()
+		if err != nil {
+			t.Fatalf("receive event %d: %v", len(out), err)
+		}
+		var event events.NewsEvent
+		if err := json.Unmarshal(msg.Value, &event); err != nil {
+			t.Fatalf("invalid event: %v", err)
+		}
+		if string(msg.Key) != strconv.FormatInt(event.News.ID, 10) {
+			t.Fatalf("event key = %q, want news ID %d", msg.Key, event.News.ID)
+		}
+		out = append(out, event)
+	}
+	return out
+}
+
+func TestRelayPublishesNewsEvents(t *testing.T) {
+	mem := broker.NewMemory(4)
+	sub := mem.Subscribe(eventsTopic, "indexer")
+	store := &fakeOutbox{delivered: map[int64]bool{}}
+
+	store.enqueue(t, feed(
+		domain.Item{ID: 1, Title: "One", Status: domain.StatusCreated},
+		domain.Item{ID: 2, Title: "Two", Status: domain.StatusCreated},
+		domain.Item{ID: 3, Title: "Three", Status: domain.StatusUnchanged},
+	))
+	store.enqueue(t, feed(domain.Item{ID: 1, Title: "One, updated", Status: domain.StatusUpdated}))
+
+	sent, err := newRelay(store, mem).RelayOnce(context.Background())
+	if err != nil || sent != 3 {
+		t.Fatalf("RelayOnce = %d, %v; want 3 sent", sent, err)
+	}
+
+	got := readEvents(t, sub, 3)
+	var first []string
+	for _, e := range got {
+		if e.News.ID == 1 {
+			first = append(first, e.Type)
+		}
+		if e.News.ID == 3 {
+			t.Fatalf("event published for unchanged news 3")
+		}
+	}
+	if len(first) != 2 || first[0] != events.TypeNewsCreated || first[1] != events.TypeNewsUpdated {
+		t.Fatalf("events for news 1 = %v, want created then updated", first)
+	}
+}
+
+func TestRelayHoldsBackKeyAfterFailure(t *testing.T) {
+	mem := broker.NewMemory(2)
+	sub := mem.Subscribe(eventsTopic, "indexer")
+	store := &fakeOutbox{delivered: map[int64]bool{}}
+	store.enqueue(t, feed(
+		domain.Item{ID: 1, Status: domain.StatusCreated},
+		domain.Item{ID: 2, Status: domain.StatusCreated},
+	))
+	store.enqueue(t, feed(domain.Item{ID: 1, Status: domain.StatusUpdated}))
+
+	sent, err := newRelay(store, &flakyPublisher{Publisher: mem, failKey: "1"}).RelayOnce(context.Background())
+	if err != nil || sent != 1 {
+		t.Fatalf("RelayOnce = %d, %v; want 1 sent", sent, err)
+	}
+	if store.messages[0].Attempts != 1 || store.messages[2].Attempts != 0 {
+		t.Fatalf("attempts = %d, %d; later event of news 1 must wait", store.messages[0].Attempts, store.messages[2].Attempts)
+	}
+	if got := readEvents(t, sub, 1); got[0].News.ID != 2 {
+		t.Fatalf("published news %d, want 2", got[0].News.ID)
+	}
+
+	// После восстановления брокера события news 1 уходят в исходном порядке.
+	for i := range store.messages {
+		store.messages[i].NextAttemptAt = time.Time{}
+	}
+	if sent, err := newRelay(store, mem).RelayOnce(context.Background()); err != nil || sent != 2 {
+		t.Fatalf("RelayOnce after recovery = %d, %v; want 2 sent", sent, err)
+	}
+	got := readEvents(t, sub, 2)
+	if got[0].Type != events.TypeNewsCreated || got[1].Type != events.TypeNewsUpdated {
+		t.Fatalf("events = %s, %s; want created then updated", got[0].Type, got[1].Type)
+	}
+}
+
+// flakyKeysPublisher отклоняет сообщения с ключами из failKeys.
+type flakyKeysPublisher struct {
+	broker.Publisher
+	failKeys map[string]bool
+}
+
+func (p *flakyKeysPublisher) Publish(ctx context.Context, topic string, key, value []byte) error {
+	if p.failKeys[string(key)] {
+		return errors.New("message too large")
+	}
+	return p.Publisher.Publish(ctx, topic, key, value)
+}
+
+func TestRelaySkipsPermanentlyFailingKeys(t *testing.T) {
+	mem := broker.NewMemory(8)
+	sub := mem.Subscribe(eventsTopic, "indexer")
+	store := &fakeOutbox{delivered: map[int64]bool{}}
+	store.enqueue(t, feed(
+		domain.Item{ID: 1, Status: domain.StatusCreated},
+		domain.Item{ID: 2, Status: domain.StatusCreated},
+		domain.Item{ID: 3, Status: domain.StatusCreated},
+		domain.Item{ID: 4, Status: domain.StatusCreated},
+	))
+	store.enqueue(t, feed(domain.Item{ID: 1, Status: domain.StatusUpdated}))
+
+	publisher := &flakyKeysPublisher{Publisher: mem, failKeys: map[string]bool{"1": true, "2": true}}
+	relay := NewRelay(config.OutboxConfig{BatchSize: 2, MaxAttempts: 3}, store, publisher, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ctx := context.Background()
+
+	// Первые два ключа занимают всю пачку и не доставляются, но после
+	// неудачи ждут своей попытки и не мешают следующим ключам.
+	if sent, err := relay.RelayOnce(ctx); err != nil || sent != 0 {
+		t.Fatalf("first RelayOnce = %d, %v; want 0 sent", sent, err)
+	}
+	if sent, err := relay.RelayOnce(ctx); err != nil || sent != 2 {
+		t.Fatalf("second RelayOnce = %d, %v; want 2 sent", sent, err)
+	}
+	var ids []int64
+	for _, e := range readEvents(t, sub, 2) {
+		ids = append(ids, e.News.ID)
+	}
+	if !slices.Equal(ids, []int64{3, 4}) {
+		t.Fatalf("published news %v, want [3 4]", ids)
+	}
+
+	// После maxAttempts попыток сообщения отмечаются мертвыми, а следующее
+	// сообщение того же ключа отправляется.
+	for range 2 {
+		store.messages[0].NextAttemptAt = time.Time{}
+		store.messages[1].NextAttemptAt = time.Time{}
+		if _, err := relay.RelayOnce(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !store.dead[1] || !store.dead[2] || store.messages[0].Attempts != 3 {
+		t.Fatalf("dead = %v, attempts = %d; want messages 1 and 2 dead after 3 attempts", store.dead, store.messages[0].Attempts)
+	}
+	delete(publisher.failKeys, "1")
+	if sent, err := relay.RelayOnce(ctx); err != nil || sent != 1 {
+		t.Fatalf("RelayOnce after dead head = %d, %v; want 1 sent", sent, err)
+	}
+	if got := readEvents(t, sub, 1); got[0].News.ID != 1 || got[0].Type != events.TypeNewsUpdated {
+		t.Fatalf("published %s for news %d, want update of news 1", got[0].Type, got[0].News.ID)
+	}
+}
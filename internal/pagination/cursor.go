@@ -0,0 +1,180 @@
+package pagination
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"newsservice/internal/models"
+	"strings"
+	"time"
+)
+
+// cursorVersion — версия формата токена курсора.
+const cursorVersion = 1
+
+// cursorMACSize — длина подписи токена в байтах.
+const cursorMACSize = 16
+
+// ErrInvalidCursor возвращается для поврежденного, чужого или устаревшего токена курсора.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor — позиция в списке новостей: ключ (PublishedAt, ID) крайней новости
+// страницы и порядок сортировки. Backward означает переход к предыдущей
+// странице, то есть выборку новостей перед позицией.
+type Cursor struct {
+	PublishedAt time.Time
+	ID          int
+	Order       string
+	Backward    bool
+}
+
+// Keyset возвращает позицию курсора для фильтра хранилища.
+func (c Cursor) Keyset() *models.Keyset {
+	return &models.Keyset{
+		PublishedAt: c.PublishedAt,
+		ID:          c.ID,
+		Order:       c.Order,
+		Backward:    c.Backward,
+	}
+}
+
+// cursorPayload — содержимое токена курсора.
+type cursorPayload struct {
+	Version     int    `json:"v"`
+	PublishedAt int64  `json:"t"`
+	ID          int    `json:"i"`
+	Order       string `json:"o"`
+	Backward    bool   `json:"b,omitempty"`
+}
+
+// CursorCodec кодирует курсоры в непрозрачные токены, подписанные HMAC-SHA256,
+// и проверяет подпись при разборе, чтобы клиент не мог подделать позицию.
+type CursorCodec struct {
+	key []byte
+}
+
+// NewCursorCodec создает кодек с ключом secret. Если ключ пуст, генерируется
+// случайный: выданные токены перестанут приниматься после перезапуска.
+func NewCursorCodec(secret []byte) *CursorCodec {
+	if len(secret) == 0 {
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			panic("pagination: failed to generate cursor key: " + err.Error())
+		}
+	}
+	return &CursorCodec{key: secret}
+}
+
+// Encode возвращает токен курсора.
+func (c *CursorCodec) Encode(cur Cursor) string {
+	payload, _ := json.Marshal(cursorPayload{
+		Version:     cursorVersion,
+		PublishedAt: cur.PublishedAt.UnixNano(),
+		ID:          cur.ID,
+		Order:       cur.Order,
+		Backward:    cur.Backward,
+	})
+	body := base64.RawURLEncoding.EncodeToString(payload)
+	return body + "." + base64.RawURLEncoding.EncodeToString(c.sign(body))
+}
+
+// Decode проверяет подпись токена и возвращает курсор.
+func (c *CursorCodec) Decode(token string) (Cursor, error) {
+	body, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return Cursor{}, ErrInvalidCursor
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(mac, c.sign(body)) {
+		return Cursor{}, ErrInvalidCursor
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(body)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	var p cursorPayload
+	if err := json.Unmarshal(raw, &p); err != nil || p.Version != cursorVersion || p.ID <= 0 {
+		return Cursor{}, ErrInvalidCursor
+	}
+	if p.Order != models.SortAsc && p.Order != models.SortDesc {
+		return Cursor{}, ErrInvalidCursor
+	}
+	return Cursor{
+		PublishedAt: time.Unix(0, p.PublishedAt).UTC(),
+		ID:          p.ID,
+		Order:       p.Order,
+		Backward:    p.Backward,
+	}, nil
+}
+
+func (c *CursorCodec) sign(body string) []byte {
+	h := hmac.New(sha256.New, c.key)
+	h.Write([]byte(body))
+	return h.Sum(nil)[:cursorMACSize]
+}
+
+// CursorPage — страница новостей при выводе по курсору. Общее число новостей
+// не подсчитывается; NextCursor и PrevCursor передаются в параметре cursor
+// для перехода к соседним страницам.
+type CursorPage struct {
+	NewsPerPage int                       `json:"news_per_page"`
+	Results     []models.NewsFullDetailed `json:"results"`
+	HasNext     bool                      `json:"has_next"`
+	HasPrev     bool                      `json:"has_prev"`
+	NextCursor  string                    `json:"next_cursor,omitempty"`
+	PrevCursor  string                    `json:"prev_cursor,omitempty"`
+}
+
+// NewCursorPage строит страницу по результатам выборки с запасом: хранилище
+// возвращает до limit+1 новостей в порядке вывода, лишняя новость означает,
+// что в направлении перехода есть еще страница. cur — курсор запроса, nil
+// для первой страницы.
+func NewCursorPage(codec *CursorCodec, news []models.NewsFullDetailed, limit int, order string, cur *Cursor) *CursorPage {
+	backward := cur != nil && cur.Backward
+	more := len(news) > limit
+	if more {
+		if backward {
+			news = news[len(news)-limit:]
+		} else {
+			news = news[:limit]
+		}
+	}
+	if news == nil {
+		news = []models.NewsFullDetailed{}
+	}
+
+	page := &CursorPage{NewsPerPage: limit, Results: news}
+	if backward {
+		page.HasPrev, page.HasNext = more, true
+	} else {
+		page.HasNext, page.HasPrev = more, cur != nil
+	}
+
+	if len(news) == 0 {
+		// Пустая страница: вернуться можно к позиции, с которой пришел запрос.
+		if cur != nil {
+			back := *cur
+			back.Backward = !cur.Backward
+			if backward {
+				page.HasPrev = false
+				page.NextCursor = codec.Encode(back)
+			} else {
+				page.HasNext = false
+				page.PrevCursor = codec.Encode(back)
+			}
+		}
+		return page
+	}
+	if page.HasNext {
+		last := news[len(news)-1]
+		page.NextCursor = codec.Encode(Cursor{PublishedAt: last.PublishedAt, ID: last.NewsID, Order: order})
+	}
+	if page.HasPrev {
+		first := news[0]
+		page.PrevCursor = codec.Encode(Cursor{PublishedAt: first.PublishedAt, ID: first.NewsID, Order: order, Backward: true})
+	}
+	return page
+}
@@ -20,6 +20,15 @@ type Pagination struct {
 }
 
 func New(totalResults int, currentPage int) *Pagination {
+	return NewWithLimit(totalResults, currentPage, NEWS_PER_PAGE)
+}
+
+// NewWithLimit создает пагинацию с указанным количеством новостей на странице
+func NewWithLimit(totalResults int, currentPage int, newsPerPage int) *Pagination {
+	if newsPerPage < 1 {
+		newsPerPage = NEWS_PER_PAGE
+	}
+
 	if currentPage < 1 {
 		currentPage = 1
 	}
@@ -28,7 +37,7 @@ func New(totalResults int, currentPage int) *Pagination {
 		totalResults = 0
 	}
 
-	totalPages := calculateTotalPages(totalResults)
+	totalPages := calculateTotalPages(totalResults, newsPerPage)
 
 	if currentPage > totalPages && totalPages > 0 {
 		currentPage = totalPages
@@ -49,7 +58,7 @@ func New(totalResults int, currentPage int) *Pagination {
 		TotalResults: totalResults,
 		TotalPages:   totalPages,
 		CurrentPage:  currentPage,
-		NewsPerPage:  NEWS_PER_PAGE,
+		NewsPerPage:  newsPerPage,
 		HasNext:      hasNext,
 		HasPrev:      hasPrev,
 		NextPage:     nextPage,
@@ -58,13 +67,13 @@ func New(totalResults int, currentPage int) *Pagination {
 }
 
 // calculateTotalPages вычисляет общее количество страниц
-func calculateTotalPages(totalResults int) int {
+func calculateTotalPages(totalResults int, newsPerPage int) int {
 	if totalResults <= 0 {
 		return 0
 	}
 
-	totalPages := totalResults / NEWS_PER_PAGE
-	if totalResults%NEWS_PER_PAGE != 0 {
+	totalPages := totalResults / newsPerPage
+	if totalResults%newsPerPage != 0 {
 		totalPages++
 	}
 
@@ -92,7 +101,7 @@ func (p *Pagination) SetResults(results []models.NewsFullDetailed) {
 	if len(results) < p.NewsPerPage && p.CurrentPage == p.TotalPages {
 		// На последней странице получили меньше результатов чем ожидалось
 		p.TotalResults = (p.TotalPages-1)*p.NewsPerPage + len(results)
-		p.TotalPages = calculateTotalPages(p.TotalResults)
+		p.TotalPages = calculateTotalPages(p.TotalResults, p.NewsPerPage)
 		p.updateNavigation()
 	}
 }
@@ -12,21 +12,49 @@ import (
 )
 
 type rssXML struct {
-	Channel channelXML `json:"channel"`
+	Channel channelXML `xml:"channel"`
 }
 
 type channelXML struct {
-	Title       string    `json:"title"`
-	Link        string    `json:"link"`
-	Description string    `json:"description"`
-	Items       []itemXML `json:"item"`
+	Title       string    `xml:"title"`
+	Link        string    `xml:"link"`
+	Description string    `xml:"description"`
+	Language    string    `xml:"language"`
+	Items       []itemXML `xml:"item"`
 }
 
 type itemXML struct {
-	Title       string `json:"title"`
-	Link        string `json:"link"`
-	Description string `json:"description"`
-	PubDate     string `json:"pubDate"`
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	Description string   `xml:"description"`
+	Content     string   `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	PubDate     string   `xml:"pubDate"`
+	Categories  []string `xml:"category"`
+	Author      string   `xml:"author"`
+	Creator     string   `xml:"http://purl.org/dc/elements/1.1/ creator"`
+
+	Enclosures      []enclosureXML  `xml:"enclosure"`
+	MediaContent    []mediaXML      `xml:"http://search.yahoo.com/mrss/ content"`
+	MediaThumbnails []mediaXML      `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+	MediaGroups     []mediaGroupXML `xml:"http://search.yahoo.com/mrss/ group"`
+}
+
+type enclosureXML struct {
+	URL  string `xml:"url,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type mediaXML struct {
+	URL    string `xml:"url,attr"`
+	Type   string `xml:"type,attr"`
+	Medium string `xml:"medium,attr"`
+	Width  int    `xml:"width,attr"`
+	Height int    `xml:"height,attr"`
+}
+
+type mediaGroupXML struct {
+	Content    []mediaXML `xml:"http://search.yahoo.com/mrss/ content"`
+	Thumbnails []mediaXML `xml:"http://search.yahoo.com/mrss/ thumbnail"`
 }
 
 type XMLParser struct {
@@ -56,6 +84,7 @@ func (p *XMLParser) Parse(ctx context.Context, reader io.Reader) (*domain.Feed,
 		Title:       rss.Channel.Title,
 		Link:        rss.Channel.Link,
 		Description: rss.Channel.Description,
+		Language:    rss.Channel.Language,
 		Items:       make([]domain.Item, 0, len(rss.Channel.Items)),
 	}
 	for _, itemDTO := range rss.Channel.Items {
@@ -73,13 +102,73 @@ func (p *XMLParser) Parse(ctx context.Context, reader io.Reader) (*domain.Feed,
 			Title:       itemDTO.Title,
 			Link:        itemDTO.Link,
 			Description: itemDTO.Description,
+			Content:     itemDTO.Content,
 			PubDate:     pubDate,
+			Categories:  itemDTO.Categories,
+			Author:      itemAuthor(itemDTO),
+			Images:      imageCandidates(itemDTO),
 		}
 		feed.Items = append(feed.Items, item)
 	}
 	return &feed, nil
 }
 
+// itemAuthor возвращает автора элемента: dc:creator, а при его отсутствии author.
+func itemAuthor(item itemXML) string {
+	if creator := strings.TrimSpace(item.Creator); creator != "" {
+		return creator
+	}
+	return strings.TrimSpace(item.Author)
+}
+
+// imageCandidates собирает изображения из enclosure и media:* тегов элемента.
+func imageCandidates(item itemXML) []domain.ImageCandidate {
+	var candidates []domain.ImageCandidate
+	for _, enc := range item.Enclosures {
+		if enc.URL == "" || !strings.HasPrefix(enc.Type, "image/") {
+			continue
+		}
+		candidates = append(candidates, domain.ImageCandidate{
+			URL:    enc.URL,
+			Type:   enc.Type,
+			Origin: domain.ImageOriginEnclosure,
+		})
+	}
+	content := item.MediaContent
+	thumbnails := item.MediaThumbnails
+	for _, group := range item.MediaGroups {
+		content = append(content, group.Content...)
+		thumbnails = append(thumbnails, group.Thumbnails...)
+	}
+	for _, m := range content {
+		if m.URL == "" {
+			continue
+		}
+		if m.Medium != "image" && !strings.HasPrefix(m.Type, "image/") {
+			continue
+		}
+		candidates = append(candidates, domain.ImageCandidate{
+			URL:    m.URL,
+			Type:   m.Type,
+			Width:  m.Width,
+			Height: m.Height,
+			Origin: domain.ImageOriginMediaContent,
+		})
+	}
+	for _, m := range thumbnails {
+		if m.URL == "" {
+			continue
+		}
+		candidates = append(candidates, domain.ImageCandidate{
+			URL:    m.URL,
+			Width:  m.Width,
+			Height: m.Height,
+			Origin: domain.ImageOriginMediaThumbnail,
+		})
+	}
+	return candidates
+}
+
 // parsePubDate - вспомогательная функция для парсинга даты в разных форматах.
 func parsePubDate(dateStr string) (time.Time, error) {
 	formats := []string{
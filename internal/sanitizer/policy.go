@@ -0,0 +1,229 @@
+package sanitizer
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html/atom"
+)
+
+// allowedTags — allow-list тегов и допустимых для них атрибутов.
+var allowedTags = map[atom.Atom][]string{
+	atom.A:          {"href", "title"},
+	atom.Img:        {"src", "alt", "title", "width", "height"},
+	atom.P:          nil,
+	atom.Br:         nil,
+	atom.Hr:         nil,
+	atom.B:          nil,
+	atom.Strong:     nil,
+	atom.I:          nil,
+	atom.Em:         nil,
+	atom.U:          nil,
+	atom.S:          nil,
+	atom.Sub:        nil,
+	atom.Sup:        nil,
+	atom.Small:      nil,
+	atom.Blockquote: {"cite"},
+	atom.Q:          {"cite"},
+	atom.Code:       nil,
+	atom.Pre:        nil,
+	atom.Ul:         nil,
+	atom.Ol:         nil,
+	atom.Li:         nil,
+	atom.Dl:         nil,
+	atom.Dt:         nil,
+	atom.Dd:         nil,
+	atom.H1:         nil,
+	atom.H2:         nil,
+	atom.H3:         nil,
+	atom.H4:         nil,
+	atom.H5:         nil,
+	atom.H6:         nil,
+	atom.Figure:     nil,
+	atom.Figcaption: nil,
+	atom.Table:      nil,
+	atom.Caption:    nil,
+	atom.Thead:      nil,
+	atom.Tbody:      nil,
+	atom.Tfoot:      nil,
+	atom.Tr:         nil,
+	atom.Th:         {"colspan", "rowspan"},
+	atom.Td:         {"colspan", "rowspan"},
+}
+
+// droppedTags — теги, которые удаляются вместе со всем содержимым.
+var droppedTags = map[atom.Atom]bool{
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Iframe:   true,
+	atom.Frame:    true,
+	atom.Frameset: true,
+	atom.Object:   true,
+	atom.Embed:    true,
+	atom.Applet:   true,
+	atom.Noscript: true,
+	atom.Template: true,
+	atom.Svg:      true,
+	atom.Math:     true,
+	atom.Form:     true,
+	atom.Button:   true,
+	atom.Select:   true,
+	atom.Textarea: true,
+	atom.Head:     true,
+	atom.Title:    true,
+	atom.Video:    true,
+	atom.Audio:    true,
+	atom.Canvas:   true,
+}
+
+// voidTags — теги без закрывающей пары.
+var voidTags = map[atom.Atom]bool{
+	atom.Br:  true,
+	atom.Hr:  true,
+	atom.Img: true,
+}
+
+// impliedEnd — теги, открытие которых неявно закрывает незакрытые однотипные теги.
+var impliedEnd = map[atom.Atom][]atom.Atom{
+	atom.P:  {atom.P},
+	atom.Li: {atom.Li},
+	atom.Dt: {atom.Dt, atom.Dd},
+	atom.Dd: {atom.Dt, atom.Dd},
+	atom.Tr: {atom.Td, atom.Th, atom.Tr},
+	atom.Td: {atom.Td, atom.Th},
+	atom.Th: {atom.Td, atom.Th},
+}
+
+// blockTags — теги, которые в текстовом представлении отделяются переводом строки.
+var blockTags = map[atom.Atom]bool{
+	atom.P:          true,
+	atom.Br:         true,
+	atom.Hr:         true,
+	atom.Div:        true,
+	atom.Blockquote: true,
+	atom.Pre:        true,
+	atom.Ul:         true,
+	atom.Ol:         true,
+	atom.Li:         true,
+	atom.Dl:         true,
+	atom.Dt:         true,
+	atom.Dd:         true,
+	atom.H1:         true,
+	atom.H2:         true,
+	atom.H3:         true,
+	atom.H4:         true,
+	atom.H5:         true,
+	atom.H6:         true,
+	atom.Figure:     true,
+	atom.Figcaption: true,
+	atom.Table:      true,
+	atom.Caption:    true,
+	atom.Tr:         true,
+}
+
+// urlAttrs — атрибуты, содержащие URL.
+var urlAttrs = map[string]bool{
+	"href": true,
+	"src":  true,
+	"cite": true,
+}
+
+// trackerHosts — домены известных счетчиков и трекинговых пикселей.
+var trackerHosts = []string{
+	"feeds.feedburner.com",
+	"feedsportal.com",
+	"pixel.wp.com",
+	"stats.wordpress.com",
+	"doubleclick.net",
+	"google-analytics.com",
+	"scorecardresearch.com",
+	"quantserve.com",
+	"pixel.quantserve.com",
+	"counter.yadro.ru",
+	"mc.yandex.ru",
+	"top-fwz1.mail.ru",
+}
+
+// trackerPathMarkers — фрагменты пути, характерные для трекинговых пикселей.
+var trackerPathMarkers = []string{
+	"/~r/",
+	"/pixel",
+	"/beacon",
+	"/tracking/",
+	"1x1.gif",
+	"spacer.gif",
+}
+
+// resolveURL приводит URL к абсолютному виду относительно base и проверяет схему.
+func resolveURL(raw string, base *url.URL, attr string) (string, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", false
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", false
+	}
+	if base != nil {
+		u = base.ResolveReference(u)
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https":
+		if u.Host == "" {
+			return "", false
+		}
+	case "mailto":
+		if attr != "href" {
+			return "", false
+		}
+	default:
+		return "", false
+	}
+	return u.String(), true
+}
+
+// isTrackingPixel определяет, является ли изображение трекинговым пикселем.
+func isTrackingPixel(src string, attrs map[string]string) bool {
+	for _, name := range []string{"width", "height"} {
+		if v, ok := attrs[name]; ok {
+			if n, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(v), "px")); err == nil && n <= 1 {
+				return true
+			}
+		}
+	}
+	style := strings.ToLower(strings.ReplaceAll(attrs["style"], " ", ""))
+	if strings.Contains(style, "display:none") || strings.Contains(style, "visibility:hidden") {
+		return true
+	}
+	u, err := url.Parse(src)
+	if err != nil {
+		return true
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, tracker := range trackerHosts {
+		if host == tracker || strings.HasSuffix(host, "."+tracker) {
+			return true
+		}
+	}
+	path := strings.ToLower(u.Path)
+	for _, marker := range trackerPathMarkers {
+		if strings.Contains(path, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// isNumeric проверяет, что значение атрибута состоит только из цифр.
+func isNumeric(v string) bool {
+	if v == "" {
+		return false
+	}
+	for _, r := range v {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,208 @@
+package sanitizer
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+	"newsservice/internal/domain"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+type Sanitizer struct {
+	log *slog.Logger
+}
+
+func New(log *slog.Logger) *Sanitizer {
+	return &Sanitizer{
+		log: log,
+	}
+}
+
+// Name возвращает имя этапа конвейера обработки.
+func (s *Sanitizer) Name() string {
+	return "sanitize"
+}
+
+// Process очищает описание и контент всех элементов фида и формирует их текстовое представление.
+func (s *Sanitizer) Process(ctx context.Context, feed *domain.Feed) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	for i := range feed.Items {
+		item := &feed.Items[i]
+		item.Description, item.DescriptionText = Sanitize(item.Description, item.Link)
+		item.Content, item.ContentText = Sanitize(item.Content, item.Link)
+	}
+	s.log.Debug("Feed items sanitized",
+		slog.String("feed", feed.Source),
+		slog.Int("items", len(feed.Items)),
+	)
+	return nil
+}
+
+// Sanitize очищает HTML по allow-list политике, переписывая относительные URL
+// относительно baseURL, и возвращает безопасный HTML и его текстовое представление.
+func Sanitize(raw, baseURL string) (string, string) {
+	if strings.TrimSpace(raw) == "" {
+		return "", ""
+	}
+	var base *url.URL
+	if u, err := url.Parse(baseURL); err == nil && u.IsAbs() {
+		base = u
+	}
+	clean := sanitizeHTML(raw, base)
+	return clean, PlainText(clean)
+}
+
+// sanitizeHTML проходит по токенам HTML и оставляет только разрешенные теги и атрибуты.
+func sanitizeHTML(raw string, base *url.URL) string {
+	var b strings.Builder
+	var open []atom.Atom
+	var skip atom.Atom
+	skipDepth := 0
+
+	z := html.NewTokenizer(strings.NewReader(raw))
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			for i := len(open) - 1; i >= 0; i-- {
+				b.WriteString("</" + open[i].String() + ">")
+			}
+			return strings.TrimSpace(b.String())
+		case html.TextToken:
+			if skipDepth > 0 {
+				continue
+			}
+			b.WriteString(html.EscapeString(string(z.Text())))
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tok := z.Token()
+			if skipDepth > 0 {
+				if tok.DataAtom == skip && tt == html.StartTagToken {
+					skipDepth++
+				}
+				continue
+			}
+			if droppedTags[tok.DataAtom] {
+				if tt == html.StartTagToken {
+					skip = tok.DataAtom
+					skipDepth = 1
+				}
+				continue
+			}
+			allowed, ok := allowedTags[tok.DataAtom]
+			if !ok {
+				continue
+			}
+			attrs, keep := filterAttrs(tok, allowed, base)
+			if !keep {
+				continue
+			}
+			for len(open) > 0 && slices.Contains(impliedEnd[tok.DataAtom], open[len(open)-1]) {
+				b.WriteString("</" + open[len(open)-1].String() + ">")
+				open = open[:len(open)-1]
+			}
+			b.WriteString("<" + tok.DataAtom.String() + attrs + ">")
+			if tt == html.StartTagToken && !voidTags[tok.DataAtom] {
+				open = append(open, tok.DataAtom)
+			}
+		case html.EndTagToken:
+			tok := z.Token()
+			if skipDepth > 0 {
+				if tok.DataAtom == skip {
+					skipDepth--
+				}
+				continue
+			}
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i] != tok.DataAtom {
+					continue
+				}
+				for j := len(open) - 1; j >= i; j-- {
+					b.WriteString("</" + open[j].String() + ">")
+				}
+				open = open[:i]
+				break
+			}
+		}
+	}
+}
+
+// filterAttrs оставляет разрешенные атрибуты тега. Второе значение false
+// означает, что тег должен быть удален целиком.
+func filterAttrs(tok html.Token, allowed []string, base *url.URL) (string, bool) {
+	all := make(map[string]string, len(tok.Attr))
+	for _, a := range tok.Attr {
+		all[strings.ToLower(a.Key)] = a.Val
+	}
+
+	var b strings.Builder
+	kept := make(map[string]string, len(allowed))
+	for _, name := range allowed {
+		val, ok := all[name]
+		if !ok {
+			continue
+		}
+		switch {
+		case urlAttrs[name]:
+			resolved, ok := resolveURL(val, base, name)
+			if !ok {
+				continue
+			}
+			val = resolved
+		case name == "width" || name == "height" || name == "colspan" || name == "rowspan":
+			if !isNumeric(val) {
+				continue
+			}
+		}
+		kept[name] = val
+		b.WriteString(" " + name + `="` + html.EscapeString(val) + `"`)
+	}
+
+	switch tok.DataAtom {
+	case atom.Img:
+		src, ok := kept["src"]
+		if !ok || isTrackingPixel(src, all) {
+			return "", false
+		}
+	case atom.A:
+		if _, ok := kept["href"]; ok {
+			b.WriteString(` rel="nofollow noopener noreferrer"`)
+		}
+	}
+	return b.String(), true
+}
+
+// PlainText строит текстовое представление HTML: блочные элементы
+// разделяются переводами строк, пробельные символы схлопываются.
+func PlainText(raw string) string {
+	var b strings.Builder
+	z := html.NewTokenizer(strings.NewReader(raw))
+	for tt := z.Next(); tt != html.ErrorToken; tt = z.Next() {
+		switch tt {
+		case html.TextToken:
+			b.Write(z.Text())
+		case html.StartTagToken, html.EndTagToken, html.SelfClosingTagToken:
+			name, _ := z.TagName()
+			switch a := atom.Lookup(name); {
+			case blockTags[a]:
+				b.WriteByte('\n')
+			case a == atom.Td || a == atom.Th:
+				b.WriteByte(' ')
+			}
+		}
+	}
+
+	lines := strings.Split(b.String(), "\n")
+	out := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			out = append(out, line)
+		}
+	}
+	return strings.Join(out, "\n")
+}
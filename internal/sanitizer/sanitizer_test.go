@@ -0,0 +1,88 @@
+package sanitizer
+
+import "testing"
+
+const baseLink = "https://example.com/news/2024/item.html"
+
+func TestSanitize(t *testing.T) {
+	cases := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{"script", `<p>a<script>alert(1)</script>b</p>`, `<p>ab</p>`},
+		{"script raw text", `<script>"<p>x</p>"</script>y</script><p>ok</p>`, `y<p>ok</p>`},
+		{"style", `<style>p{color:red}</style><p>text</p>`, `<p>text</p>`},
+		{"iframe", `<p>a</p><iframe src="https://evil.com/">fallback</iframe>`, `<p>a</p>`},
+		{"javascript href", `<a href="javascript:alert(1)">x</a>`, `<a>x</a>`},
+		{"javascript href case", `<a href=" JavaScript:alert(1)">x</a>`, `<a>x</a>`},
+		{"data href", `<a href="data:text/html;base64,PHNjcmlwdD4=">x</a>`, `<a>x</a>`},
+		{"data img", `<img src="data:image/png;base64,AAAA">`, ``},
+		{"event handlers", `<p onclick="x()">a <b onmouseover="y()">b</b></p>`, `<p>a <b>b</b></p>`},
+		{"style attribute", `<p style="position:fixed">a</p>`, `<p>a</p>`},
+		{"unknown tag kept text", `<div><span>text</span></div>`, `text`},
+		{"pixel by size", `<p>a<img src="https://example.com/p.gif" width="1" height="1"></p>`, `<p>a</p>`},
+		{"pixel by px size", `<img src="https://example.com/p.gif" width="1px">`, ``},
+		{"pixel hidden", `<img src="https://example.com/p.gif" style="display: none">`, ``},
+		{"pixel by host", `<img src="https://mc.yandex.ru/watch/1">`, ``},
+		{"pixel by path", `<img src="https://example.com/stats/1x1.gif">`, ``},
+		{"image kept", `<img src="https://example.com/photo.jpg" alt="Фото" width="640" height="abc">`,
+			`<img src="https://example.com/photo.jpg" alt="Фото" width="640">`},
+		{"relative href", `<a href="../other.html">x</a>`,
+			`<a href="https://example.com/news/other.html" rel="nofollow noopener noreferrer">x</a>`},
+		{"root relative src", `<img src="/img/a.jpg">`, `<img src="https://example.com/img/a.jpg">`},
+		{"protocol relative", `<a href="//cdn.example.org/x">x</a>`,
+			`<a href="https://cdn.example.org/x" rel="nofollow noopener noreferrer">x</a>`},
+		{"rel replaced", `<a href="https://a.com/" rel="opener" target="_blank">x</a>`,
+			`<a href="https://a.com/" rel="nofollow noopener noreferrer">x</a>`},
+		{"mailto href", `<a href="mailto:editor@example.com">mail</a>`,
+			`<a href="mailto:editor@example.com" rel="nofollow noopener noreferrer">mail</a>`},
+		{"unclosed tags", `<p><b>bold`, `<p><b>bold</b></p>`},
+		{"implied end", `<ul><li>a<li>b</ul>`, `<ul><li>a</li><li>b</li></ul>`},
+		{"text escaped", `a &lt;script&gt; b`, `a &lt;script&gt; b`},
+		{"attribute escaped", `<a href="https://a.com/?q=&quot;x&quot;" title='"><script>'>x</a>`,
+			`<a href="https://a.com/?q=&#34;x&#34;" title="&#34;&gt;&lt;script&gt;" rel="nofollow noopener noreferrer">x</a>`},
+		{"blank", " \n\t", ``},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, _ := Sanitize(c.raw, baseLink)
+			if got != c.want {
+				t.Fatalf("Sanitize(%q)\n got %q\nwant %q", c.raw, got, c.want)
+			}
+		})
+	}
+}
+
+func TestSanitizeWithoutBase(t *testing.T) {
+	got, _ := Sanitize(`<a href="/relative">x</a><img src="img.jpg">`, "not a url")
+	if want := `<a>x</a>`; got != want {
+		t.Fatalf("Sanitize() = %q, want %q", got, want)
+	}
+}
+
+func TestPlainText(t *testing.T) {
+	cases := []struct {
+		raw  string
+		want string
+	}{
+		{`<p>Первый   абзац</p><p>Второй<br>строка</p>`, "Первый абзац\nВторой\nстрока"},
+		{`<ul><li>one</li><li>two</li></ul>`, "one\ntwo"},
+		{`<table><tr><td>a</td><td>b</td></tr></table>`, "a b"},
+		{`<b>bold</b> and <i>italic</i>`, "bold and italic"},
+		{`a &amp; b &lt;c&gt;`, "a & b <c>"},
+		{"\n\n<p>  </p>\n", ""},
+	}
+	for _, c := range cases {
+		if got := PlainText(c.raw); got != c.want {
+			t.Fatalf("PlainText(%q) = %q, want %q", c.raw, got, c.want)
+		}
+	}
+}
+
+func TestSanitizeReturnsPlainText(t *testing.T) {
+	_, text := Sanitize(`<h2>Заголовок</h2><script>x</script><p>Текст <a href="/a">ссылки</a></p>`, baseLink)
+	if want := "Заголовок\nТекст ссылки"; text != want {
+		t.Fatalf("text = %q, want %q", text, want)
+	}
+}
@@ -0,0 +1,98 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"newsservice/internal/broker"
+	"newsservice/internal/dlq"
+	"newsservice/internal/events"
+	"time"
+)
+
+// DocumentSource загружает новости для индексации по идентификаторам.
+type DocumentSource interface {
+	GetNewsForIndex(ctx context.Context, ids ...int64) ([]Document, error)
+}
+
+// Indexer обновляет индекс по событиям news.created и news.updated.
+// Событие несет только сведения о новости, поэтому полный текст
+// загружается из хранилища.
+type Indexer struct {
+	consumer    broker.Subscriber
+	source      DocumentSource
+	searcher    Searcher
+	deadLetters *dlq.Processor
+	log         *slog.Logger
+}
+
+func NewIndexer(consumer broker.Subscriber, source DocumentSource, searcher Searcher, deadLetters *dlq.Processor, log *slog.Logger) *Indexer {
+	return &Indexer{
+		consumer:    consumer,
+		source:      source,
+		searcher:    searcher,
+		deadLetters: deadLetters,
+		log:         log.With(slog.String("component", "search-indexer")),
+	}
+}
+
+// Run индексирует новости из событий, пока не будет отменен ctx.
+func (ix *Indexer) Run(ctx context.Context) error {
+	ix.log.Info("Search indexer started")
+	for {
+		msg, err := ix.consumer.Receive(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				ix.log.Info("Search indexer stopped")
+				return nil
+			}
+			ix.log.Error("Failed to read news event", slog.Any("error", err))
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+		if err := ix.deadLetters.Process(ctx, msg, ix.handleMessage); err != nil {
+			ix.log.Error("News event lost", slog.Int64("offset", msg.Offset), slog.Any("error", err))
+		}
+	}
+}
+
+func (ix *Indexer) handleMessage(ctx context.Context, msg broker.Message) error {
+	return ix.Handle(ctx, msg.Value)
+}
+
+// Handle индексирует новость из одного события. Неизвестные типы событий
+// пропускаются; некорректное событие помечается dlq.Permanent.
+func (ix *Indexer) Handle(ctx context.Context, raw []byte) error {
+	var event events.NewsEvent
+	if err := json.Unmarshal(raw, &event); err != nil {
+		return dlq.Permanent(fmt.Errorf("malformed news event: %w", err))
+	}
+	if event.SchemaVersion != events.SchemaVersion {
+		return dlq.Permanent(fmt.Errorf("unsupported news event schema version %d", event.SchemaVersion))
+	}
+	if event.Type != events.TypeNewsCreated && event.Type != events.TypeNewsUpdated {
+		return nil
+	}
+	if event.News.ID <= 0 {
+		return dlq.Permanent(fmt.Errorf("news event %s has no news id", event.EventID))
+	}
+
+	docs, err := ix.source.GetNewsForIndex(ctx, event.News.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load news %d: %w", event.News.ID, err)
+	}
+	if len(docs) == 0 {
+		ix.log.Warn("Indexed news not found", slog.Int64("news_id", event.News.ID))
+		return nil
+	}
+	if err := ix.searcher.Index(ctx, docs...); err != nil {
+		return fmt.Errorf("failed to index news %d: %w", event.News.ID, err)
+	}
+	ix.log.Debug("News indexed", slog.Int64("news_id", event.News.ID), slog.String("type", event.Type))
+	return nil
+}
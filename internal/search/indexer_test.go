@@ -0,0 +1,112 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"newsservice/internal/broker"
+	"newsservice/internal/dlq"
+	"newsservice/internal/events"
+	"testing"
+	"time"
+)
+
+// fakeDocuments — хранилище документов для тестов индексатора.
+type fakeDocuments map[int64]Document
+
+func (f fakeDocuments) GetNewsForIndex(ctx context.Context, ids ...int64) ([]Document, error) {
+	var docs []Document
+	for _, id := range ids {
+		if doc, ok := f[id]; ok {
+			docs = append(docs, doc)
+		}
+	}
+	return docs, nil
+}
+
+func encodeEvent(t *testing.T, eventType string, version int, id int64) []byte {
+	t.Helper()
+	raw, err := json.Marshal(events.NewsEvent{SchemaVersion: version, Type: eventType, News: events.NewsPayload{ID: id}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+func TestIndexerHandle(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ix, err := OpenLocalIndex(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	docs := fakeDocuments{
+		1: {ID: 1, Source: "bbc", Title: "Oil prices climb", PublishedAt: day(1)},
+	}
+	indexer := NewIndexer(nil, docs, ix, nil, log)
+	ctx := context.Background()
+
+	if err := indexer.Handle(ctx, encodeEvent(t, events.TypeNewsCreated, events.SchemaVersion, 1)); err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(search(t, ix, "oil", Filter{})); len(got) != 1 || got[0] != 1 {
+		t.Fatalf("after create: hits = %v, want [1]", got)
+	}
+
+	docs[1] = Document{ID: 1, Source: "bbc", Title: "Gas prices fall", PublishedAt: day(1)}
+	if err := indexer.Handle(ctx, encodeEvent(t, events.TypeNewsUpdated, events.SchemaVersion, 1)); err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(search(t, ix, "oil", Filter{})); len(got) != 0 {
+		t.Fatalf("after update: oil hits = %v, want none", got)
+	}
+	if got := ids(search(t, ix, "gas", Filter{})); len(got) != 1 {
+		t.Fatalf("after update: gas hits = %v, want [1]", got)
+	}
+
+	if err := indexer.Handle(ctx, encodeEvent(t, "news.deleted", events.SchemaVersion, 1)); err != nil {
+		t.Errorf("unknown event type: %v, want skip", err)
+	}
+	for name, raw := range map[string][]byte{
+		"malformed": []byte(`{"type":`),
+		"version":   encodeEvent(t, events.TypeNewsCreated, 99, 1),
+		"no id":     encodeEvent(t, events.TypeNewsCreated, events.SchemaVersion, 0),
+	} {
+		if err := indexer.Handle(ctx, raw); !dlq.IsPermanent(err) {
+			t.Errorf("%s: err = %v, want permanent", name, err)
+		}
+	}
+}
+
+func TestIndexerMovesBadEventsToDeadLetter(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	mem := broker.NewMemory(2)
+	ix, err := OpenLocalIndex(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	deadLetters := dlq.NewProcessor("search-indexer", "dead", mem, 2, time.Millisecond, log)
+	indexer := NewIndexer(mem.Subscribe("news_events", "indexer"), fakeDocuments{}, ix, deadLetters, log)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		indexer.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	if err := mem.Publish(ctx, "news_events", nil, encodeEvent(t, events.TypeNewsCreated, 99, 1)); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for len(mem.Messages("dead")) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("bad event was not moved to the dead-letter topic")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,551 @@
+package search
+
+import (
+	"cmp"
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"math"
+	"newsservice/internal/models"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	localIndexVersion = 1
+	localIndexFile    = "index.gob"
+
+	// Параметры ранжирования BM25.
+	bm25K1 = 1.2
+	bm25B  = 0.75
+	// Вхождение слова в заголовок весит как titleBoost вхождений в текст.
+	titleBoost   = 2
+	phraseBoost  = 1.5
+	prefixWeight = 0.8
+	fuzzyWeight  = 0.5
+	defaultLimit = 20
+
+	defaultFlushInterval = 30 * time.Second
+)
+
+var _ Searcher = (*LocalIndex)(nil)
+
+// LocalIndex — инвертированный индекс в памяти процесса с сохранением на диск.
+// Изменения записываются на диск при Flush и Close; после сбоя индекс
+// восстанавливается командой reindex. Каталог индекса не должен
+// использоваться несколькими процессами одновременно.
+//
+// Для слов запроса, которых нет в словаре индекса, ищутся слова с одной-двумя
+// опечатками; префиксы и исключения опечатки не учитывают.
+type LocalIndex struct {
+	mu       sync.RWMutex
+	path     string
+	docs     map[int64]*indexedDoc
+	postings map[string]map[int64][]int32
+	totalLen int64
+	dirty    bool
+}
+
+// indexedDoc — документ и сведения, нужные для ранжирования и удаления.
+type indexedDoc struct {
+	Doc         Document
+	Length      int
+	TitleLength int
+	Terms       []string
+}
+
+// localSnapshot — содержимое файла индекса.
+type localSnapshot struct {
+	Version  int
+	Docs     map[int64]*indexedDoc
+	Postings map[string]map[int64][]int32
+}
+
+// OpenLocalIndex открывает индекс в каталоге dir, создавая пустой при отсутствии.
+func OpenLocalIndex(dir string) (*LocalIndex, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create search index directory: %w", err)
+	}
+	ix := &LocalIndex{
+		path:     filepath.Join(dir, localIndexFile),
+		docs:     make(map[int64]*indexedDoc),
+		postings: make(map[string]map[int64][]int32),
+	}
+	f, err := os.Open(ix.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return ix, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open search index: %w", err)
+	}
+	defer f.Close()
+
+	var snap localSnapshot
+	if err := gob.NewDecoder(f).Decode(&snap); err != nil {
+		return nil, fmt.Errorf("failed to read search index %s: %w", ix.path, err)
+	}
+	if snap.Version != localIndexVersion {
+		return nil, fmt.Errorf("search index version %d is not supported, run reindex", snap.Version)
+	}
+	if snap.Docs != nil {
+		ix.docs = snap.Docs
+	}
+	if snap.Postings != nil {
+		ix.postings = snap.Postings
+	}
+	for _, d := range ix.docs {
+		ix.totalLen += int64(d.Length)
+	}
+	return ix, nil
+}
+
+// RemoveLocalIndex удаляет файл индекса из каталога dir, в том числе
+// индекс неподдерживаемой версии.
+func RemoveLocalIndex(dir string) error {
+	err := os.Remove(filepath.Join(dir, localIndexFile))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to remove search index: %w", err)
+	}
+	return nil
+}
+
+// Index добавляет документы или заменяет ранее проиндексированные.
+func (ix *LocalIndex) Index(ctx context.Context, docs ...Document) error {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	for _, doc := range docs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		ix.remove(doc.ID)
+		ix.add(doc)
+	}
+	ix.dirty = ix.dirty || len(docs) > 0
+	return nil
+}
+
+// Reset удаляет все документы.
+func (ix *LocalIndex) Reset() {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.docs = make(map[int64]*indexedDoc)
+	ix.postings = make(map[string]map[int64][]int32)
+	ix.totalLen = 0
+	ix.dirty = true
+}
+
+// Len возвращает число документов в индексе.
+func (ix *LocalIndex) Len() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return len(ix.docs)
+}
+
+// Flush записывает индекс на диск, если он изменился.
+func (ix *LocalIndex) Flush() error {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	if !ix.dirty {
+		return nil
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(ix.path), localIndexFile+".*")
+	if err != nil {
+		return fmt.Errorf("failed to create search index file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	err = gob.NewEncoder(tmp).Encode(localSnapshot{
+		Version:  localIndexVersion,
+		Docs:     ix.docs,
+		Postings: ix.postings,
+	})
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write search index: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), ix.path); err != nil {
+		return fmt.Errorf("failed to replace search index: %w", err)
+	}
+	ix.dirty = false
+	return nil
+}
+
+// Close записывает несохраненные изменения.
+func (ix *LocalIndex) Close() error {
+	return ix.Flush()
+}
+
+// AutoFlush записывает изменения на диск каждые interval, пока не будет
+// отменен ctx.
+func (ix *LocalIndex) AutoFlush(ctx context.Context, interval time.Duration, log *slog.Logger) {
+	if interval <= 0 {
+		interval = defaultFlushInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := ix.Flush(); err != nil {
+				log.Error("Failed to flush search index", slog.String("path", ix.path), slog.Any("error", err))
+			}
+		}
+	}
+}
+
+// add индексирует документ. Вызывается под ix.mu.
+func (ix *LocalIndex) add(doc Document) {
+	title := tokenize(doc.Title)
+	text := tokenize(doc.Text)
+	d := &indexedDoc{
+		Doc:         doc,
+		TitleLength: len(title),
+		// Позиция между заголовком и текстом пропускается, чтобы фраза не
+		// склеивалась из конца заголовка и начала текста.
+		Length: len(title) + 1 + len(text),
+	}
+	seen := make(map[string]bool)
+	put := func(word string, pos int) {
+		list, ok := ix.postings[word]
+		if !ok {
+			list = make(map[int64][]int32)
+			ix.postings[word] = list
+		}
+		list[doc.ID] = append(list[doc.ID], int32(pos))
+		if !seen[word] {
+			seen[word] = true
+			d.Terms = append(d.Terms, word)
+		}
+	}
+	for i, t := range title {
+		put(t.word, i)
+	}
+	for i, t := range text {
+		put(t.word, len(title)+1+i)
+	}
+	ix.docs[doc.ID] = d
+	ix.totalLen += int64(d.Length)
+}
+
+// remove удаляет документ из индекса. Вызывается под ix.mu.
+func (ix *LocalIndex) remove(id int64) {
+	d, ok := ix.docs[id]
+	if !ok {
+		return
+	}
+	for _, word := range d.Terms {
+		delete(ix.postings[word], id)
+		if len(ix.postings[word]) == 0 {
+			delete(ix.postings, word)
+		}
+	}
+	ix.totalLen -= int64(d.Length)
+	delete(ix.docs, id)
+}
+
+// Search ищет документы по запросу, упорядочивая их по BM25.
+func (ix *LocalIndex) Search(ctx context.Context, req Request) (Result, error) {
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	matched := make(map[string]bool)
+	scores := make(map[int64]float64)
+	for _, g := range req.Query.Groups {
+		for id, s := range ix.matchGroup(g, matched) {
+			scores[id] += s
+		}
+	}
+
+	type scored struct {
+		doc   *indexedDoc
+		score float64
+	}
+	var hits []scored
+	var facets *facetCounter
+	if req.Facets {
+		facets = newFacetCounter()
+	}
+	for id, s := range scores {
+		d := ix.docs[id]
+		if !req.Filter.match(d.Doc) {
+			continue
+		}
+		hits = append(hits, scored{doc: d, score: s})
+		facets.add(d.Doc)
+	}
+	slices.SortFunc(hits, func(a, b scored) int {
+		if c := cmp.Compare(b.score, a.score); c != 0 {
+			return c
+		}
+		if c := b.doc.Doc.PublishedAt.Compare(a.doc.Doc.PublishedAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.doc.Doc.ID, a.doc.Doc.ID)
+	})
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+	from := min(max(req.Offset, 0), len(hits))
+	to := min(from+limit, len(hits))
+
+	result := Result{Total: len(hits), Hits: make([]models.SearchResult, 0, to-from), Facets: facets.result()}
+	for _, h := range hits[from:to] {
+		doc := h.doc.Doc
+		result.Hits = append(result.Hits, models.SearchResult{
+			NewsID:         int(doc.ID),
+			Title:          doc.Title,
+			TitleHighlight: highlight(doc.Title, matched, 0),
+			Snippet:        highlight(doc.Text, matched, snippetWords),
+			Link:           doc.Link,
+			Source:         doc.Source,
+			Language:       doc.Language,
+			Category:       doc.Category,
+			PublishedAt:    doc.PublishedAt,
+			Rank:           h.score,
+		})
+	}
+	return result, nil
+}
+
+// matchGroup возвращает документы, совпавшие со всеми словами группы и не
+// содержащие исключенных. Найденные слова индекса добавляются в matched.
+func (ix *LocalIndex) matchGroup(g Group, matched map[string]bool) map[int64]float64 {
+	var acc map[int64]float64
+	for _, t := range g.Terms {
+		if t.Negated {
+			continue
+		}
+		m := ix.matchTerm(t, matched)
+		if acc == nil {
+			acc = m
+			continue
+		}
+		for id := range acc {
+			if s, ok := m[id]; ok {
+				acc[id] += s
+			} else {
+				delete(acc, id)
+			}
+		}
+	}
+	for _, t := range g.Terms {
+		if !t.Negated || len(acc) == 0 {
+			continue
+		}
+		for id := range ix.matchTerm(t, nil) {
+			delete(acc, id)
+		}
+	}
+	return acc
+}
+
+// matchTerm возвращает документы, содержащие слово или фразу, с их оценкой.
+func (ix *LocalIndex) matchTerm(t Term, matched map[string]bool) map[int64]float64 {
+	last := len(t.Words) - 1
+	// Опечатки исправляются только в отдельных словах, не в исключениях.
+	lastTerms := ix.expand(t.Words[last], t.Prefix, !t.Phrase() && !t.Negated)
+	if !t.Phrase() {
+		scores := make(map[int64]float64)
+		for term, weight := range lastTerms {
+			for id, positions := range ix.postings[term] {
+				scores[id] = max(scores[id], weight*ix.score(term, id, positions))
+			}
+			if matched != nil {
+				matched[term] = true
+			}
+		}
+		return scores
+	}
+
+	for _, w := range t.Words[:last] {
+		if _, ok := ix.postings[w]; !ok {
+			return nil
+		}
+	}
+	scores := make(map[int64]float64)
+	for id, first := range ix.postings[t.Words[0]] {
+		for term, weight := range lastTerms {
+			tail, ok := ix.postings[term][id]
+			if !ok || !ix.hasPhrase(id, first, t.Words[1:last], tail) {
+				continue
+			}
+			s := weight * ix.score(term, id, tail)
+			for _, w := range t.Words[:last] {
+				s += ix.score(w, id, ix.postings[w][id])
+			}
+			scores[id] = max(scores[id], phraseBoost*s)
+			if matched != nil {
+				matched[term] = true
+			}
+		}
+	}
+	if matched != nil && len(scores) > 0 {
+		for _, w := range t.Words[:last] {
+			matched[w] = true
+		}
+	}
+	return scores
+}
+
+// hasPhrase проверяет, что в документе есть слова middle и позиция из tail
+// сразу после позиции из first.
+func (ix *LocalIndex) hasPhrase(id int64, first []int32, middle []string, tail []int32) bool {
+	for _, start := range first {
+		pos := start
+		ok := true
+		for _, w := range middle {
+			pos++
+			if _, found := slices.BinarySearch(ix.postings[w][id], pos); !found {
+				ok = false
+				break
+			}
+		}
+		if !ok {
+			continue
+		}
+		if _, found := slices.BinarySearch(tail, pos+1); found {
+			return true
+		}
+	}
+	return false
+}
+
+// expand возвращает слова индекса, соответствующие слову запроса, с весами.
+func (ix *LocalIndex) expand(word string, prefix, fuzzy bool) map[string]float64 {
+	terms := make(map[string]float64)
+	if _, ok := ix.postings[word]; ok {
+		terms[word] = 1
+	}
+	switch {
+	case prefix:
+		for term := range ix.postings {
+			if term != word && strings.HasPrefix(term, word) {
+				terms[term] = prefixWeight
+			}
+		}
+	case fuzzy && len(terms) == 0:
+		edits := maxEdits(word)
+		if edits == 0 {
+			break
+		}
+		for term := range ix.postings {
+			if d := editDistance(word, term, edits); d <= edits {
+				terms[term] = fuzzyWeight / float64(d)
+			}
+		}
+	}
+	return terms
+}
+
+// score вычисляет BM25 слова term для документа id.
+func (ix *LocalIndex) score(term string, id int64, positions []int32) float64 {
+	d := ix.docs[id]
+	tf := 0.0
+	for _, p := range positions {
+		if int(p) < d.TitleLength {
+			tf += titleBoost
+		} else {
+			tf++
+		}
+	}
+	n := float64(len(ix.docs))
+	df := float64(len(ix.postings[term]))
+	idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+	avg := float64(ix.totalLen) / n
+	return idf * tf * (bm25K1 + 1) / (tf + bm25K1*(1-bm25B+bm25B*float64(d.Length)/avg))
+}
+
+// match проверяет документ на соответствие фильтру.
+func (f Filter) match(doc Document) bool {
+	switch {
+	case f.Source != "" && doc.Source != f.Source,
+		f.Category != "" && doc.Category != f.Category,
+		f.Language != "" && doc.Language != f.Language,
+		f.Author != "" && doc.Author != f.Author,
+		!f.From.IsZero() && doc.PublishedAt.Before(f.From),
+		!f.To.IsZero() && !doc.PublishedAt.Before(f.To):
+		return false
+	}
+	return true
+}
+
+// facetCounter подсчитывает фасеты; nil-счетчик ничего не считает.
+type facetCounter struct {
+	sources, categories, languages, dates map[string]int
+}
+
+func newFacetCounter() *facetCounter {
+	return &facetCounter{
+		sources:    make(map[string]int),
+		categories: make(map[string]int),
+		languages:  make(map[string]int),
+		dates:      make(map[string]int),
+	}
+}
+
+func (c *facetCounter) add(doc Document) {
+	if c == nil {
+		return
+	}
+	if doc.Source != "" {
+		c.sources[doc.Source]++
+	}
+	if doc.Category != "" {
+		c.categories[doc.Category]++
+	}
+	if doc.Language != "" {
+		c.languages[doc.Language]++
+	}
+	c.dates[doc.PublishedAt.UTC().Format("2006-01-02")]++
+}
+
+func (c *facetCounter) result() *Facets {
+	if c == nil {
+		return nil
+	}
+	facets := &Facets{
+		Sources:    topValues(c.sources),
+		Categories: topValues(c.categories),
+		Languages:  topValues(c.languages),
+		Dates:      []DateBucket{},
+	}
+	for _, date := range slices.Sorted(maps.Keys(c.dates)) {
+		facets.Dates = append(facets.Dates, DateBucket{Date: date, Count: c.dates[date]})
+	}
+	return facets
+}
+
+// topValues возвращает MaxFacetValues самых частых значений.
+func topValues(counts map[string]int) []FacetValue {
+	values := make([]FacetValue, 0, len(counts))
+	for v, n := range counts {
+		values = append(values, FacetValue{Value: v, Count: n})
+	}
+	slices.SortFunc(values, func(a, b FacetValue) int {
+		if c := cmp.Compare(b.Count, a.Count); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Value, b.Value)
+	})
+	if len(values) > MaxFacetValues {
+		values = values[:MaxFacetValues]
+	}
+	return values
+}
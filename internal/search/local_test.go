@@ -0,0 +1,172 @@
+package search
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func day(d int) time.Time {
+	return time.Date(2025, 3, d, 12, 0, 0, 0, time.UTC)
+}
+
+func testIndex(t *testing.T) *LocalIndex {
+	t.Helper()
+	ix, err := OpenLocalIndex(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ix.Index(context.Background(),
+		Document{ID: 1, Source: "bbc", Language: "en", Category: "economy", PublishedAt: day(1),
+			Title: "Central bank raises interest rates", Text: "The central bank raised rates again to fight inflation."},
+		Document{ID: 2, Source: "reuters", Language: "en", Category: "economy", PublishedAt: day(2),
+			Title: "Oil prices climb", Text: "Brent crude rose as the central committee met. Banking shares fell."},
+		Document{ID: 3, Source: "bbc", Language: "en", Category: "sport", PublishedAt: day(2),
+			Title: "Football final", Text: "The bank holiday final drew record crowds."},
+		Document{ID: 4, Source: "lenta", Language: "ru", Category: "economy", PublishedAt: day(3),
+			Title: "Центральный банк повысил ставку", Text: "Банк России повысил ключевую ставку."},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ix
+}
+
+func search(t *testing.T, ix *LocalIndex, text string, filter Filter) Result {
+	t.Helper()
+	q, err := ParseQuery(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := ix.Search(context.Background(), Request{Query: q, Filter: filter, Facets: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func ids(res Result) []int {
+	out := make([]int, 0, len(res.Hits))
+	for _, h := range res.Hits {
+		out = append(out, h.NewsID)
+	}
+	return out
+}
+
+func equal(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestLocalIndexQueries(t *testing.T) {
+	ix := testIndex(t)
+	cases := []struct {
+		query string
+		want  []int
+	}{
+		{`bank`, []int{1, 3}},
+		{`"central bank"`, []int{1}},
+		{`bank*`, []int{1, 2, 3}},
+		{`bank -football`, []int{1}},
+		{`crude OR holiday`, []int{3, 2}},
+		{`"central bank" -"interest rates"`, nil},
+		{`банк ставку`, []int{4}},
+		// Опечатки исправляются для слов, которых нет в индексе.
+		{`inflaton`, []int{1}},
+		{`centrall bnk`, nil},
+	}
+	for _, c := range cases {
+		res := search(t, ix, c.query, Filter{})
+		if got := ids(res); !equal(got, c.want) {
+			t.Errorf("%s: got %v, want %v", c.query, got, c.want)
+		}
+		if res.Total != len(c.want) {
+			t.Errorf("%s: total = %d, want %d", c.query, res.Total, len(c.want))
+		}
+	}
+}
+
+func TestLocalIndexFiltersAndFacets(t *testing.T) {
+	ix := testIndex(t)
+
+	res := search(t, ix, `bank*`, Filter{Source: "bbc"})
+	if got := ids(res); !equal(got, []int{1, 3}) {
+		t.Fatalf("source filter: got %v", got)
+	}
+	res = search(t, ix, `bank*`, Filter{From: day(2), To: day(3)})
+	if got := ids(res); !equal(got, []int{2, 3}) {
+		t.Fatalf("date filter: got %v", got)
+	}
+
+	res = search(t, ix, `bank* OR банк`, Filter{})
+	f := res.Facets
+	if f == nil {
+		t.Fatal("facets not computed")
+	}
+	if len(f.Sources) != 3 || f.Sources[0] != (FacetValue{Value: "bbc", Count: 2}) {
+		t.Errorf("sources = %+v", f.Sources)
+	}
+	if len(f.Categories) != 2 || f.Categories[0] != (FacetValue{Value: "economy", Count: 3}) {
+		t.Errorf("categories = %+v", f.Categories)
+	}
+	if len(f.Languages) != 2 || f.Languages[0] != (FacetValue{Value: "en", Count: 3}) {
+		t.Errorf("languages = %+v", f.Languages)
+	}
+	wantDates := []DateBucket{{"2025-03-01", 1}, {"2025-03-02", 2}, {"2025-03-03", 1}}
+	if len(f.Dates) != len(wantDates) {
+		t.Fatalf("dates = %+v", f.Dates)
+	}
+	for i := range wantDates {
+		if f.Dates[i] != wantDates[i] {
+			t.Errorf("dates = %+v, want %+v", f.Dates, wantDates)
+		}
+	}
+}
+
+func TestLocalIndexHighlights(t *testing.T) {
+	ix := testIndex(t)
+	res := search(t, ix, `"central bank"`, Filter{})
+	hit := res.Hits[0]
+	if hit.TitleHighlight != "<mark>Central</mark> <mark>bank</mark> raises interest rates" {
+		t.Errorf("title highlight = %q", hit.TitleHighlight)
+	}
+	if !strings.Contains(hit.Snippet, "<mark>central</mark> <mark>bank</mark>") {
+		t.Errorf("snippet = %q", hit.Snippet)
+	}
+}
+
+func TestLocalIndexUpdateAndPersistence(t *testing.T) {
+	dir := t.TempDir()
+	ix, err := OpenLocalIndex(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	ix.Index(ctx, Document{ID: 1, Title: "Old headline", PublishedAt: day(1)})
+	ix.Index(ctx, Document{ID: 1, Title: "New headline", PublishedAt: day(1)})
+	if got := ids(search(t, ix, `old`, Filter{})); len(got) != 0 {
+		t.Fatalf("replaced document still matches: %v", got)
+	}
+	if err := ix.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := OpenLocalIndex(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reopened.Len() != 1 {
+		t.Fatalf("reopened index has %d documents, want 1", reopened.Len())
+	}
+	if got := ids(search(t, reopened, `new`, Filter{})); !equal(got, []int{1}) {
+		t.Fatalf("reopened index: got %v", got)
+	}
+}
@@ -0,0 +1,151 @@
+// Package search описывает язык поисковых запросов по новостям.
+//
+// Запрос состоит из слов, которые должны встречаться все сразу. Поддерживаются:
+//
+//	"центральный банк"  — фраза, слова идут подряд;
+//	эконом*             — префикс;
+//	-спорт              — исключение слова или фразы;
+//	нефть OR газ        — любая из групп слов.
+package search
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// MaxTerms ограничивает число слов и фраз в запросе.
+const MaxTerms = 32
+
+// ErrInvalidQuery возвращается для запросов, которые нельзя выполнить.
+var ErrInvalidQuery = errors.New("invalid search query")
+
+// Query — разобранный запрос: совпадение хотя бы с одной из групп.
+type Query struct {
+	Groups []Group
+}
+
+// Group — слова и фразы, которые должны совпасть одновременно.
+type Group struct {
+	Terms []Term
+}
+
+// Term — слово или фраза. Prefix относится к последнему слову.
+type Term struct {
+	Words   []string
+	Prefix  bool
+	Negated bool
+}
+
+// Phrase сообщает, состоит ли терм из нескольких слов.
+func (t Term) Phrase() bool {
+	return len(t.Words) > 1
+}
+
+// ParseQuery разбирает текст запроса. Слова приводятся к нижнему регистру,
+// знаки препинания внутри слова разбивают его на фразу ("covid-19").
+func ParseQuery(text string) (Query, error) {
+	var q Query
+	var group Group
+	terms := 0
+
+	closeGroup := func() error {
+		if len(group.Terms) == 0 {
+			return nil
+		}
+		positive := false
+		for _, t := range group.Terms {
+			if !t.Negated {
+				positive = true
+			}
+		}
+		if !positive {
+			return fmt.Errorf("%w: query cannot consist of exclusions only", ErrInvalidQuery)
+		}
+		q.Groups = append(q.Groups, group)
+		group = Group{}
+		return nil
+	}
+
+	rest := strings.TrimSpace(text)
+	for rest != "" {
+		var token string
+		quoted := false
+		negated := false
+		if rest[0] == '-' {
+			negated = true
+			rest = rest[1:]
+		}
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				token, rest = rest[1:], ""
+			} else {
+				token, rest = rest[1:end+1], rest[end+2:]
+			}
+			quoted = true
+		} else {
+			end := strings.IndexFunc(rest, unicode.IsSpace)
+			if end < 0 {
+				end = len(rest)
+			}
+			token, rest = rest[:end], rest[end:]
+		}
+		rest = strings.TrimLeftFunc(rest, unicode.IsSpace)
+
+		if !quoted && !negated && (token == "OR" || token == "|") {
+			if err := closeGroup(); err != nil {
+				return Query{}, err
+			}
+			continue
+		}
+
+		prefix := false
+		if !quoted && strings.HasSuffix(token, "*") {
+			prefix = true
+			token = strings.TrimRight(token, "*")
+		}
+		words := strings.FieldsFunc(strings.ToLower(token), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		if len(words) == 0 {
+			continue
+		}
+		terms++
+		if terms > MaxTerms {
+			return Query{}, fmt.Errorf("%w: too many terms, max %d", ErrInvalidQuery, MaxTerms)
+		}
+		group.Terms = append(group.Terms, Term{Words: words, Prefix: prefix, Negated: negated})
+	}
+	if err := closeGroup(); err != nil {
+		return Query{}, err
+	}
+	if len(q.Groups) == 0 {
+		return Query{}, fmt.Errorf("%w: query has no words", ErrInvalidQuery)
+	}
+	return q, nil
+}
+
+// String возвращает запрос в каноническом виде.
+func (q Query) String() string {
+	groups := make([]string, 0, len(q.Groups))
+	for _, g := range q.Groups {
+		terms := make([]string, 0, len(g.Terms))
+		for _, t := range g.Terms {
+			s := strings.Join(t.Words, " ")
+			if t.Phrase() {
+				s = `"` + s + `"`
+			}
+			if t.Prefix {
+				s += "*"
+			}
+			if t.Negated {
+				s = "-" + s
+			}
+			terms = append(terms, s)
+		}
+		groups = append(groups, strings.Join(terms, " "))
+	}
+	return strings.Join(groups, " OR ")
+}
@@ -0,0 +1,47 @@
+package search
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseQuery(t *testing.T) {
+	cases := []struct {
+		text string
+		want string
+	}{
+		{`Нефть газ*`, `нефть газ*`},
+		{`"Central Bank" -sport OR oil`, `"central bank" -sport OR oil`},
+		{`covid-19 -"fake news"`, `"covid 19" -"fake news"`},
+		{`"unterminated phrase`, `"unterminated phrase"`},
+		{`a | b`, `a OR b`},
+	}
+	for _, c := range cases {
+		q, err := ParseQuery(c.text)
+		if err != nil {
+			t.Fatalf("ParseQuery(%q): %v", c.text, err)
+		}
+		if got := q.String(); got != c.want {
+			t.Fatalf("ParseQuery(%q) = %q, want %q", c.text, got, c.want)
+		}
+	}
+}
+
+func TestParseQueryTerms(t *testing.T) {
+	q, err := ParseQuery(`"central bank"* -rate`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	terms := q.Groups[0].Terms
+	if len(terms) != 2 || !terms[0].Phrase() || terms[0].Prefix || !terms[1].Negated {
+		t.Fatalf("terms = %+v", terms)
+	}
+}
+
+func TestParseQueryRejects(t *testing.T) {
+	for _, text := range []string{``, `OR`, `***`, `-sport`, `oil OR -sport`} {
+		if _, err := ParseQuery(text); !errors.Is(err, ErrInvalidQuery) {
+			t.Fatalf("ParseQuery(%q) error = %v, want ErrInvalidQuery", text, err)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package search
+
+import (
+	"context"
+	"newsservice/internal/models"
+	"time"
+)
+
+// Document — новость в поисковом индексе.
+type Document struct {
+	ID          int64
+	Source      string
+	Title       string
+	Text        string
+	Link        string
+	Author      string
+	Language    string
+	Category    string
+	PublishedAt time.Time
+}
+
+// Filter ограничивает результаты поиска. Пустые поля не учитываются,
+// From включительно, To исключительно.
+type Filter struct {
+	Source   string
+	Category string
+	Language string
+	Author   string
+	From     time.Time
+	To       time.Time
+}
+
+// Request — поисковый запрос. Facets включает подсчет фасетов по всем
+// найденным новостям, а не только по странице.
+type Request struct {
+	Query  Query
+	Filter Filter
+	Limit  int
+	Offset int
+	Facets bool
+}
+
+// Result — страница результатов и общее число найденных новостей.
+type Result struct {
+	Hits   []models.SearchResult
+	Total  int
+	Facets *Facets
+}
+
+// Facets — распределение найденных новостей по значениям полей.
+type Facets struct {
+	Sources    []FacetValue `json:"sources"`
+	Categories []FacetValue `json:"categories"`
+	Languages  []FacetValue `json:"languages"`
+	Dates      []DateBucket `json:"dates"`
+}
+
+// FacetValue — значение поля и число новостей с ним.
+type FacetValue struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// DateBucket — число новостей, опубликованных за день (UTC).
+type DateBucket struct {
+	Date  string `json:"date"`
+	Count int    `json:"count"`
+}
+
+// MaxFacetValues ограничивает число значений в фасетах источников, категорий и языков.
+const MaxFacetValues = 20
+
+// Searcher — поисковый движок по новостям. Index добавляет или заменяет
+// документы; движки, которые индексируют данные сами, могут его игнорировать.
+type Searcher interface {
+	Search(ctx context.Context, req Request) (Result, error)
+	Index(ctx context.Context, docs ...Document) error
+}
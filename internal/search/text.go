@@ -0,0 +1,125 @@
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// token — слово текста и его байтовые границы в исходной строке.
+type token struct {
+	word  string
+	start int
+	end   int
+}
+
+// tokenize разбивает текст на слова из букв и цифр в нижнем регистре, по тем
+// же правилам, что и ParseQuery.
+func tokenize(text string) []token {
+	var tokens []token
+	start := -1
+	for i, r := range text {
+		wordRune := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if wordRune && start < 0 {
+			start = i
+		}
+		if !wordRune && start >= 0 {
+			tokens = append(tokens, token{word: strings.ToLower(text[start:i]), start: start, end: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, token{word: strings.ToLower(text[start:]), start: start, end: len(text)})
+	}
+	return tokens
+}
+
+// maxEdits возвращает допустимое число опечаток в слове.
+func maxEdits(word string) int {
+	switch n := utf8.RuneCountInString(word); {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// editDistance возвращает расстояние Левенштейна между a и b или max+1,
+// если оно больше max.
+func editDistance(a, b string, max int) int {
+	ra, rb := []rune(a), []rune(b)
+	if d := len(ra) - len(rb); d > max || -d > max {
+		return max + 1
+	}
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		rowMin := cur[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			rowMin = min(rowMin, cur[j])
+		}
+		if rowMin > max {
+			return max + 1
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+const (
+	snippetWords  = 30
+	snippetBefore = 8
+)
+
+// highlight экранирует текст и выделяет тегами <mark> слова из matched.
+// При window > 0 возвращается фрагмент из window слов вокруг первого совпадения.
+func highlight(text string, matched map[string]bool, window int) string {
+	tokens := tokenize(text)
+	from, to := 0, len(tokens)
+	if window > 0 && len(tokens) > window {
+		first := 0
+		for i, t := range tokens {
+			if matched[t.word] {
+				first = i
+				break
+			}
+		}
+		from = max(0, first-snippetBefore)
+		to = min(len(tokens), from+window)
+		from = max(0, to-window)
+	}
+
+	var b strings.Builder
+	pos := 0
+	if from > 0 {
+		b.WriteString("… ")
+		pos = tokens[from].start
+	}
+	for _, t := range tokens[from:to] {
+		b.WriteString(html.EscapeString(text[pos:t.start]))
+		if matched[t.word] {
+			b.WriteString("<mark>" + html.EscapeString(text[t.start:t.end]) + "</mark>")
+		} else {
+			b.WriteString(html.EscapeString(text[t.start:t.end]))
+		}
+		pos = t.end
+	}
+	if to < len(tokens) {
+		b.WriteString(" …")
+	} else {
+		b.WriteString(html.EscapeString(text[pos:]))
+	}
+	return strings.TrimSpace(b.String())
+}
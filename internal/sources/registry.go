@@ -0,0 +1,104 @@
+// Package sources ведет список RSS-фидов, которые обрабатывает сервис:
+// фиды из конфигурации и фиды, подключенные или отключенные во время работы.
+package sources
+
+import (
+	"context"
+	"fmt"
+	"newsservice/internal/domain"
+	"newsservice/internal/infrastructure/config"
+	"newsservice/storage"
+	"strings"
+)
+
+// Registry объединяет фиды из конфигурации с записями реестра в БД.
+// Запись в БД имеет приоритет: отключенный фид из конфигурации не обрабатывается.
+type Registry struct {
+	static []domain.Source
+	store  storage.SourceStorage
+}
+
+func NewRegistry(feeds []config.FeedURL, store storage.SourceStorage) *Registry {
+	static := make([]domain.Source, 0, len(feeds))
+	for _, feed := range feeds {
+		static = append(static, domain.Source{URL: feed.URL, Name: feed.Name, Active: true})
+	}
+	return &Registry{static: static, store: store}
+}
+
+// Active возвращает фиды, которые нужно обрабатывать.
+func (r *Registry) Active(ctx context.Context) ([]domain.Source, error) {
+	all, err := r.All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	active := all[:0]
+	for _, src := range all {
+		if src.Active {
+			active = append(active, src)
+		}
+	}
+	return active, nil
+}
+
+// All возвращает все известные фиды, включая отключенные.
+func (r *Registry) All(ctx context.Context) ([]domain.Source, error) {
+	stored, err := r.store.ListSources(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byURL := make(map[string]domain.Source, len(stored))
+	for _, src := range stored {
+		byURL[src.URL] = src
+	}
+
+	result := make([]domain.Source, 0, len(r.static)+len(stored))
+	for _, src := range r.static {
+		if override, ok := byURL[src.URL]; ok {
+			if override.Name == "" {
+				override.Name = src.Name
+			}
+			src = override
+			delete(byURL, src.URL)
+		}
+		result = append(result, src)
+	}
+	for _, src := range stored {
+		if _, ok := byURL[src.URL]; ok {
+			result = append(result, src)
+		}
+	}
+	return result, nil
+}
+
+// Get возвращает фид по URL; storage.ErrNotFound, если он неизвестен.
+func (r *Registry) Get(ctx context.Context, url string) (domain.Source, error) {
+	all, err := r.All(ctx)
+	if err != nil {
+		return domain.Source{}, err
+	}
+	for _, src := range all {
+		if src.URL == url {
+			return src, nil
+		}
+	}
+	return domain.Source{}, fmt.Errorf("source %s: %w", url, storage.ErrNotFound)
+}
+
+// Subscribe подключает фид и возвращает его актуальное состояние.
+func (r *Registry) Subscribe(ctx context.Context, url, name string) (domain.Source, error) {
+	url = strings.TrimSpace(url)
+	if err := r.store.SaveSource(ctx, domain.Source{URL: url, Name: strings.TrimSpace(name), Active: true}); err != nil {
+		return domain.Source{}, err
+	}
+	return r.Get(ctx, url)
+}
+
+// Unsubscribe отключает фид; сохраненные новости фида остаются.
+func (r *Registry) Unsubscribe(ctx context.Context, url string) error {
+	url = strings.TrimSpace(url)
+	if _, err := r.Get(ctx, url); err != nil {
+		return err
+	}
+	return r.store.SaveSource(ctx, domain.Source{URL: url, Active: false})
+}
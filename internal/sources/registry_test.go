@@ -0,0 +1,114 @@
+package sources
+
+import (
+	"context"
+	"errors"
+	"newsservice/internal/domain"
+	"newsservice/internal/infrastructure/config"
+	"newsservice/storage"
+	"slices"
+	"testing"
+)
+
+// memoryStore — реестр источников в памяти с сохранением порядка записи.
+type memoryStore struct {
+	sources []domain.Source
+}
+
+func (m *memoryStore) ListSources(ctx context.Context) ([]domain.Source, error) {
+	return slices.Clone(m.sources), nil
+}
+
+func (m *memoryStore) SaveSource(ctx context.Context, source domain.Source) error {
+	for i, src := range m.sources {
+		if src.URL == source.URL {
+			if source.Name == "" {
+				source.Name = src.Name
+			}
+			m.sources[i] = source
+			return nil
+		}
+	}
+	m.sources = append(m.sources, source)
+	return nil
+}
+
+func urls(list []domain.Source) []string {
+	out := make([]string, 0, len(list))
+	for _, src := range list {
+		out = append(out, src.URL)
+	}
+	return out
+}
+
+func TestRegistry(t *testing.T) {
+	ctx := context.Background()
+	store := &memoryStore{}
+	r := NewRegistry([]config.FeedURL{
+		{Name: "bbc", URL: "https://bbc.com/rss"},
+		{Name: "ria", URL: "https://ria.ru/rss"},
+	}, store)
+
+	all, err := r.All(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := urls(all); !slices.Equal(got, []string{"https://bbc.com/rss", "https://ria.ru/rss"}) {
+		t.Fatalf("All() = %v, want configured feeds", got)
+	}
+
+	src, err := r.Subscribe(ctx, " https://example.com/rss ", " example ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if src.URL != "https://example.com/rss" || src.Name != "example" || !src.Active {
+		t.Fatalf("Subscribe() = %+v", src)
+	}
+	if err := r.Unsubscribe(ctx, "https://bbc.com/rss"); err != nil {
+		t.Fatal(err)
+	}
+
+	all, err = r.All(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := urls(all); !slices.Equal(got, []string{"https://bbc.com/rss", "https://ria.ru/rss", "https://example.com/rss"}) {
+		t.Fatalf("All() = %v, want configured feeds before subscribed ones", got)
+	}
+	if all[0].Active || all[0].Name != "bbc" {
+		t.Fatalf("unsubscribed configured feed = %+v, want inactive with its configured name", all[0])
+	}
+	active, err := r.Active(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := urls(active); !slices.Equal(got, []string{"https://ria.ru/rss", "https://example.com/rss"}) {
+		t.Fatalf("Active() = %v", got)
+	}
+
+	// Повторная подписка возвращает фид из конфигурации в работу.
+	if src, err := r.Subscribe(ctx, "https://bbc.com/rss", ""); err != nil || !src.Active || src.Name != "bbc" {
+		t.Fatalf("Subscribe(bbc) = %+v, %v", src, err)
+	}
+	if err := r.Unsubscribe(ctx, "https://example.com/rss"); err != nil {
+		t.Fatal(err)
+	}
+	if src, err := r.Get(ctx, "https://example.com/rss"); err != nil || src.Active || src.Name != "example" {
+		t.Fatalf("Get(example) = %+v, %v", src, err)
+	}
+}
+
+func TestRegistryUnknownSource(t *testing.T) {
+	ctx := context.Background()
+	store := &memoryStore{}
+	r := NewRegistry(nil, store)
+	if _, err := r.Get(ctx, "https://unknown.com/rss"); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("Get() error = %v, want ErrNotFound", err)
+	}
+	if err := r.Unsubscribe(ctx, "https://unknown.com/rss"); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("Unsubscribe() error = %v, want ErrNotFound", err)
+	}
+	if len(store.sources) != 0 {
+		t.Fatalf("stored sources = %+v, want none", store.sources)
+	}
+}
@@ -0,0 +1,98 @@
+package summarizer
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// abbreviations — сокращения, после точки в которых предложение не заканчивается.
+var abbreviations = map[string]map[string]bool{
+	"en": {
+		"mr": true, "mrs": true, "ms": true, "dr": true, "prof": true, "sr": true, "jr": true,
+		"st": true, "vs": true, "etc": true, "e.g": true, "i.e": true, "u.s": true, "u.k": true,
+		"no": true, "gen": true, "gov": true, "sen": true, "rep": true, "inc": true, "ltd": true,
+		"co": true, "corp": true, "jan": true, "feb": true, "mar": true, "apr": true, "aug": true,
+		"sep": true, "sept": true, "oct": true, "nov": true, "dec": true,
+	},
+	"ru": {
+		"т.е": true, "т.д": true, "т.п": true, "т.к": true, "т.н": true, "г": true, "гг": true,
+		"им": true, "ул": true, "пр": true, "др": true, "н.э": true, "млн": true, "млрд": true,
+		"тыс": true, "руб": true, "коп": true, "см": true, "стр": true, "проф": true, "акад": true,
+		"обл": true, "р-н": true, "с.г": true,
+	},
+}
+
+// splitSentences разбивает текст на предложения с учетом сокращений языка
+// и инициалов ("В. Путин", "J. Smith"). Перевод строки всегда завершает
+// предложение, поэтому заголовки и абзацы без знаков препинания не склеиваются.
+func splitSentences(text, lang string) []string {
+	abbr := abbreviations[lang]
+	var sentences []string
+	for _, line := range strings.Split(text, "\n") {
+		sentences = splitLine(sentences, line, abbr)
+	}
+	return sentences
+}
+
+// splitLine добавляет к sentences предложения одной строки текста.
+func splitLine(sentences []string, line string, abbr map[string]bool) []string {
+	runes := []rune(strings.Join(strings.Fields(line), " "))
+	start := 0
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if r != '.' && r != '!' && r != '?' && r != '…' {
+			continue
+		}
+		end := i + 1
+		for end < len(runes) && strings.ContainsRune(".!?…\"»”)", runes[end]) {
+			end++
+		}
+		if end < len(runes) && !unicode.IsSpace(runes[end]) {
+			continue
+		}
+		next := end
+		for next < len(runes) && unicode.IsSpace(runes[next]) {
+			next++
+		}
+		if next < len(runes) && !startsSentence(runes[next]) {
+			continue
+		}
+		if r == '.' && isAbbreviation(runes[start:i], abbr) {
+			continue
+		}
+		if s := strings.TrimSpace(string(runes[start:end])); s != "" {
+			sentences = append(sentences, s)
+		}
+		start = next
+		i = next - 1
+	}
+	if s := strings.TrimSpace(string(runes[start:])); s != "" {
+		sentences = append(sentences, s)
+	}
+	return sentences
+}
+
+// startsSentence проверяет, может ли символ начинать новое предложение.
+func startsSentence(r rune) bool {
+	return unicode.IsUpper(r) || unicode.IsDigit(r) || strings.ContainsRune("\"«“(—-", r)
+}
+
+// isAbbreviation проверяет, является ли последнее слово перед точкой сокращением или инициалом.
+func isAbbreviation(before []rune, abbr map[string]bool) bool {
+	i := len(before)
+	for i > 0 && !unicode.IsSpace(before[i-1]) {
+		i--
+	}
+	word := strings.Trim(string(before[i:]), "\"«(")
+	if word == "" {
+		return false
+	}
+	if utf8.RuneCountInString(word) == 1 {
+		first, _ := utf8.DecodeRuneInString(word)
+		if unicode.IsUpper(first) {
+			return true
+		}
+	}
+	return abbr[strings.ToLower(word)]
+}
@@ -0,0 +1,39 @@
+package summarizer
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSplitSentences(t *testing.T) {
+	cases := []struct {
+		name string
+		text string
+		lang string
+		want []string
+	}{
+		{"punctuation", "Первое предложение. Второе! Третье?", "ru",
+			[]string{"Первое предложение.", "Второе!", "Третье?"}},
+		{"paragraph breaks", "Заголовок без точки\nТекст первого абзаца. Еще одно\n\nПоследний абзац", "ru",
+			[]string{"Заголовок без точки", "Текст первого абзаца.", "Еще одно", "Последний абзац"}},
+		{"line with extra spaces", "  Строка  с   пробелами \n", "ru", []string{"Строка с пробелами"}},
+		{"abbreviations", "Выплаты составили 5 млн. руб. в этом году. Это рекорд.", "ru",
+			[]string{"Выплаты составили 5 млн. руб. в этом году.", "Это рекорд."}},
+		{"initials", "Заявление сделал В. Путин. Его поддержали.", "ru",
+			[]string{"Заявление сделал В. Путин.", "Его поддержали."}},
+		{"english abbreviations", "Dr. Smith met Mr. Jones in the U.S. today. They talked.", "en",
+			[]string{"Dr. Smith met Mr. Jones in the U.S. today.", "They talked."}},
+		{"closing quotes", "Он сказал: «Хватит!» Затем ушел.", "ru",
+			[]string{"Он сказал: «Хватит!»", "Затем ушел."}},
+		{"lowercase continuation", "Версия 2.5 вышла. в ней исправлены ошибки.", "ru",
+			[]string{"Версия 2.5 вышла. в ней исправлены ошибки."}},
+		{"empty", " \n\n ", "ru", nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := splitSentences(c.text, c.lang); !slices.Equal(got, c.want) {
+				t.Fatalf("splitSentences(%q) = %q, want %q", c.text, got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package summarizer
+
+import (
+	"context"
+	"html"
+	"log/slog"
+	"newsservice/internal/domain"
+	"newsservice/internal/infrastructure/config"
+	"strings"
+)
+
+const (
+	defaultMaxSentences        = 3
+	defaultMinDescriptionWords = 8
+)
+
+type Summarizer struct {
+	log                 *slog.Logger
+	maxSentences        int
+	minDescriptionWords int
+}
+
+func New(cfg config.SummaryConfig, log *slog.Logger) *Summarizer {
+	s := &Summarizer{
+		log:                 log,
+		maxSentences:        cfg.MaxSentences,
+		minDescriptionWords: cfg.MinDescriptionWords,
+	}
+	if s.maxSentences <= 0 {
+		s.maxSentences = defaultMaxSentences
+	}
+	if s.minDescriptionWords <= 0 {
+		s.minDescriptionWords = defaultMinDescriptionWords
+	}
+	return s
+}
+
+// Name возвращает имя этапа конвейера обработки.
+func (s *Summarizer) Name() string {
+	return "summarize"
+}
+
+// Process формирует описание из текста статьи для элементов, у которых
+// описание отсутствует или слишком короткое.
+func (s *Summarizer) Process(ctx context.Context, feed *domain.Feed) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	generated := 0
+	for i := range feed.Items {
+		item := &feed.Items[i]
+		if len(strings.Fields(item.DescriptionText)) >= s.minDescriptionWords {
+			continue
+		}
+		summary := s.Summarize(item.ContentText, item.Language)
+		if summary == "" || len(strings.Fields(summary)) <= len(strings.Fields(item.DescriptionText)) {
+			continue
+		}
+		item.Description = "<p>" + html.EscapeString(summary) + "</p>"
+		item.DescriptionText = summary
+		item.DescriptionGenerated = true
+		generated++
+	}
+	if generated > 0 {
+		s.log.Debug("Descriptions generated",
+			slog.String("feed", feed.Source),
+			slog.Int("items", generated),
+		)
+	}
+	return nil
+}
+
+// Summarize составляет краткое изложение текста из наиболее значимых предложений.
+func (s *Summarizer) Summarize(text, lang string) string {
+	sentences := splitSentences(text, lang)
+	if len(sentences) == 0 {
+		return ""
+	}
+	picked := rankSentences(sentences, s.maxSentences)
+	out := make([]string, len(picked))
+	for i, idx := range picked {
+		out[i] = sentences[idx]
+	}
+	return strings.Join(out, " ")
+}
@@ -0,0 +1,106 @@
+package summarizer
+
+import (
+	"math"
+	"newsservice/internal/textutil"
+	"sort"
+	"strings"
+)
+
+const (
+	// damping — коэффициент затухания PageRank.
+	damping = 0.85
+	// maxIterations — ограничение числа итераций PageRank.
+	maxIterations = 50
+	// tolerance — порог сходимости PageRank.
+	tolerance = 1e-4
+)
+
+// rankSentences возвращает индексы limit самых значимых предложений
+// (TextRank) в порядке их следования в тексте.
+func rankSentences(sentences []string, limit int) []int {
+	n := len(sentences)
+	if n <= limit {
+		out := make([]int, n)
+		for i := range out {
+			out[i] = i
+		}
+		return out
+	}
+
+	words := make([]map[string]bool, n)
+	for i, s := range sentences {
+		words[i] = make(map[string]bool)
+		for _, t := range textutil.Tokenize(s) {
+			if lower := strings.ToLower(t.Surface); !textutil.IsStopword(lower) && !textutil.IsNumeric(lower) {
+				words[i][t.Stem] = true
+			}
+		}
+	}
+
+	weights := make([][]float64, n)
+	outSum := make([]float64, n)
+	for i := range weights {
+		weights[i] = make([]float64, n)
+	}
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			w := similarity(words[i], words[j])
+			weights[i][j], weights[j][i] = w, w
+			outSum[i] += w
+			outSum[j] += w
+		}
+	}
+
+	scores := make([]float64, n)
+	for i := range scores {
+		scores[i] = 1
+	}
+	for iter := 0; iter < maxIterations; iter++ {
+		next := make([]float64, n)
+		delta := 0.0
+		for i := 0; i < n; i++ {
+			sum := 0.0
+			for j := 0; j < n; j++ {
+				if weights[j][i] > 0 && outSum[j] > 0 {
+					sum += weights[j][i] / outSum[j] * scores[j]
+				}
+			}
+			next[i] = (1 - damping) + damping*sum
+			delta += math.Abs(next[i] - scores[i])
+		}
+		scores = next
+		if delta < tolerance {
+			break
+		}
+	}
+
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return scores[order[a]] > scores[order[b]]
+	})
+	top := order[:limit]
+	sort.Ints(top)
+	return top
+}
+
+// similarity — мера сходства предложений из оригинальной статьи TextRank:
+// число общих слов, нормированное на логарифмы длин предложений.
+func similarity(a, b map[string]bool) float64 {
+	if len(a) < 2 || len(b) < 2 {
+		return 0
+	}
+	common := 0
+	for w := range a {
+		if b[w] {
+			common++
+		}
+	}
+	if common == 0 {
+		return 0
+	}
+	return float64(common) / (math.Log(float64(len(a))) + math.Log(float64(len(b))))
+}
@@ -0,0 +1,207 @@
+package tagging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"newsservice/internal/domain"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unicode"
+)
+
+// RuleSource — источник правил категоризации (файл конфигурации или БД).
+type RuleSource interface {
+	LoadRules(ctx context.Context) (RuleSet, error)
+}
+
+// Document — данные новости, по которым подбираются категория и теги.
+type Document struct {
+	Source         string
+	Language       string
+	Title          string
+	Text           string
+	Link           string
+	FeedCategories []string
+}
+
+// Result — категория и теги, назначенные новости.
+type Result struct {
+	Category string
+	Tags     []string
+}
+
+type Engine struct {
+	source RuleSource
+	log    *slog.Logger
+	rules  atomic.Pointer[compiledRuleSet]
+}
+
+func NewEngine(source RuleSource, log *slog.Logger) *Engine {
+	e := &Engine{
+		source: source,
+		log:    log,
+	}
+	e.rules.Store(&compiledRuleSet{sources: map[string]SourceDefault{}})
+	return e
+}
+
+// Reload загружает правила из источника и атомарно подменяет действующий набор.
+// При ошибке продолжает действовать прежний набор правил.
+func (e *Engine) Reload(ctx context.Context) error {
+	set, err := e.source.LoadRules(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load tagging rules: %w", err)
+	}
+	compiled, err := compile(set)
+	if err != nil {
+		return fmt.Errorf("invalid tagging rules: %w", err)
+	}
+	e.rules.Store(compiled)
+	e.log.Info("Tagging rules reloaded",
+		slog.Int("rules", len(compiled.rules)),
+		slog.Int("source_defaults", len(compiled.sources)),
+	)
+	return nil
+}
+
+// Watch периодически перезагружает правила до отмены контекста.
+func (e *Engine) Watch(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := e.Reload(ctx); err != nil {
+				e.log.Error("Tagging rules reload failed", slog.Any("error", err))
+			}
+		}
+	}
+}
+
+// Name возвращает имя этапа конвейера обработки.
+func (e *Engine) Name() string {
+	return "tagging"
+}
+
+// Process назначает категорию и теги всем элементам фида.
+func (e *Engine) Process(ctx context.Context, feed *domain.Feed) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	for i := range feed.Items {
+		item := &feed.Items[i]
+		text := item.DescriptionText
+		if item.ContentText != "" {
+			text += "\n" + item.ContentText
+		}
+		result := e.Classify(Document{
+			Source:         feed.Source,
+			Language:       item.Language,
+			Title:          item.Title,
+			Text:           text,
+			Link:           item.Link,
+			FeedCategories: item.Categories,
+		})
+		item.Category = result.Category
+		item.Tags = result.Tags
+	}
+	return nil
+}
+
+// Classify подбирает категорию и теги документа. Категорию назначает первое
+// совпавшее правило, теги собираются со всех совпавших правил и умолчаний источника.
+func (e *Engine) Classify(doc Document) Result {
+	set := e.rules.Load()
+	var result Result
+	seen := make(map[string]bool)
+	addTags := func(tags []string) {
+		for _, t := range tags {
+			if !seen[t] {
+				seen[t] = true
+				result.Tags = append(result.Tags, t)
+			}
+		}
+	}
+
+	words, text, original := prepareText(doc.Title + "\n" + doc.Text)
+	path := doc.Link
+	if u, err := url.Parse(doc.Link); err == nil {
+		path = u.Path
+	}
+
+	for _, rule := range set.rules {
+		if !rule.matches(doc, words, text, original, path) {
+			continue
+		}
+		if result.Category == "" {
+			result.Category = rule.category
+		}
+		addTags(rule.tags)
+	}
+
+	if d, ok := set.sources[doc.Source]; ok {
+		if result.Category == "" {
+			result.Category = d.Category
+		}
+		addTags(d.Tags)
+	}
+	return result
+}
+
+// matches проверяет ограничения правила и условия совпадения.
+func (r *compiledRule) matches(doc Document, words map[string]bool, text, original, path string) bool {
+	if len(r.sources) > 0 && !r.sources[doc.Source] {
+		return false
+	}
+	for _, c := range doc.FeedCategories {
+		if r.feedCategories[strings.ToLower(strings.TrimSpace(c))] {
+			return true
+		}
+	}
+	for _, lang := range []string{doc.Language, AnyLanguage} {
+		for w := range r.keywords[lang] {
+			if words[w] {
+				return true
+			}
+		}
+		for _, phrase := range r.phrases[lang] {
+			if strings.Contains(text, phrase) {
+				return true
+			}
+		}
+		for _, re := range r.patterns[lang] {
+			if re.MatchString(original) {
+				return true
+			}
+		}
+	}
+	for _, re := range r.urlPatterns {
+		if re.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// prepareText возвращает множество слов текста в нижнем регистре, текст
+// в нижнем регистре и исходный текст с нормализованными пробелами.
+func prepareText(raw string) (map[string]bool, string, string) {
+	original := strings.Join(strings.Fields(raw), " ")
+	lower := strings.ToLower(original)
+	words := strings.FieldsFunc(lower, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	set := make(map[string]bool, len(words))
+	for _, w := range words {
+		set[w] = true
+	}
+	return set, lower, original
+}
@@ -0,0 +1,168 @@
+package tagging
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// AnyLanguage — ключ словаря, правила которого применяются к новостям на любом языке.
+const AnyLanguage = "*"
+
+// RuleSet — полный набор правил категоризации.
+type RuleSet struct {
+	Sources []SourceDefault `yaml:"sources" json:"sources"`
+	Rules   []Rule          `yaml:"rules" json:"rules"`
+}
+
+// SourceDefault — категория и теги, назначаемые всем новостям источника.
+type SourceDefault struct {
+	Source   string   `yaml:"source" json:"source"`
+	Category string   `yaml:"category" json:"category"`
+	Tags     []string `yaml:"tags" json:"tags"`
+}
+
+// Rule — правило, назначающее категорию и теги при совпадении хотя бы одного условия.
+// Keywords и Patterns задаются по языкам; ключ "*" действует для всех языков.
+// Keywords сравниваются без учета регистра, а Patterns применяются к исходному
+// тексту: для поиска без учета регистра выражение начинается с (?i). \b в
+// выражениях учитывает только ASCII-буквы, поэтому границы кириллических слов
+// задаются явно, например (?:^|[^\p{L}\p{N}]).
+type Rule struct {
+	Name           string              `yaml:"name" json:"name"`
+	Category       string              `yaml:"category" json:"category"`
+	Tags           []string            `yaml:"tags" json:"tags"`
+	Sources        []string            `yaml:"sources" json:"sources"`
+	FeedCategories []string            `yaml:"feed_categories" json:"feed_categories"`
+	Keywords       map[string][]string `yaml:"keywords" json:"keywords"`
+	Patterns       map[string][]string `yaml:"patterns" json:"patterns"`
+	URLPatterns    []string            `yaml:"url_patterns" json:"url_patterns"`
+}
+
+// compiledRule — правило с подготовленными для сопоставления словарями.
+type compiledRule struct {
+	name           string
+	category       string
+	tags           []string
+	sources        map[string]bool
+	feedCategories map[string]bool
+	keywords       map[string]map[string]bool
+	phrases        map[string][]string
+	patterns       map[string][]*regexp.Regexp
+	urlPatterns    []*regexp.Regexp
+}
+
+// compiledRuleSet — набор правил, готовый к применению.
+type compiledRuleSet struct {
+	sources map[string]SourceDefault
+	rules   []compiledRule
+}
+
+// compile проверяет правила и компилирует регулярные выражения.
+func compile(set RuleSet) (*compiledRuleSet, error) {
+	out := &compiledRuleSet{
+		sources: make(map[string]SourceDefault, len(set.Sources)),
+		rules:   make([]compiledRule, 0, len(set.Rules)),
+	}
+	for _, d := range set.Sources {
+		if d.Source == "" {
+			return nil, fmt.Errorf("source default without source name")
+		}
+		d.Category = NormalizeSlug(d.Category)
+		d.Tags = normalizeTags(d.Tags)
+		out.sources[d.Source] = d
+	}
+
+	for i, r := range set.Rules {
+		name := r.Name
+		if name == "" {
+			name = fmt.Sprintf("rule #%d", i+1)
+		}
+		if r.Category == "" && len(r.Tags) == 0 {
+			return nil, fmt.Errorf("%s assigns neither category nor tags", name)
+		}
+		c := compiledRule{
+			name:           name,
+			category:       NormalizeSlug(r.Category),
+			tags:           normalizeTags(r.Tags),
+			sources:        toSet(r.Sources, false),
+			feedCategories: toSet(r.FeedCategories, true),
+			keywords:       make(map[string]map[string]bool),
+			phrases:        make(map[string][]string),
+			patterns:       make(map[string][]*regexp.Regexp),
+		}
+		for lang, words := range r.Keywords {
+			for _, w := range words {
+				w = strings.ToLower(strings.TrimSpace(w))
+				if w == "" {
+					continue
+				}
+				if strings.ContainsRune(w, ' ') {
+					c.phrases[lang] = append(c.phrases[lang], w)
+					continue
+				}
+				if c.keywords[lang] == nil {
+					c.keywords[lang] = make(map[string]bool)
+				}
+				c.keywords[lang][w] = true
+			}
+		}
+		for lang, exprs := range r.Patterns {
+			for _, expr := range exprs {
+				re, err := regexp.Compile(expr)
+				if err != nil {
+					return nil, fmt.Errorf("%s: invalid pattern %q: %w", name, expr, err)
+				}
+				c.patterns[lang] = append(c.patterns[lang], re)
+			}
+		}
+		for _, expr := range r.URLPatterns {
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				return nil, fmt.Errorf("%s: invalid url pattern %q: %w", name, expr, err)
+			}
+			c.urlPatterns = append(c.urlPatterns, re)
+		}
+		if len(c.feedCategories) == 0 && len(c.keywords) == 0 && len(c.phrases) == 0 &&
+			len(c.patterns) == 0 && len(c.urlPatterns) == 0 {
+			return nil, fmt.Errorf("%s has no match conditions", name)
+		}
+		out.rules = append(out.rules, c)
+	}
+	return out, nil
+}
+
+// NormalizeSlug приводит название категории или тега к виду slug.
+func NormalizeSlug(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	return strings.Join(strings.Fields(s), "-")
+}
+
+// normalizeTags нормализует теги и удаляет повторы.
+func normalizeTags(tags []string) []string {
+	out := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, t := range tags {
+		t = NormalizeSlug(t)
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		out = append(out, t)
+	}
+	return out
+}
+
+func toSet(values []string, lower bool) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if lower {
+			v = strings.ToLower(v)
+		}
+		if v != "" {
+			set[v] = true
+		}
+	}
+	return set
+}
@@ -0,0 +1,77 @@
+package tagging
+
+import (
+	"context"
+	"fmt"
+	"newsservice/internal/infrastructure/config"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileSource загружает правила из YAML-файла.
+type FileSource struct {
+	path string
+}
+
+func NewFileSource(path string) *FileSource {
+	return &FileSource{path: path}
+}
+
+func (s *FileSource) LoadRules(ctx context.Context) (RuleSet, error) {
+	raw, err := os.ReadFile(s.path)
+	if err != nil {
+		return RuleSet{}, fmt.Errorf("failed to read rules file: %w", err)
+	}
+	return ParseRules(raw)
+}
+
+// RulesStore — хранилище, в котором лежит актуальный документ с правилами.
+type RulesStore interface {
+	GetTaggingRules(ctx context.Context) ([]byte, error)
+}
+
+// DBSource загружает правила из БД.
+type DBSource struct {
+	store RulesStore
+}
+
+func NewDBSource(store RulesStore) *DBSource {
+	return &DBSource{store: store}
+}
+
+func (s *DBSource) LoadRules(ctx context.Context) (RuleSet, error) {
+	raw, err := s.store.GetTaggingRules(ctx)
+	if err != nil {
+		return RuleSet{}, err
+	}
+	return ParseRules(raw)
+}
+
+// ParseRules разбирает документ с правилами в формате YAML или JSON.
+func ParseRules(raw []byte) (RuleSet, error) {
+	var set RuleSet
+	if err := yaml.Unmarshal(raw, &set); err != nil {
+		return RuleSet{}, fmt.Errorf("failed to parse rules: %w", err)
+	}
+	return set, nil
+}
+
+// NewSource выбирает источник правил согласно конфигурации. store может быть
+// nil, если хранилище не поддерживает правила в БД.
+func NewSource(cfg config.TaggingConfig, store RulesStore) (RuleSource, error) {
+	switch cfg.RulesSource {
+	case "", "file":
+		if cfg.RulesPath == "" {
+			return nil, fmt.Errorf("tagging rules path is empty")
+		}
+		return NewFileSource(cfg.RulesPath), nil
+	case "db":
+		if store == nil {
+			return nil, fmt.Errorf("tagging rules source db is not supported by the storage")
+		}
+		return NewDBSource(store), nil
+	default:
+		return nil, fmt.Errorf("unknown tagging rules source %q", cfg.RulesSource)
+	}
+}
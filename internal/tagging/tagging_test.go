@@ -0,0 +1,252 @@
+package tagging
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"newsservice/internal/domain"
+	"newsservice/internal/infrastructure/config"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// staticSource — источник правил, содержимое которого можно подменить в тесте.
+type staticSource struct {
+	set RuleSet
+	err error
+}
+
+func (s *staticSource) LoadRules(ctx context.Context) (RuleSet, error) {
+	return s.set, s.err
+}
+
+// fakeStore — хранилище правил в БД для тестов DBSource.
+type fakeStore struct {
+	raw []byte
+	err error
+}
+
+func (f *fakeStore) GetTaggingRules(ctx context.Context) ([]byte, error) {
+	return f.raw, f.err
+}
+
+func newTestEngine(t *testing.T, source RuleSource) *Engine {
+	t.Helper()
+	e := NewEngine(source, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err := e.Reload(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+func TestClassify(t *testing.T) {
+	e := newTestEngine(t, &staticSource{set: RuleSet{
+		Sources: []SourceDefault{{Source: "dev.to", Category: "Technology", Tags: []string{"Programming"}}},
+		Rules: []Rule{
+			{Name: "sport", Category: "sport", Tags: []string{"sport"}, FeedCategories: []string{"Sports"}, URLPatterns: []string{"^/sport"}},
+			{Name: "economy", Category: "economy", Tags: []string{"economy"}, Keywords: map[string][]string{"ru": {"Инфляция"}}},
+			{Name: "ai", Tags: []string{"ai", "tech"}, Keywords: map[string][]string{"en": {"machine learning"}, AnyLanguage: {"chatgpt"}}},
+			{Name: "lenta only", Category: "russia", Sources: []string{"lenta"}, Keywords: map[string][]string{AnyLanguage: {"москва"}}},
+		},
+	}})
+
+	tests := []struct {
+		name string
+		doc  Document
+		want Result
+	}{
+		{"feed category", Document{Source: "bbc", FeedCategories: []string{" SPORTS "}},
+			Result{Category: "sport", Tags: []string{"sport"}}},
+		{"url pattern", Document{Source: "bbc", Link: "https://bbc.com/sport/football/1"},
+			Result{Category: "sport", Tags: []string{"sport"}}},
+		{"keyword of the document language", Document{Language: "ru", Title: "ИНФЛЯЦИЯ замедлилась"},
+			Result{Category: "economy", Tags: []string{"economy"}}},
+		{"keyword of another language", Document{Language: "en", Title: "Инфляция замедлилась"},
+			Result{}},
+		{"keyword is a whole word", Document{Language: "ru", Title: "Антиинфляция"},
+			Result{}},
+		{"phrase", Document{Language: "en", Text: "Advances in  Machine\nLearning"},
+			Result{Tags: []string{"ai", "tech"}}},
+		{"first category wins and tags are merged", Document{Language: "ru", Title: "Инфляция и ChatGPT", FeedCategories: []string{"sports"}},
+			Result{Category: "sport", Tags: []string{"sport", "economy", "ai", "tech"}}},
+		{"source restriction", Document{Source: "ria", Title: "Москва"},
+			Result{}},
+		{"allowed source", Document{Source: "lenta", Title: "Москва"},
+			Result{Category: "russia"}},
+		{"source default", Document{Source: "dev.to", Title: "ChatGPT"},
+			Result{Category: "technology", Tags: []string{"ai", "tech", "programming"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := e.Classify(tt.doc)
+			if got.Category != tt.want.Category || !slices.Equal(got.Tags, tt.want.Tags) {
+				t.Fatalf("Classify() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigRules(t *testing.T) {
+	e := newTestEngine(t, NewFileSource(filepath.Join("..", "..", "configs", "rules.yaml")))
+
+	tests := []struct {
+		language string
+		title    string
+		want     string
+	}{
+		{"ru", "ЦБ сохранил ключевую ставку", "economy"},
+		{"ru", "Решение ЦБ по ставке", "economy"},
+		{"ru", "Заседание совета директоров (ЦБ).", "economy"},
+		{"ru", "ЦБР опубликовал отчет", ""},
+		{"en", "Fed holds rates steady", "economy"},
+		{"en", "ECB raises rates", "economy"},
+		{"en", "Fans fed up with the referee", ""},
+		{"en", "FedEx reports record profit", ""},
+	}
+	for _, tt := range tests {
+		got := e.Classify(Document{Language: tt.language, Title: tt.title})
+		if got.Category != tt.want {
+			t.Errorf("Classify(%q) category = %q, want %q", tt.title, got.Category, tt.want)
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		set  RuleSet
+		want string
+	}{
+		{"source without name", RuleSet{Sources: []SourceDefault{{Category: "world"}}}, "without source name"},
+		{"nothing assigned", RuleSet{Rules: []Rule{{Name: "empty", Keywords: map[string][]string{"en": {"oil"}}}}}, "assigns neither"},
+		{"no conditions", RuleSet{Rules: []Rule{{Category: "world", Keywords: map[string][]string{"en": {" "}}}}}, "rule #1 has no match conditions"},
+		{"invalid pattern", RuleSet{Rules: []Rule{{Name: "bad", Category: "world", Patterns: map[string][]string{AnyLanguage: {"("}}}}}, "invalid pattern"},
+		{"invalid url pattern", RuleSet{Rules: []Rule{{Name: "bad", Category: "world", URLPatterns: []string{"["}}}}, "invalid url pattern"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := compile(tt.set)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("compile() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeSlug(t *testing.T) {
+	if got := NormalizeSlug("  Science &  Technology "); got != "science-&-technology" {
+		t.Fatalf("NormalizeSlug() = %q", got)
+	}
+	if got := normalizeTags([]string{"AI", "ai", " ", "Machine Learning"}); !slices.Equal(got, []string{"ai", "machine-learning"}) {
+		t.Fatalf("normalizeTags() = %v", got)
+	}
+}
+
+func TestReloadKeepsRulesOnError(t *testing.T) {
+	source := &staticSource{set: RuleSet{Rules: []Rule{
+		{Name: "oil", Category: "economy", Keywords: map[string][]string{AnyLanguage: {"oil"}}},
+	}}}
+	e := newTestEngine(t, source)
+	doc := Document{Title: "Oil prices"}
+	if got := e.Classify(doc).Category; got != "economy" {
+		t.Fatalf("category = %q, want economy", got)
+	}
+
+	source.set = RuleSet{Rules: []Rule{{Name: "broken", Category: "world"}}}
+	if err := e.Reload(context.Background()); err == nil {
+		t.Fatal("Reload() accepted a rule without conditions")
+	}
+	source.set, source.err = RuleSet{}, errors.New("unavailable")
+	if err := e.Reload(context.Background()); err == nil {
+		t.Fatal("Reload() ignored the source error")
+	}
+	if got := e.Classify(doc).Category; got != "economy" {
+		t.Fatalf("category after failed reloads = %q, want economy", got)
+	}
+
+	source.set, source.err = RuleSet{Rules: []Rule{
+		{Name: "oil", Category: "energy", Keywords: map[string][]string{AnyLanguage: {"oil"}}},
+	}}, nil
+	if err := e.Reload(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := e.Classify(doc).Category; got != "energy" {
+		t.Fatalf("category after reload = %q, want energy", got)
+	}
+}
+
+func TestProcess(t *testing.T) {
+	e := newTestEngine(t, &staticSource{set: RuleSet{Rules: []Rule{
+		{Name: "oil", Category: "economy", Tags: []string{"oil"}, Keywords: map[string][]string{"en": {"brent"}}},
+	}}})
+	feed := &domain.Feed{Source: "bbc", Items: []domain.Item{
+		{Title: "Prices", Language: "en", ContentText: "Brent fell"},
+		{Title: "Football", Language: "en"},
+	}}
+	if err := e.Process(context.Background(), feed); err != nil {
+		t.Fatal(err)
+	}
+	if item := feed.Items[0]; item.Category != "economy" || !slices.Equal(item.Tags, []string{"oil"}) {
+		t.Fatalf("item 0 = %q %v, want economy [oil]", item.Category, item.Tags)
+	}
+	if item := feed.Items[1]; item.Category != "" || len(item.Tags) != 0 {
+		t.Fatalf("item 1 = %q %v, want no category", item.Category, item.Tags)
+	}
+}
+
+func TestSources(t *testing.T) {
+	const doc = "rules:\n  - name: oil\n    category: economy\n    keywords:\n      en: [oil]\n"
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	if err := os.WriteFile(path, []byte(doc), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	store := &fakeStore{raw: []byte(`{"rules":[{"name":"oil","category":"economy","keywords":{"en":["oil"]}}]}`)}
+
+	for _, tt := range []struct {
+		name string
+		cfg  config.TaggingConfig
+	}{
+		{"file", config.TaggingConfig{RulesPath: path}},
+		{"db", config.TaggingConfig{RulesSource: "db"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			source, err := NewSource(tt.cfg, store)
+			if err != nil {
+				t.Fatal(err)
+			}
+			set, err := source.LoadRules(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(set.Rules) != 1 || set.Rules[0].Category != "economy" || !slices.Equal(set.Rules[0].Keywords["en"], []string{"oil"}) {
+				t.Fatalf("rules = %+v", set)
+			}
+		})
+	}
+
+	for _, tt := range []struct {
+		name  string
+		cfg   config.TaggingConfig
+		store RulesStore
+	}{
+		{"file without path", config.TaggingConfig{RulesSource: "file"}, store},
+		{"db without store", config.TaggingConfig{RulesSource: "db"}, nil},
+		{"unknown source", config.TaggingConfig{RulesSource: "etcd"}, store},
+	} {
+		if _, err := NewSource(tt.cfg, tt.store); err == nil {
+			t.Errorf("NewSource(%s) succeeded", tt.name)
+		}
+	}
+
+	store.err = errors.New("connection refused")
+	if _, err := NewDBSource(store).LoadRules(context.Background()); !errors.Is(err, store.err) {
+		t.Fatalf("LoadRules() error = %v, want the store error", err)
+	}
+	if _, err := NewFileSource(filepath.Join(t.TempDir(), "missing.yaml")).LoadRules(context.Background()); err == nil {
+		t.Fatal("LoadRules() on a missing file succeeded")
+	}
+}
@@ -0,0 +1,100 @@
+// Package textdiff строит пословное сравнение двух версий текста.
+package textdiff
+
+import "strings"
+
+// Виды операций сравнения.
+const (
+	OpEqual  = "equal"
+	OpInsert = "insert"
+	OpDelete = "delete"
+)
+
+// maxCells ограничивает размер таблицы LCS; для более длинных текстов
+// отличающаяся середина описывается как удаление и вставка целиком.
+const maxCells = 4_000_000
+
+// Op — фрагмент сравнения: одинаковые, добавленные или удаленные слова.
+type Op struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// Words сравнивает тексты по словам. Соседние слова с одной операцией
+// объединяются в один фрагмент, пробельные символы нормализуются.
+func Words(before, after string) []Op {
+	a, b := strings.Fields(before), strings.Fields(after)
+
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var d differ
+	d.add(OpEqual, a[:prefix]...)
+	d.diff(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])
+	d.add(OpEqual, a[len(a)-suffix:]...)
+	return d.ops
+}
+
+// Changed сообщает, отличаются ли тексты без учета пробельных символов.
+func Changed(before, after string) bool {
+	return strings.Join(strings.Fields(before), " ") != strings.Join(strings.Fields(after), " ")
+}
+
+type differ struct {
+	ops []Op
+}
+
+func (d *differ) add(op string, words ...string) {
+	if len(words) == 0 {
+		return
+	}
+	text := strings.Join(words, " ")
+	if n := len(d.ops); n > 0 && d.ops[n-1].Op == op {
+		d.ops[n-1].Text += " " + text
+		return
+	}
+	d.ops = append(d.ops, Op{Op: op, Text: text})
+}
+
+// diff восстанавливает сравнение по таблице наибольшей общей подпоследовательности.
+func (d *differ) diff(a, b []string) {
+	if len(a) == 0 || len(b) == 0 || (len(a)+1)*(len(b)+1) > maxCells {
+		d.add(OpDelete, a...)
+		d.add(OpInsert, b...)
+		return
+	}
+	w := len(b) + 1
+	lcs := make([]int32, (len(a)+1)*w)
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i*w+j] = lcs[(i+1)*w+j+1] + 1
+			} else {
+				lcs[i*w+j] = max(lcs[(i+1)*w+j], lcs[i*w+j+1])
+			}
+		}
+	}
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			d.add(OpEqual, a[i])
+			i++
+			j++
+		case lcs[(i+1)*w+j] >= lcs[i*w+j+1]:
+			d.add(OpDelete, a[i])
+			i++
+		default:
+			d.add(OpInsert, b[j])
+			j++
+		}
+	}
+	d.add(OpDelete, a[i:]...)
+	d.add(OpInsert, b[j:]...)
+}
@@ -0,0 +1,76 @@
+package textdiff
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestWords(t *testing.T) {
+	cases := []struct {
+		name          string
+		before, after string
+		want          []Op
+	}{
+		{"equal", "a b c", "a b c", []Op{{OpEqual, "a b c"}}},
+		{"prefix only", "a b c", "x a b c", []Op{{OpInsert, "x"}, {OpEqual, "a b c"}}},
+		{"suffix only", "a b c", "a b", []Op{{OpEqual, "a b"}, {OpDelete, "c"}}},
+		{"middle", "курс рубля вырос вчера", "курс рубля упал вчера",
+			[]Op{{OpEqual, "курс рубля"}, {OpDelete, "вырос"}, {OpInsert, "упал"}, {OpEqual, "вчера"}}},
+		{"scattered", "a b c d e", "a x c d y e",
+			[]Op{{OpEqual, "a"}, {OpDelete, "b"}, {OpInsert, "x"}, {OpEqual, "c d"}, {OpInsert, "y"}, {OpEqual, "e"}}},
+		{"from empty", "", "a b", []Op{{OpInsert, "a b"}}},
+		{"to empty", "a b", "", []Op{{OpDelete, "a b"}}},
+		{"whitespace only", "a  b\n\tc ", " a b c", []Op{{OpEqual, "a b c"}}},
+		{"both empty", " ", "", nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := Words(c.before, c.after); !slices.Equal(got, c.want) {
+				t.Fatalf("Words(%q, %q) = %v, want %v", c.before, c.after, got, c.want)
+			}
+		})
+	}
+}
+
+func TestWordsFallbackAboveMaxCells(t *testing.T) {
+	// Середины по 2500 слов дают таблицу больше maxCells.
+	a := make([]string, 2500)
+	b := make([]string, 2500)
+	for i := range a {
+		a[i] = "a"
+		b[i] = "b"
+	}
+	a[1000], b[1000] = "common", "common"
+	before := "start " + strings.Join(a, " ") + " end"
+	after := "start " + strings.Join(b, " ") + " end"
+
+	got := Words(before, after)
+	want := []Op{
+		{OpEqual, "start"},
+		{OpDelete, strings.Join(a, " ")},
+		{OpInsert, strings.Join(b, " ")},
+		{OpEqual, "end"},
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("Words() returned %d ops, want whole middle replaced", len(got))
+	}
+}
+
+func TestChanged(t *testing.T) {
+	cases := []struct {
+		before, after string
+		want          bool
+	}{
+		{"a b", "a b", false},
+		{"a  b\n", " a\tb", false},
+		{"a b", "a c", true},
+		{"", " ", false},
+		{"ab", "a b", true},
+	}
+	for _, c := range cases {
+		if got := Changed(c.before, c.after); got != c.want {
+			t.Fatalf("Changed(%q, %q) = %v, want %v", c.before, c.after, got, c.want)
+		}
+	}
+}
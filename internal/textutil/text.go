@@ -0,0 +1,79 @@
+// Package textutil содержит общие средства разбора текста новостей на слова
+// для английского и русского языков.
+package textutil
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Token — слово текста в исходном и нормализованном виде.
+type Token struct {
+	Surface string
+	Stem    string
+}
+
+// russianSuffixes — окончания, отбрасываемые при упрощенном стемминге русских слов.
+// Более длинные окончания проверяются первыми.
+var russianSuffixes = []string{
+	"ями", "ами", "ого", "его", "ому", "ему", "ыми", "ими",
+	"ой", "ей", "ом", "ем", "ам", "ям", "ах", "ях", "ую", "юю",
+	"ая", "яя", "ое", "ее", "ые", "ие", "ый", "ий", "ов", "ев",
+	"а", "я", "у", "ю", "е", "и", "ы", "о", "ь",
+}
+
+// minStemRunes — минимальная длина основы после отбрасывания окончания.
+const minStemRunes = 3
+
+// Tokenize разбивает текст на слова и вычисляет их основы.
+func Tokenize(text string) []Token {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\'' && r != '’' && r != '-'
+	})
+	tokens := make([]Token, 0, len(words))
+	for _, w := range words {
+		w = strings.Trim(w, "'’-")
+		if w == "" {
+			continue
+		}
+		tokens = append(tokens, Token{Surface: w, Stem: Stem(w)})
+	}
+	return tokens
+}
+
+// Stem приводит слово к упрощенной основе: нижний регистр, без притяжательного
+// "'s" для английского и без падежного окончания для русского.
+func Stem(word string) string {
+	w := strings.ToLower(word)
+	w = strings.TrimSuffix(strings.TrimSuffix(w, "'s"), "’s")
+	if !isCyrillic(w) {
+		return w
+	}
+	runes := []rune(w)
+	for _, suffix := range russianSuffixes {
+		s := []rune(suffix)
+		if len(runes)-len(s) >= minStemRunes && strings.HasSuffix(w, suffix) {
+			return string(runes[:len(runes)-len(s)])
+		}
+	}
+	return w
+}
+
+func isCyrillic(word string) bool {
+	for _, r := range word {
+		if unicode.Is(unicode.Cyrillic, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsNumeric проверяет, что слово состоит только из цифр.
+func IsNumeric(word string) bool {
+	for _, r := range word {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
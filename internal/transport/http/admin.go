@@ -0,0 +1,64 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"newsservice/storage"
+	"time"
+
+	httputils "github.com/Fau1con/renderresponse"
+)
+
+type AdminHandler struct {
+	storage storage.AdminStorage
+}
+
+func NewAdminHandler(storage storage.AdminStorage) *AdminHandler {
+	return &AdminHandler{
+		storage: storage,
+	}
+}
+
+// HandleLanguageStats возвращает распределение новостей каждого источника по языкам.
+func (h *AdminHandler) HandleLanguageStats() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !httputils.ValidateMethod(w, r, http.MethodGet) {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+		defer cancel()
+
+		stats, err := h.storage.GetLanguageStats(ctx)
+		if err != nil {
+			httputils.RenderError(w, "failed to get language stats from database", http.StatusInternalServerError)
+			return
+		}
+
+		httputils.RenderJSON(w, stats, http.StatusOK)
+	}
+}
+
+// RulesReloader — компонент, правила которого можно перезагрузить без перезапуска сервиса.
+type RulesReloader interface {
+	Reload(ctx context.Context) error
+}
+
+// HandleReloadRules перезагружает правила из их источника.
+func HandleReloadRules(reloader RulesReloader) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !httputils.ValidateMethod(w, r, http.MethodPost) {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+		defer cancel()
+
+		if err := reloader.Reload(ctx); err != nil {
+			httputils.RenderError(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+
+		httputils.RenderJSON(w, "rules reloaded", http.StatusOK)
+	}
+}
@@ -0,0 +1,97 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"newsservice/internal/contentfilter"
+	"newsservice/internal/domain"
+	"newsservice/internal/infrastructure/config"
+	"newsservice/internal/usecase"
+
+	httputils "github.com/Fau1con/renderresponse"
+)
+
+// maxDryRunBody ограничивает размер тела запроса пробной фильтрации.
+const maxDryRunBody = 1 << 20
+
+// filterDryRunRequest — элементы для пробной проверки правилами фильтрации.
+// Если Rules задан, элементы проверяются им вместо действующих правил.
+type filterDryRunRequest struct {
+	Source string               `json:"source"`
+	Rules  *config.FilterConfig `json:"rules,omitempty"`
+	Items  []filterDryRunItem   `json:"items"`
+}
+
+type filterDryRunItem struct {
+	Title      string   `json:"title"`
+	Link       string   `json:"link"`
+	Author     string   `json:"author"`
+	Categories []string `json:"categories"`
+}
+
+type filterDryRunResult struct {
+	Title string `json:"title"`
+	Link  string `json:"link"`
+	contentfilter.Decision
+}
+
+// HandleFilterDryRun проверяет переданные элементы правилами фильтрации без сохранения.
+func HandleFilterDryRun(filter *contentfilter.Filter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !httputils.ValidateMethod(w, r, http.MethodPost) {
+			return
+		}
+
+		var req filterDryRunRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxDryRunBody)).Decode(&req); err != nil {
+			httputils.RenderError(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		items := make([]domain.Item, len(req.Items))
+		for i, it := range req.Items {
+			items[i] = domain.Item{
+				Title:      it.Title,
+				Link:       it.Link,
+				Author:     it.Author,
+				Categories: it.Categories,
+			}
+		}
+
+		var decisions []contentfilter.Decision
+		if req.Rules != nil {
+			var err error
+			decisions, err = contentfilter.Evaluate(*req.Rules, req.Source, items)
+			if err != nil {
+				httputils.RenderError(w, err.Error(), http.StatusUnprocessableEntity)
+				return
+			}
+		} else {
+			decisions = make([]contentfilter.Decision, len(items))
+			for i, item := range items {
+				decisions[i] = filter.Evaluate(req.Source, item)
+			}
+		}
+
+		results := make([]filterDryRunResult, len(items))
+		for i, item := range items {
+			results[i] = filterDryRunResult{Title: item.Title, Link: item.Link, Decision: decisions[i]}
+		}
+		httputils.RenderJSON(w, results, http.StatusOK)
+	}
+}
+
+// FeedStatsProvider — источник статистики обработки фидов.
+type FeedStatsProvider interface {
+	Stats() map[string]usecase.FeedStats
+}
+
+// HandleFeedStats возвращает статистику последней обработки каждого фида.
+func HandleFeedStats(provider FeedStatsProvider) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !httputils.ValidateMethod(w, r, http.MethodGet) {
+			return
+		}
+		httputils.RenderJSON(w, provider.Stats(), http.StatusOK)
+	}
+}
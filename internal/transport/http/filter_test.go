@@ -0,0 +1,97 @@
+package http
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"newsservice/internal/contentfilter"
+	"newsservice/internal/infrastructure/config"
+	"strings"
+	"testing"
+)
+
+func serveDryRun(t *testing.T, method, body string) (int, []byte) {
+	t.Helper()
+	filter, err := contentfilter.New(config.FilterConfig{Global: config.FilterRules{
+		Exclude: []config.FilterRule{{Name: "ads", Field: contentfilter.FieldTitle, Contains: "реклама"}},
+	}}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := httptest.NewRecorder()
+	HandleFilterDryRun(filter).ServeHTTP(rec, httptest.NewRequest(method, "/admin/filter/dry-run", strings.NewReader(body)))
+	return rec.Code, rec.Body.Bytes()
+}
+
+func TestFilterDryRun(t *testing.T) {
+	cases := []struct {
+		name string
+		body string
+		want []filterDryRunResult
+	}{
+		{"active rules", `{"source": "ria", "items": [{"title": "Реклама"}, {"title": "Новость"}]}`,
+			[]filterDryRunResult{
+				{Title: "Реклама", Decision: contentfilter.Decision{Scope: "global", Rule: "ads", Reason: "exclude"}},
+				{Title: "Новость", Decision: contentfilter.Decision{Keep: true}},
+			}},
+		{"inline rules", `{
+			"source": "ria",
+			"rules": {"sources": {"ria": {"include": [{"name": "politics", "field": "category", "contains": "политика"}]}}},
+			"items": [
+				{"title": "Реклама", "categories": ["Политика"]},
+				{"title": "Спорт", "link": "https://ria.ru/sport/1", "categories": ["Спорт"]}
+			]}`,
+			[]filterDryRunResult{
+				{Title: "Реклама", Decision: contentfilter.Decision{Keep: true}},
+				{Title: "Спорт", Link: "https://ria.ru/sport/1", Decision: contentfilter.Decision{Scope: "source", Reason: "include"}},
+			}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			code, body := serveDryRun(t, http.MethodPost, c.body)
+			if code != http.StatusOK {
+				t.Fatalf("status = %d, body %s", code, body)
+			}
+			var resp struct {
+				Data []filterDryRunResult `json:"data"`
+			}
+			if err := json.Unmarshal(body, &resp); err != nil {
+				t.Fatal(err)
+			}
+			got := resp.Data
+			if len(got) != len(c.want) {
+				t.Fatalf("results = %+v, want %+v", got, c.want)
+			}
+			for i := range got {
+				if got[i] != c.want[i] {
+					t.Fatalf("result %d = %+v, want %+v", i, got[i], c.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFilterDryRunErrors(t *testing.T) {
+	cases := []struct {
+		name   string
+		method string
+		body   string
+		want   int
+	}{
+		{"method", http.MethodGet, "", http.StatusMethodNotAllowed},
+		{"invalid body", http.MethodPost, `{"items": `, http.StatusBadRequest},
+		{"invalid regex", http.MethodPost, `{"rules": {"global": {"exclude": [{"field": "title", "regex": "("}]}}, "items": []}`,
+			http.StatusUnprocessableEntity},
+		{"unknown field", http.MethodPost, `{"rules": {"global": {"include": [{"field": "body", "contains": "x"}]}}, "items": []}`,
+			http.StatusUnprocessableEntity},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if code, body := serveDryRun(t, c.method, c.body); code != c.want {
+				t.Fatalf("status = %d, want %d, body %s", code, c.want, body)
+			}
+		})
+	}
+}
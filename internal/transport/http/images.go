@@ -0,0 +1,52 @@
+package http
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"newsservice/internal/blobstore"
+	"newsservice/internal/models"
+	"path"
+	"strings"
+
+	httputils "github.com/Fau1con/renderresponse"
+)
+
+// HandleImages отдает изображения и миниатюры из хранилища объектов по пути
+// models.ImagesPath + ключ. Ключи содержат хеш исходной ссылки и не меняются,
+// поэтому ответы кэшируются клиентами без ограничения срока.
+func HandleImages(store blobstore.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !httputils.ValidateMethod(w, r, http.MethodGet, http.MethodHead) {
+			return
+		}
+
+		key := strings.TrimPrefix(r.URL.Path, models.ImagesPath)
+		contentType := mime.TypeByExtension(path.Ext(key))
+		if key == "" || !strings.HasPrefix(contentType, "image/") {
+			httputils.RenderError(w, "image not found", http.StatusNotFound)
+			return
+		}
+
+		body, err := store.Get(r.Context(), key)
+		if errors.Is(err, blobstore.ErrNotFound) || errors.Is(err, blobstore.ErrInvalidKey) {
+			httputils.RenderError(w, "image not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			httputils.RenderError(w, "failed to read image", http.StatusInternalServerError)
+			return
+		}
+		defer body.Close()
+
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.WriteHeader(http.StatusOK)
+		if r.Method == http.MethodHead {
+			return
+		}
+		io.Copy(w, body)
+	}
+}
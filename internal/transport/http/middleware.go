@@ -3,13 +3,21 @@ package http
 import (
 	"context"
 	"crypto/rand"
+	"crypto/subtle"
 	"encoding/hex"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"path"
+	"strings"
 	"time"
+
+	httputils "github.com/Fau1con/renderresponse"
 )
 
+// adminPrefix — префикс маршрутов администрирования.
+const adminPrefix = "/admin/"
+
 type contextKey string
 
 const requestIDKey contextKey = "request_id"
@@ -77,7 +85,7 @@ func generateRequestID() string {
 
 // GetRequestID извлекает ID запроса из контекста
 func GetRequestID(ctx context.Context) string {
-	if id, ok := ctx.Value("request_id").(string); ok {
+	if id, ok := ctx.Value(requestIDKey).(string); ok {
 		return id
 	}
 	return ""
@@ -101,3 +109,28 @@ func CORSMiddleware() func(http.Handler) http.Handler {
 		})
 	}
 }
+
+// AdminAuthMiddleware пропускает запросы к маршрутам /admin/ только с
+// заголовком "Authorization: Bearer <token>". Пустой token отключает эти маршруты.
+func AdminAuthMiddleware(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Путь очищается так же, как это делает http.ServeMux при выборе маршрута.
+			if p := path.Clean("/" + r.URL.Path); !strings.HasPrefix(p+"/", adminPrefix) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if token == "" {
+				httputils.RenderError(w, "admin endpoints are disabled", http.StatusForbidden)
+				return
+			}
+			got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+				httputils.RenderError(w, "invalid admin token", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
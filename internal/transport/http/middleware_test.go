@@ -0,0 +1,44 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAdminAuthMiddleware(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	cases := []struct {
+		name   string
+		token  string
+		path   string
+		header string
+		want   int
+	}{
+		{"public route", "secret", "/api/v1/news", "", http.StatusTeapot},
+		{"valid token", "secret", "/admin/tags/merge", "Bearer secret", http.StatusTeapot},
+		{"missing token", "secret", "/admin/tags/merge", "", http.StatusUnauthorized},
+		{"wrong token", "secret", "/admin/categories/sport", "Bearer other", http.StatusUnauthorized},
+		{"wrong scheme", "secret", "/admin/feeds/stats", "Basic secret", http.StatusUnauthorized},
+		{"unclean path", "secret", "/api/../admin/tags/merge", "", http.StatusUnauthorized},
+		{"admin root", "secret", "/admin", "", http.StatusUnauthorized},
+		{"not configured", "", "/admin/tags/merge", "Bearer ", http.StatusForbidden},
+		{"not configured public route", "", "/api/v1/news", "", http.StatusTeapot},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			req.URL.Path = c.path
+			if c.header != "" {
+				req.Header.Set("Authorization", c.header)
+			}
+			rec := httptest.NewRecorder()
+			AdminAuthMiddleware(c.token)(next).ServeHTTP(rec, req)
+			if rec.Code != c.want {
+				t.Fatalf("status = %d, want %d", rec.Code, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"newsservice/storage"
+	"strconv"
+	"time"
+
+	httputils "github.com/Fau1con/renderresponse"
+)
+
+// HandleNewsRevisions возвращает историю правок новости из пути /news/{id}/revisions.
+func HandleNewsRevisions(revisions storage.RevisionStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !httputils.ValidateMethod(w, r, http.MethodGet) {
+			return
+		}
+
+		newsID, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil || newsID <= 0 {
+			httputils.RenderError(w, "invalid news id", http.StatusBadRequest)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+		defer cancel()
+
+		history, err := revisions.GetNewsRevisions(ctx, newsID)
+		if errors.Is(err, storage.ErrNotFound) {
+			httputils.RenderError(w, "news not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			httputils.RenderError(w, "failed to get news revisions from database", http.StatusInternalServerError)
+			return
+		}
+
+		httputils.RenderJSON(w, history, http.StatusOK)
+	}
+}
@@ -0,0 +1,96 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"newsservice/internal/usecase"
+	"strconv"
+	"strings"
+	"time"
+
+	httputils "github.com/Fau1con/renderresponse"
+)
+
+// HandleFullTextSearch ищет новости по релевантности: GET /api/v1/search?q=....
+// Запрос поддерживает фразы в кавычках, префиксы со звездочкой, исключения
+// через минус и OR. Фильтры: source, category, lang, author, date;
+// facets=true добавляет распределение результатов по источникам, категориям,
+// языкам и дням.
+//
+// Вывод по курсору не поддерживается: оценки релевантности меняются вместе с
+// индексом и не годятся для ключа курсора. Параметр cursor отклоняется, для
+// вывода по курсору есть /api/v1/news/search.
+func HandleFullTextSearch(uc *usecase.SearchUseCase, log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !httputils.ValidateMethod(w, r, http.MethodGet) {
+			return
+		}
+
+		text := strings.TrimSpace(r.URL.Query().Get("q"))
+		if text == "" {
+			httputils.RenderError(w, "q parameter is required", http.StatusBadRequest)
+			return
+		}
+		values := r.URL.Query()
+		if values.Has("cursor") {
+			httputils.RenderError(w, "cursor parameter is not supported by full-text search, use page or /api/v1/news/search", http.StatusBadRequest)
+			return
+		}
+		page, err := parsePositiveInt(values.Get("page"), "page")
+		if err != nil {
+			httputils.RenderError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		limit, err := parsePositiveInt(values.Get("limit"), "limit")
+		if err != nil {
+			httputils.RenderError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		facets, err := parseBool(values.Get("facets"), "facets")
+		if err != nil {
+			httputils.RenderError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+		defer cancel()
+
+		result, err := uc.Search(ctx, usecase.SearchQuery{
+			Text:     text,
+			Page:     page,
+			Limit:    limit,
+			Source:   values.Get("source"),
+			Category: values.Get("category"),
+			Language: values.Get("lang"),
+			Author:   values.Get("author"),
+			Date:     values.Get("date"),
+			Facets:   facets,
+		})
+		if errors.Is(err, usecase.ErrInvalidQuery) {
+			httputils.RenderError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			log.Error("Failed to run full-text search", slog.String("query", text), slog.Any("error", err))
+			httputils.RenderError(w, "failed to search news", http.StatusInternalServerError)
+			return
+		}
+
+		httputils.RenderJSON(w, result, http.StatusOK)
+	}
+}
+
+// parseBool разбирает логический параметр; пустое значение дает false.
+func parseBool(raw, name string) (bool, error) {
+	if raw == "" {
+		return false, nil
+	}
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("%s parameter must be a boolean", name)
+	}
+	return value, nil
+}
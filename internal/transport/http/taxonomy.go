@@ -0,0 +1,206 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"newsservice/internal/models"
+	"newsservice/internal/usecase"
+	"newsservice/storage"
+	"time"
+
+	httputils "github.com/Fau1con/renderresponse"
+)
+
+// maxTaxonomyBody ограничивает размер тела административных запросов к справочникам.
+const maxTaxonomyBody = 64 << 10
+
+// TaxonomyHandler — просмотр тегов и категорий и их администрирование.
+type TaxonomyHandler struct {
+	taxonomy *usecase.TaxonomyUseCase
+	log      *slog.Logger
+}
+
+func NewTaxonomyHandler(taxonomy *usecase.TaxonomyUseCase, log *slog.Logger) *TaxonomyHandler {
+	return &TaxonomyHandler{
+		taxonomy: taxonomy,
+		log:      log,
+	}
+}
+
+type renameTagRequest struct {
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+}
+
+type mergeTagsRequest struct {
+	From []string `json:"from"`
+	Into string   `json:"into"`
+}
+
+type saveCategoryRequest struct {
+	Parent string            `json:"parent"`
+	Names  map[string]string `json:"names"`
+}
+
+// HandleListTags возвращает теги с числом новостей: GET /api/v1/tags?prefix=...&limit=....
+func (h *TaxonomyHandler) HandleListTags() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !httputils.ValidateMethod(w, r, http.MethodGet) {
+			return
+		}
+		limit, err := parsePositiveInt(r.URL.Query().Get("limit"), "limit")
+		if err != nil {
+			httputils.RenderError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+		defer cancel()
+
+		tags, err := h.taxonomy.ListTags(ctx, r.URL.Query().Get("prefix"), limit)
+		if err != nil {
+			h.renderError(w, err, "failed to list tags")
+			return
+		}
+		httputils.RenderJSON(w, tags, http.StatusOK)
+	}
+}
+
+// HandleTagNews возвращает новости с тегом: GET /api/v1/tags/{slug}/news.
+// Принимает те же параметры фильтрации и пагинации, что и список новостей.
+func (h *TaxonomyHandler) HandleTagNews() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !httputils.ValidateMethod(w, r, http.MethodGet) {
+			return
+		}
+		query, err := parseListQuery(r)
+		if err != nil {
+			httputils.RenderError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+		defer cancel()
+
+		result, err := h.taxonomy.NewsByTag(ctx, r.PathValue("slug"), query)
+		if err != nil {
+			h.renderError(w, err, "failed to list news by tag")
+			return
+		}
+		httputils.RenderJSON(w, result, http.StatusOK)
+	}
+}
+
+// HandleListCategories возвращает дерево категорий с числом новостей:
+// GET /api/v1/categories?lang=....
+func (h *TaxonomyHandler) HandleListCategories() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !httputils.ValidateMethod(w, r, http.MethodGet) {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+		defer cancel()
+
+		categories, err := h.taxonomy.Categories(ctx, r.URL.Query().Get("lang"))
+		if err != nil {
+			h.renderError(w, err, "failed to list categories")
+			return
+		}
+		httputils.RenderJSON(w, categories, http.StatusOK)
+	}
+}
+
+// HandleRenameTag меняет slug и название тега: POST /admin/tags/{slug}/rename.
+func (h *TaxonomyHandler) HandleRenameTag() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !httputils.ValidateMethod(w, r, http.MethodPost) {
+			return
+		}
+		var req renameTagRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxTaxonomyBody)).Decode(&req); err != nil {
+			httputils.RenderError(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+		defer cancel()
+
+		tag, err := h.taxonomy.RenameTag(ctx, r.PathValue("slug"), req.Slug, req.Name)
+		if err != nil {
+			h.renderError(w, err, "failed to rename tag")
+			return
+		}
+		httputils.RenderJSON(w, tag, http.StatusOK)
+	}
+}
+
+// HandleMergeTags объединяет теги: POST /admin/tags/merge.
+func (h *TaxonomyHandler) HandleMergeTags() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !httputils.ValidateMethod(w, r, http.MethodPost) {
+			return
+		}
+		var req mergeTagsRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxTaxonomyBody)).Decode(&req); err != nil {
+			httputils.RenderError(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+		defer cancel()
+
+		tag, err := h.taxonomy.MergeTags(ctx, req.From, req.Into)
+		if err != nil {
+			h.renderError(w, err, "failed to merge tags")
+			return
+		}
+		httputils.RenderJSON(w, tag, http.StatusOK)
+	}
+}
+
+// HandleSaveCategory создает или изменяет категорию: PUT /admin/categories/{slug}.
+func (h *TaxonomyHandler) HandleSaveCategory() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !httputils.ValidateMethod(w, r, http.MethodPut) {
+			return
+		}
+		var req saveCategoryRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxTaxonomyBody)).Decode(&req); err != nil {
+			httputils.RenderError(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+		defer cancel()
+
+		category := models.Category{
+			Slug:   r.PathValue("slug"),
+			Parent: req.Parent,
+			Names:  req.Names,
+		}
+		if err := h.taxonomy.SaveCategory(ctx, category); err != nil {
+			h.renderError(w, err, "failed to save category")
+			return
+		}
+		httputils.RenderJSON(w, category, http.StatusOK)
+	}
+}
+
+// renderError отвечает кодом, соответствующим ошибке use case или хранилища.
+func (h *TaxonomyHandler) renderError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidQuery):
+		httputils.RenderError(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, storage.ErrNotFound):
+		httputils.RenderError(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, storage.ErrConflict):
+		httputils.RenderError(w, err.Error(), http.StatusConflict)
+	default:
+		h.log.Error("Taxonomy request failed", slog.String("operation", message), slog.Any("error", err))
+		httputils.RenderError(w, message, http.StatusInternalServerError)
+	}
+}
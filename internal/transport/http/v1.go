@@ -0,0 +1,216 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"newsservice/internal/usecase"
+	"newsservice/storage"
+	"strconv"
+	"strings"
+	"time"
+
+	httputils "github.com/Fau1con/renderresponse"
+)
+
+// V1Handler — обработчики REST API /api/v1, читающие параметры из самого запроса.
+type V1Handler struct {
+	news *usecase.NewsQueryUseCase
+	log  *slog.Logger
+}
+
+func NewV1Handler(news *usecase.NewsQueryUseCase, log *slog.Logger) *V1Handler {
+	return &V1Handler{
+		news: news,
+		log:  log,
+	}
+}
+
+// HandleListNews возвращает страницу новостей: GET /api/v1/news. С параметром
+// cursor (пустым для первой страницы) новости выводятся по курсору, иначе по
+// номеру страницы. Порядок задается параметром sort, например
+// sort=-published_at,title. Фильтры source, category, tag, lang и exclude_*
+// принимают несколько значений: повтором параметра или через запятую.
+func (h *V1Handler) HandleListNews() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !httputils.ValidateMethod(w, r, http.MethodGet) {
+			return
+		}
+
+		query, err := parseListQuery(r)
+		if err != nil {
+			httputils.RenderError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+		defer cancel()
+
+		var result any
+		if query.Cursor != nil {
+			result, err = h.news.ListNewsByCursor(ctx, query)
+		} else {
+			result, err = h.news.ListNews(ctx, query)
+		}
+		if errors.Is(err, usecase.ErrInvalidQuery) {
+			httputils.RenderError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			h.log.Error("Failed to list news", slog.Any("error", err))
+			httputils.RenderError(w, "failed to get news from database", http.StatusInternalServerError)
+			return
+		}
+
+		httputils.RenderJSON(w, result, http.StatusOK)
+	}
+}
+
+// HandleGetNews возвращает новость по идентификатору: GET /api/v1/news/{id}.
+func (h *V1Handler) HandleGetNews() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !httputils.ValidateMethod(w, r, http.MethodGet) {
+			return
+		}
+
+		newsID, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil || newsID <= 0 {
+			httputils.RenderError(w, "invalid news id", http.StatusBadRequest)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+		defer cancel()
+
+		news, err := h.news.GetNews(ctx, newsID)
+		if errors.Is(err, storage.ErrNotFound) {
+			httputils.RenderError(w, "news not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			h.log.Error("Failed to get news", slog.Int("newsID", newsID), slog.Any("error", err))
+			httputils.RenderError(w, "failed to get news from database", http.StatusInternalServerError)
+			return
+		}
+
+		httputils.RenderJSON(w, news, http.StatusOK)
+	}
+}
+
+// HandleSearchNews ищет новости по тексту: GET /api/v1/news/search?q=....
+func (h *V1Handler) HandleSearchNews() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !httputils.ValidateMethod(w, r, http.MethodGet) {
+			return
+		}
+
+		text := strings.TrimSpace(r.URL.Query().Get("q"))
+		if text == "" {
+			httputils.RenderError(w, "q parameter is required", http.StatusBadRequest)
+			return
+		}
+		query, err := parseListQuery(r)
+		if err != nil {
+			httputils.RenderError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+		defer cancel()
+
+		var result any
+		if query.Cursor != nil {
+			result, err = h.news.SearchNewsByCursor(ctx, text, query)
+		} else {
+			result, err = h.news.SearchNews(ctx, text, query)
+		}
+		if errors.Is(err, usecase.ErrInvalidQuery) {
+			httputils.RenderError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			h.log.Error("Failed to search news", slog.String("query", text), slog.Any("error", err))
+			httputils.RenderError(w, "failed to search news in database", http.StatusInternalServerError)
+			return
+		}
+
+		httputils.RenderJSON(w, result, http.StatusOK)
+	}
+}
+
+// parseListQuery читает параметры фильтрации и пагинации из строки запроса.
+// Значения проверяются в usecase.NewsQueryUseCase.
+func parseListQuery(r *http.Request) (usecase.ListQuery, error) {
+	values := r.URL.Query()
+	page, err := parsePositiveInt(values.Get("page"), "page")
+	if err != nil {
+		return usecase.ListQuery{}, err
+	}
+	limit, err := parsePositiveInt(values.Get("limit"), "limit")
+	if err != nil {
+		return usecase.ListQuery{}, err
+	}
+	var cursor *string
+	if values.Has("cursor") {
+		token := values.Get("cursor")
+		cursor = &token
+	}
+	var hasImage *bool
+	if raw := values.Get("has_image"); raw != "" {
+		value, err := parseBool(raw, "has_image")
+		if err != nil {
+			return usecase.ListQuery{}, err
+		}
+		hasImage = &value
+	}
+	return usecase.ListQuery{
+		Page:              page,
+		Limit:             limit,
+		Cursor:            cursor,
+		Sources:           listParam(values, "source"),
+		ExcludeSources:    listParam(values, "exclude_source"),
+		Categories:        listParam(values, "category"),
+		ExcludeCategories: listParam(values, "exclude_category"),
+		Tags:              listParam(values, "tag"),
+		TagsMatch:         values.Get("tags_match"),
+		ExcludeTags:       listParam(values, "exclude_tag"),
+		Languages:         listParam(values, "lang"),
+		Author:            values.Get("author"),
+		Date:              values.Get("date"),
+		From:              values.Get("from"),
+		To:                values.Get("to"),
+		HasImage:          hasImage,
+		Collapse:          values.Get("collapse"),
+		Entity:            values.Get("entity"),
+		Sort:              values.Get("sort"),
+	}, nil
+}
+
+// listParam собирает значения параметра, переданного несколько раз или
+// через запятую: source=a&source=b или source=a,b.
+func listParam(values url.Values, name string) []string {
+	var out []string
+	for _, raw := range values[name] {
+		for _, v := range strings.Split(raw, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				out = append(out, v)
+			}
+		}
+	}
+	return out
+}
+
+// parsePositiveInt разбирает положительное целое; пустое значение дает 0.
+func parsePositiveInt(raw, name string) (int, error) {
+	if raw == "" {
+		return 0, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < 1 {
+		return 0, fmt.Errorf("%s parameter must be a positive integer", name)
+	}
+	return value, nil
+}
@@ -0,0 +1,86 @@
+// Package kafka реализует шлюз запросов и ответов через Kafka: запросы в
+// версионированном JSON-конверте передаются тем же use case, что и HTTP API.
+package kafka
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// EnvelopeVersion — поддерживаемая версия конверта.
+const EnvelopeVersion = 1
+
+// Операции, доступные через шлюз.
+const (
+	OpNewsList   = "news.list"
+	OpNewsGet    = "news.get"
+	OpNewsSearch = "news.search"
+)
+
+// Статусы ответа.
+const (
+	StatusOK    = "ok"
+	StatusError = "error"
+)
+
+// Request — конверт запроса. ReplyTo — топик для ответа из разрешенного списка;
+// если он не задан или не разрешен, ответ отправляется в топик ответов по
+// умолчанию. Запрос с истекшим
+// Deadline не выполняется.
+type Request struct {
+	Version       int             `json:"version"`
+	CorrelationID string          `json:"correlation_id"`
+	ReplyTo       string          `json:"reply_to,omitempty"`
+	Operation     string          `json:"operation"`
+	Params        json.RawMessage `json:"params,omitempty"`
+	Deadline      *time.Time      `json:"deadline,omitempty"`
+}
+
+// Reply — конверт ответа; CorrelationID повторяет идентификатор запроса.
+type Reply struct {
+	Version       int    `json:"version"`
+	CorrelationID string `json:"correlation_id"`
+	Operation     string `json:"operation"`
+	Status        string `json:"status"`
+	Data          any    `json:"data,omitempty"`
+	Error         *Error `json:"error,omitempty"`
+}
+
+// ListParams — параметры операции news.list. Заданный Cursor (пустой для
+// первой страницы) включает вывод по курсору вместо номера страницы.
+// Category и Lang сохранены для совместимости и дополняют списки
+// Categories и Langs.
+type ListParams struct {
+	Page              int      `json:"page,omitempty"`
+	Limit             int      `json:"limit,omitempty"`
+	Cursor            *string  `json:"cursor,omitempty"`
+	Sources           []string `json:"sources,omitempty"`
+	ExcludeSources    []string `json:"exclude_sources,omitempty"`
+	Category          string   `json:"category,omitempty"`
+	Categories        []string `json:"categories,omitempty"`
+	ExcludeCategories []string `json:"exclude_categories,omitempty"`
+	Tags              []string `json:"tags,omitempty"`
+	TagsMatch         string   `json:"tags_match,omitempty"`
+	ExcludeTags       []string `json:"exclude_tags,omitempty"`
+	Lang              string   `json:"lang,omitempty"`
+	Langs             []string `json:"langs,omitempty"`
+	Author            string   `json:"author,omitempty"`
+	Date              string   `json:"date,omitempty"`
+	From              string   `json:"from,omitempty"`
+	To                string   `json:"to,omitempty"`
+	HasImage          *bool    `json:"has_image,omitempty"`
+	Collapse          string   `json:"collapse,omitempty"`
+	Entity            string   `json:"entity,omitempty"`
+	Sort              string   `json:"sort,omitempty"`
+}
+
+// GetParams — параметры операции news.get.
+type GetParams struct {
+	ID int `json:"id"`
+}
+
+// SearchParams — параметры операции news.search.
+type SearchParams struct {
+	Query string `json:"q"`
+	ListParams
+}
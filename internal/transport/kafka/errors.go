@@ -0,0 +1,27 @@
+package kafka
+
+import "fmt"
+
+// Коды ошибок в ответах шлюза.
+const (
+	CodeBadRequest         = "bad_request"
+	CodeNotFound           = "not_found"
+	CodeUnknownOperation   = "unknown_operation"
+	CodeUnsupportedVersion = "unsupported_version"
+	CodeDeadlineExceeded   = "deadline_exceeded"
+	CodeInternal           = "internal"
+)
+
+// Error — типизированная ошибка, возвращаемая в конверте ответа.
+type Error struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+func newError(code, format string, args ...any) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
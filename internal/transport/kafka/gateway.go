@@ -0,0 +1,272 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"newsservice/internal/broker"
+	"newsservice/internal/dlq"
+	"newsservice/internal/usecase"
+	"newsservice/storage"
+	"strings"
+	"time"
+)
+
+// defaultTimeout ограничивает выполнение запроса без собственного Deadline.
+const defaultTimeout = 10 * time.Second
+
+// Gateway читает запросы из топика, выполняет их и публикует ответы.
+type Gateway struct {
+	consumer     broker.Subscriber
+	producer     broker.Publisher
+	news         *usecase.NewsQueryUseCase
+	defaultReply string
+	replyTopics  []string
+	deadLetters  *dlq.Processor
+	log          *slog.Logger
+}
+
+// NewGateway создает шлюз. replyTopics — топики, которые разрешено указывать
+// в reply_to; значение с завершающей звездочкой задает префикс.
+func NewGateway(consumer broker.Subscriber, producer broker.Publisher, news *usecase.NewsQueryUseCase, defaultReply string, replyTopics []string, deadLetters *dlq.Processor, log *slog.Logger) *Gateway {
+	return &Gateway{
+		consumer:     consumer,
+		producer:     producer,
+		news:         news,
+		defaultReply: defaultReply,
+		replyTopics:  replyTopics,
+		deadLetters:  deadLetters,
+		log:          log.With(slog.String("component", "kafka-gateway")),
+	}
+}
+
+// Run обрабатывает запросы, пока не будет отменен ctx.
+func (g *Gateway) Run(ctx context.Context) error {
+	g.log.Info("Kafka gateway started")
+	for {
+		msg, err := g.consumer.Receive(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				g.log.Info("Kafka gateway stopped")
+				return nil
+			}
+			g.log.Error("Failed to read request from Kafka", slog.Any("error", err))
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+		if err := g.deadLetters.Process(ctx, msg, g.handleMessage); err != nil {
+			g.log.Error("Kafka request lost", slog.Int64("offset", msg.Offset), slog.Any("error", err))
+		}
+	}
+}
+
+func (g *Gateway) handleMessage(ctx context.Context, msg broker.Message) error {
+	return g.Handle(ctx, msg.Value)
+}
+
+// Handle выполняет один запрос и отправляет ответ. Ошибка означает, что ответ
+// не был доставлен; ошибки, помеченные dlq.Permanent, повторять бессмысленно.
+func (g *Gateway) Handle(ctx context.Context, raw []byte) error {
+	var req Request
+	var poison error
+	reply := Reply{Version: EnvelopeVersion}
+	if err := json.Unmarshal(raw, &req); err != nil {
+		reply.Status = StatusError
+		reply.Error = newError(CodeBadRequest, "malformed envelope: %v", err)
+		poison = dlq.Permanent(fmt.Errorf("malformed envelope: %w", err))
+	} else {
+		reply.CorrelationID = req.CorrelationID
+		reply.Operation = req.Operation
+		data, rerr := g.dispatch(ctx, req)
+		if rerr != nil {
+			reply.Status = StatusError
+			reply.Error = rerr
+		} else {
+			reply.Status = StatusOK
+			reply.Data = data
+		}
+	}
+
+	log := g.log.With(
+		slog.String("correlation_id", reply.CorrelationID),
+		slog.String("operation", reply.Operation),
+	)
+	if reply.Error != nil {
+		log.Warn("Kafka request failed", slog.String("code", reply.Error.Code), slog.String("error", reply.Error.Message))
+	}
+
+	topic := g.defaultReply
+	if req.ReplyTo != "" {
+		if g.replyAllowed(req.ReplyTo) {
+			topic = req.ReplyTo
+		} else {
+			log.Warn("Reply topic is not allowed, using the default", slog.String("reply_to", req.ReplyTo))
+		}
+	}
+	if topic == "" {
+		log.Error("Reply dropped: no reply topic")
+		return dlq.Permanent(errors.New("no reply topic"))
+	}
+	payload, err := json.Marshal(reply)
+	if err != nil {
+		log.Error("Failed to encode reply", slog.Any("error", err))
+		return dlq.Permanent(fmt.Errorf("failed to encode reply: %w", err))
+	}
+	if err := g.producer.Publish(ctx, topic, []byte(reply.CorrelationID), payload); err != nil {
+		log.Error("Failed to send reply", slog.String("topic", topic), slog.Any("error", err))
+		return fmt.Errorf("failed to send reply: %w", err)
+	}
+	log.Debug("Reply sent", slog.String("topic", topic), slog.String("status", reply.Status))
+	// Отправитель получил ответ об ошибке, но само сообщение сохраняется для разбора.
+	return poison
+}
+
+// replyAllowed сообщает, можно ли отправить ответ в топик из reply_to.
+func (g *Gateway) replyAllowed(topic string) bool {
+	if topic == g.defaultReply {
+		return true
+	}
+	for _, allowed := range g.replyTopics {
+		if prefix, ok := strings.CutSuffix(allowed, "*"); ok {
+			if prefix != "" && strings.HasPrefix(topic, prefix) {
+				return true
+			}
+			continue
+		}
+		if topic == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// dispatch проверяет конверт и вызывает use case операции.
+func (g *Gateway) dispatch(ctx context.Context, req Request) (any, *Error) {
+	if req.Version != EnvelopeVersion {
+		return nil, newError(CodeUnsupportedVersion, "envelope version %d is not supported, expected %d", req.Version, EnvelopeVersion)
+	}
+	if req.CorrelationID == "" {
+		return nil, newError(CodeBadRequest, "correlation_id is required")
+	}
+
+	deadline := time.Now().Add(defaultTimeout)
+	if req.Deadline != nil {
+		if !req.Deadline.After(time.Now()) {
+			return nil, newError(CodeDeadlineExceeded, "deadline %s has passed", req.Deadline.Format(time.RFC3339))
+		}
+		deadline = *req.Deadline
+	}
+	ctx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+
+	var data any
+	var err error
+	switch req.Operation {
+	case OpNewsList:
+		var p ListParams
+		if rerr := decodeParams(req.Params, &p); rerr != nil {
+			return nil, rerr
+		}
+		if p.Cursor != nil {
+			data, err = g.news.ListNewsByCursor(ctx, p.query())
+		} else {
+			data, err = g.news.ListNews(ctx, p.query())
+		}
+	case OpNewsGet:
+		var p GetParams
+		if rerr := decodeParams(req.Params, &p); rerr != nil {
+			return nil, rerr
+		}
+		data, err = g.news.GetNews(ctx, p.ID)
+	case OpNewsSearch:
+		var p SearchParams
+		if rerr := decodeParams(req.Params, &p); rerr != nil {
+			return nil, rerr
+		}
+		text := strings.TrimSpace(p.Query)
+		if text == "" {
+			return nil, newError(CodeBadRequest, "q parameter is required")
+		}
+		if p.Cursor != nil {
+			data, err = g.news.SearchNewsByCursor(ctx, text, p.query())
+		} else {
+			data, err = g.news.SearchNews(ctx, text, p.query())
+		}
+	default:
+		return nil, newError(CodeUnknownOperation, "unknown operation %q", req.Operation)
+	}
+	if err != nil {
+		rerr := classify(err)
+		if rerr.Code == CodeInternal {
+			g.log.Error("Kafka operation failed",
+				slog.String("correlation_id", req.CorrelationID),
+				slog.String("operation", req.Operation),
+				slog.Any("error", err),
+			)
+		}
+		return nil, rerr
+	}
+	return data, nil
+}
+
+func decodeParams(raw json.RawMessage, dst any) *Error {
+	if len(raw) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(raw, dst); err != nil {
+		return newError(CodeBadRequest, "invalid params: %v", err)
+	}
+	return nil
+}
+
+// classify сопоставляет ошибку use case с кодом ответа.
+func classify(err error) *Error {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidQuery):
+		return newError(CodeBadRequest, "%s", err.Error())
+	case errors.Is(err, storage.ErrNotFound):
+		return newError(CodeNotFound, "%s", err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return newError(CodeDeadlineExceeded, "request deadline exceeded")
+	default:
+		return newError(CodeInternal, "internal error")
+	}
+}
+
+func (p ListParams) query() usecase.ListQuery {
+	categories := p.Categories
+	if p.Category != "" {
+		categories = append(categories, p.Category)
+	}
+	langs := p.Langs
+	if p.Lang != "" {
+		langs = append(langs, p.Lang)
+	}
+	return usecase.ListQuery{
+		Page:              p.Page,
+		Limit:             p.Limit,
+		Cursor:            p.Cursor,
+		Sources:           p.Sources,
+		ExcludeSources:    p.ExcludeSources,
+		Categories:        categories,
+		ExcludeCategories: p.ExcludeCategories,
+		Tags:              p.Tags,
+		TagsMatch:         p.TagsMatch,
+		ExcludeTags:       p.ExcludeTags,
+		Languages:         langs,
+		Author:            p.Author,
+		Date:              p.Date,
+		From:              p.From,
+		To:                p.To,
+		HasImage:          p.HasImage,
+		Collapse:          p.Collapse,
+		Entity:            p.Entity,
+		Sort:              p.Sort,
+	}
+}
@@ -0,0 +1,209 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"newsservice/internal/broker"
+	"newsservice/internal/dlq"
+	"newsservice/internal/domain"
+	"newsservice/internal/models"
+	"newsservice/internal/pagination"
+	"newsservice/internal/usecase"
+	"newsservice/storage"
+	"testing"
+	"time"
+)
+
+const (
+	requestsTopic   = "news_requests"
+	repliesTopic    = "news_replies"
+	deadLetterTopic = "news_dead_letter"
+)
+
+// fakeStorage — реализация storage.NewsStorage в памяти для тестов шлюза.
+type fakeStorage struct {
+	news map[int]models.NewsFullDetailed
+}
+
+func (f *fakeStorage) GetNewsCount(ctx context.Context, filter models.NewsFilter) (int, error) {
+	return len(f.news), nil
+}
+
+func (f *fakeStorage) GetDetailedNews(ctx context.Context, id int) (models.NewsFullDetailed, error) {
+	news, ok := f.news[id]
+	if !ok {
+		return models.NewsFullDetailed{}, fmt.Errorf("news with ID %d: %w", id, storage.ErrNotFound)
+	}
+	return news, nil
+}
+
+func (f *fakeStorage) GetNewsByFilter(ctx context.Context, filter models.NewsFilter) ([]models.NewsFullDetailed, error) {
+	list := make([]models.NewsFullDetailed, 0, len(f.news))
+	for _, n := range f.news {
+		list = append(list, n)
+	}
+	return list, nil
+}
+
+func (f *fakeStorage) SaveNews(ctx context.Context, feed *domain.Feed) (int, error) {
+	return 0, errors.New("not implemented")
+}
+
+func (f *fakeStorage) Close() {}
+
+// startGateway запускает шлюз поверх брокера в памяти.
+func startGateway(t *testing.T) *broker.Memory {
+	t.Helper()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	mem := broker.NewMemory(3)
+	db := &fakeStorage{news: map[int]models.NewsFullDetailed{
+		7: {NewsID: 7, Title: "Seven"},
+	}}
+	deadLetters := dlq.NewProcessor("kafka-gateway", deadLetterTopic, mem, 2, time.Millisecond, log)
+	gw := NewGateway(mem.Subscribe(requestsTopic, "api_gateway"), mem, usecase.NewNewsQueryUseCase(db, pagination.NewCursorCodec(nil)), repliesTopic, []string{"client-replies", "team.*"}, deadLetters, log)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		gw.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return mem
+}
+
+func request(t *testing.T, mem *broker.Memory, raw []byte) {
+	t.Helper()
+	if err := mem.Publish(context.Background(), requestsTopic, nil, raw); err != nil {
+		t.Fatalf("publish request: %v", err)
+	}
+}
+
+func awaitReply(t *testing.T, sub broker.Subscriber) Reply {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	msg, err := sub.Receive(ctx)
+	if err != nil {
+		t.Fatalf("await reply: %v", err)
+	}
+	var reply Reply
+	if err := json.Unmarshal(msg.Value, &reply); err != nil {
+		t.Fatalf("invalid reply %q: %v", msg.Value, err)
+	}
+	return reply
+}
+
+func encode(t *testing.T, v any) []byte {
+	t.Helper()
+	raw, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+func TestGatewayRequestReply(t *testing.T) {
+	mem := startGateway(t)
+	replies := mem.Subscribe("client-replies", "client")
+
+	request(t, mem, encode(t, Request{
+		Version:       EnvelopeVersion,
+		CorrelationID: "c-1",
+		ReplyTo:       "client-replies",
+		Operation:     OpNewsGet,
+		Params:        encode(t, GetParams{ID: 7}),
+	}))
+	reply := awaitReply(t, replies)
+	if reply.CorrelationID != "c-1" || reply.Status != StatusOK {
+		t.Fatalf("reply = %+v, want ok for c-1", reply)
+	}
+	var news models.NewsFullDetailed
+	if err := json.Unmarshal(encode(t, reply.Data), &news); err != nil || news.Title != "Seven" {
+		t.Fatalf("reply data = %v, want news 7", reply.Data)
+	}
+
+	request(t, mem, encode(t, Request{
+		Version:       EnvelopeVersion,
+		CorrelationID: "c-2",
+		ReplyTo:       "client-replies",
+		Operation:     OpNewsGet,
+		Params:        encode(t, GetParams{ID: 8}),
+	}))
+	reply = awaitReply(t, replies)
+	if reply.CorrelationID != "c-2" || reply.Error == nil || reply.Error.Code != CodeNotFound {
+		t.Fatalf("reply = %+v, want not_found for c-2", reply)
+	}
+	if lag := mem.Lag(requestsTopic, "api_gateway"); lag != 0 {
+		t.Fatalf("requests lag = %d, want 0", lag)
+	}
+}
+
+func TestGatewayRejectsUnlistedReplyTopic(t *testing.T) {
+	mem := startGateway(t)
+	replies := mem.Subscribe(repliesTopic, "client")
+	prefixed := mem.Subscribe("team.search", "client")
+
+	for i, replyTo := range []string{deadLetterTopic, "news_events", "team"} {
+		request(t, mem, encode(t, Request{
+			Version:       EnvelopeVersion,
+			CorrelationID: fmt.Sprintf("c-%d", i),
+			ReplyTo:       replyTo,
+			Operation:     OpNewsGet,
+			Params:        encode(t, GetParams{ID: 7}),
+		}))
+		if reply := awaitReply(t, replies); reply.CorrelationID != fmt.Sprintf("c-%d", i) {
+			t.Fatalf("reply to %s = %+v, want it on the default topic", replyTo, reply)
+		}
+	}
+	for _, topic := range []string{deadLetterTopic, "news_events", "team"} {
+		if msgs := mem.Messages(topic); len(msgs) != 0 {
+			t.Fatalf("reply published to %s: %s", topic, msgs[0].Value)
+		}
+	}
+
+	request(t, mem, encode(t, Request{
+		Version:       EnvelopeVersion,
+		CorrelationID: "c-prefix",
+		ReplyTo:       "team.search",
+		Operation:     OpNewsGet,
+		Params:        encode(t, GetParams{ID: 7}),
+	}))
+	if reply := awaitReply(t, prefixed); reply.CorrelationID != "c-prefix" {
+		t.Fatalf("reply = %+v, want it on team.search", reply)
+	}
+}
+
+func TestGatewayMovesMalformedRequestToDeadLetter(t *testing.T) {
+	mem := startGateway(t)
+	replies := mem.Subscribe(repliesTopic, "client")
+	deadLetters := mem.Subscribe(deadLetterTopic, "ops")
+
+	request(t, mem, []byte(`{"version":`))
+
+	reply := awaitReply(t, replies)
+	if reply.Error == nil || reply.Error.Code != CodeBadRequest {
+		t.Fatalf("reply = %+v, want bad_request", reply)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	msg, err := deadLetters.Receive(ctx)
+	if err != nil {
+		t.Fatalf("await dead letter: %v", err)
+	}
+	var dead dlq.Message
+	if err := json.Unmarshal(msg.Value, &dead); err != nil {
+		t.Fatalf("invalid dead letter: %v", err)
+	}
+	if string(dead.Payload) != `{"version":` || dead.OriginalTopic != requestsTopic || !dead.Permanent || dead.Attempts != 1 {
+		t.Fatalf("dead letter = %+v", dead)
+	}
+}
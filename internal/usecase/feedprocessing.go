@@ -4,16 +4,31 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"newsservice/internal/domain"
 	"strings"
+	"sync"
 	"time"
 )
 
+// FeedStats — статистика последней обработки фида.
+type FeedStats struct {
+	Found      int           `json:"found"`
+	Filtered   int           `json:"filtered"`
+	Saved      int           `json:"saved"`
+	Duration   time.Duration `json:"duration"`
+	FinishedAt time.Time     `json:"finished_at"`
+}
+
 type FeedProcessingUseCase struct {
 	fetcher   FeedFetcher
 	parser    FeedParser
 	storage   FeedStorage
 	log       *slog.Logger
 	feedNames map[string]string
+	stages    []FeedStage
+
+	statsMu sync.RWMutex
+	stats   map[string]FeedStats
 }
 
 func NewFeedProsessingUseCase(
@@ -22,6 +37,7 @@ func NewFeedProsessingUseCase(
 	storage FeedStorage,
 	log *slog.Logger,
 	feedNames map[string]string,
+	stages ...FeedStage,
 ) *FeedProcessingUseCase {
 	return &FeedProcessingUseCase{
 		fetcher:   fetcher,
@@ -29,13 +45,25 @@ func NewFeedProsessingUseCase(
 		storage:   storage,
 		log:       log,
 		feedNames: feedNames,
+		stages:    stages,
+		stats:     make(map[string]FeedStats),
 	}
 }
 
 // ProcessFeed выполняет полный цикл: получение, парсинг и сохранение фида.
 func (uc *FeedProcessingUseCase) ProcessFeed(ctx context.Context, url string) error {
+	return uc.ProcessSource(ctx, domain.Source{URL: url})
+}
+
+// ProcessSource выполняет полный цикл для фида из реестра источников.
+// Пустое имя источника заменяется именем из конфигурации или доменом URL.
+func (uc *FeedProcessingUseCase) ProcessSource(ctx context.Context, source domain.Source) error {
 	start := time.Now()
-	feedName := uc.extractFeedName(url)
+	url := source.URL
+	feedName := source.Name
+	if feedName == "" {
+		feedName = uc.extractFeedName(url)
+	}
 	log := uc.log.With(
 		slog.String("component", "feed-processor"),
 		slog.String("feed", feedName),
@@ -68,23 +96,83 @@ func (uc *FeedProcessingUseCase) ProcessFeed(ctx context.Context, url string) er
 		slog.String("stage", "parse"),
 		slog.Int("items_parsed", len(feed.Items)),
 	)
-	savedCount, err := uc.storage.SaveNews(ctx, feed)
+	feed.Source = feedName
+	found := len(feed.Items)
+
+	savedCount, err := uc.process(ctx, feed, log)
 	if err != nil {
-		log.Error("Feed save failed",
-			slog.String("stage", "save"),
-			slog.Any("error", err),
-		)
-		return fmt.Errorf("save failed for %s: %w", feedName, err)
+		return err
 	}
 	duration := time.Since(start)
+	stats := FeedStats{
+		Found:      found,
+		Filtered:   found - len(feed.Items),
+		Saved:      savedCount,
+		Duration:   duration,
+		FinishedAt: time.Now(),
+	}
+	uc.statsMu.Lock()
+	uc.stats[feedName] = stats
+	uc.statsMu.Unlock()
 	log.Info("Feed proessing completed successfully",
-		slog.Int("items_found", len(feed.Items)),
-		slog.Int("items_saved", savedCount),
+		slog.Int("items_found", stats.Found),
+		slog.Int("items_filtered", stats.Filtered),
+		slog.Int("items_saved", stats.Saved),
 		slog.Duration("duration", duration),
 	)
 	return nil
 }
 
+// ProcessItems прогоняет уже разобранные элементы через этапы обработки и
+// сохраняет их. feed.Source должен быть заполнен.
+func (uc *FeedProcessingUseCase) ProcessItems(ctx context.Context, feed *domain.Feed) (int, error) {
+	log := uc.log.With(
+		slog.String("component", "feed-processor"),
+		slog.String("feed", feed.Source),
+	)
+	return uc.process(ctx, feed, log)
+}
+
+// process выполняет этапы обработки и сохраняет фид.
+func (uc *FeedProcessingUseCase) process(ctx context.Context, feed *domain.Feed, log *slog.Logger) (int, error) {
+	for _, stage := range uc.stages {
+		if err := stage.Process(ctx, feed); err != nil {
+			log.Error("Feed stage failed",
+				slog.String("stage", stage.Name()),
+				slog.Any("error", err),
+			)
+			return 0, fmt.Errorf("%s failed for %s: %w", stage.Name(), feed.Source, err)
+		}
+		log.Debug("Feed stage completed", slog.String("stage", stage.Name()))
+	}
+
+	savedCount, err := uc.storage.SaveNews(ctx, feed)
+	if err != nil {
+		log.Error("Feed save failed",
+			slog.String("stage", "save"),
+			slog.Any("error", err),
+		)
+		return 0, fmt.Errorf("save failed for %s: %w", feed.Source, err)
+	}
+	for _, stage := range uc.stages {
+		if observer, ok := stage.(FeedSaveObserver); ok {
+			observer.FeedSaved(ctx, feed)
+		}
+	}
+	return savedCount, nil
+}
+
+// Stats возвращает статистику последней успешной обработки каждого фида.
+func (uc *FeedProcessingUseCase) Stats() map[string]FeedStats {
+	uc.statsMu.RLock()
+	defer uc.statsMu.RUnlock()
+	stats := make(map[string]FeedStats, len(uc.stats))
+	for name, s := range uc.stats {
+		stats[name] = s
+	}
+	return stats
+}
+
 // extractFeedName извлекает читаемое имя фида из URL
 func (uc *FeedProcessingUseCase) extractFeedName(url string) string {
 	if name, ok := uc.feedNames[url]; ok {
@@ -20,3 +20,15 @@ type FeedParser interface {
 type FeedStorage interface {
 	SaveNews(ctx context.Context, feed *domain.Feed) (int, error)
 }
+
+// FeedStage — интерфейс этапа обработки фида между парсингом и сохранением.
+type FeedStage interface {
+	Name() string
+	Process(ctx context.Context, feed *domain.Feed) error
+}
+
+// FeedSaveObserver — необязательный интерфейс этапа, которому нужен результат
+// сохранения фида: после SaveNews у элементов заполнены ID и Status.
+type FeedSaveObserver interface {
+	FeedSaved(ctx context.Context, feed *domain.Feed)
+}
@@ -0,0 +1,308 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"newsservice/internal/langdetect"
+	"newsservice/internal/models"
+	"newsservice/internal/pagination"
+	"newsservice/storage"
+	"time"
+)
+
+const (
+	// MaxPageSize ограничивает количество новостей на странице.
+	MaxPageSize = 100
+	// MinSearchLength — минимальная длина поискового запроса в символах.
+	MinSearchLength = 2
+	// MaxFilterValues ограничивает число значений в одном фильтре-списке.
+	MaxFilterValues = 50
+)
+
+// ErrInvalidQuery возвращается при некорректных параметрах запроса.
+var ErrInvalidQuery = errors.New("invalid query")
+
+// ListQuery — параметры списка новостей, общие для HTTP и Kafka.
+// Нулевые Page и Limit заменяются значениями по умолчанию. Cursor — токен
+// позиции для вывода по курсору; пустая строка запрашивает первую страницу.
+// Date — один день публикации, From и To — границы периода в формате RFC 3339
+// или YYYY-MM-DD; день в To включается целиком.
+type ListQuery struct {
+	Page              int
+	Limit             int
+	Cursor            *string
+	Sources           []string
+	ExcludeSources    []string
+	Categories        []string
+	ExcludeCategories []string
+	Tags              []string
+	TagsMatch         string
+	ExcludeTags       []string
+	Languages         []string
+	Author            string
+	Date              string
+	From              string
+	To                string
+	HasImage          *bool
+	Collapse          string
+	Entity            string
+	Sort              string
+}
+
+// NewsQueryUseCase — чтение новостей для внешних API: списки с фильтрами,
+// отдельная новость и поиск по тексту.
+type NewsQueryUseCase struct {
+	storage storage.NewsStorage
+	cursors *pagination.CursorCodec
+}
+
+func NewNewsQueryUseCase(storage storage.NewsStorage, cursors *pagination.CursorCodec) *NewsQueryUseCase {
+	return &NewsQueryUseCase{storage: storage, cursors: cursors}
+}
+
+// ListNews возвращает страницу новостей, удовлетворяющих параметрам запроса.
+func (uc *NewsQueryUseCase) ListNews(ctx context.Context, q ListQuery) (*pagination.Pagination, error) {
+	filter, err := q.filter()
+	if err != nil {
+		return nil, err
+	}
+	if err := requireSearchForRelevance(filter); err != nil {
+		return nil, err
+	}
+	return uc.list(ctx, filter, q.Page, q.Limit)
+}
+
+// ListNewsByCursor возвращает страницу новостей после позиции q.Cursor без
+// подсчета общего числа новостей.
+func (uc *NewsQueryUseCase) ListNewsByCursor(ctx context.Context, q ListQuery) (*pagination.CursorPage, error) {
+	filter, err := q.filter()
+	if err != nil {
+		return nil, err
+	}
+	if err := requireSearchForRelevance(filter); err != nil {
+		return nil, err
+	}
+	return uc.listByCursor(ctx, filter, q)
+}
+
+// GetNews возвращает новость по идентификатору; storage.ErrNotFound, если ее нет.
+func (uc *NewsQueryUseCase) GetNews(ctx context.Context, newsID int) (models.NewsFullDetailed, error) {
+	if newsID <= 0 {
+		return models.NewsFullDetailed{}, fmt.Errorf("%w: news id must be positive", ErrInvalidQuery)
+	}
+	return uc.storage.GetDetailedNews(ctx, newsID)
+}
+
+// SearchNews возвращает страницу новостей, содержащих text в заголовке или тексте.
+func (uc *NewsQueryUseCase) SearchNews(ctx context.Context, text string, q ListQuery) (*pagination.Pagination, error) {
+	if len([]rune(text)) < MinSearchLength {
+		return nil, fmt.Errorf("%w: q parameter must be at least %d characters", ErrInvalidQuery, MinSearchLength)
+	}
+	filter, err := q.filter()
+	if err != nil {
+		return nil, err
+	}
+	filter.Query = text
+	return uc.list(ctx, filter, q.Page, q.Limit)
+}
+
+// SearchNewsByCursor — SearchNews с выводом по курсору.
+func (uc *NewsQueryUseCase) SearchNewsByCursor(ctx context.Context, text string, q ListQuery) (*pagination.CursorPage, error) {
+	if len([]rune(text)) < MinSearchLength {
+		return nil, fmt.Errorf("%w: q parameter must be at least %d characters", ErrInvalidQuery, MinSearchLength)
+	}
+	filter, err := q.filter()
+	if err != nil {
+		return nil, err
+	}
+	filter.Query = text
+	return uc.listByCursor(ctx, filter, q)
+}
+
+func (uc *NewsQueryUseCase) list(ctx context.Context, filter models.NewsFilter, page, perPage int) (*pagination.Pagination, error) {
+	total, err := uc.storage.GetNewsCount(ctx, filter)
+	if err != nil {
+		return nil, storageError(err, "failed to count news")
+	}
+
+	paginator := pagination.NewWithLimit(total, page, perPage)
+	if err := paginator.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidQuery, err)
+	}
+
+	filter.Limit = paginator.GetLimit()
+	filter.Offset = paginator.GetOffset()
+	news, err := uc.storage.GetNewsByFilter(ctx, filter)
+	if err != nil {
+		return nil, storageError(err, "failed to get news")
+	}
+	if news == nil {
+		news = []models.NewsFullDetailed{}
+	}
+	paginator.SetResults(news)
+	return paginator, nil
+}
+
+func (uc *NewsQueryUseCase) listByCursor(ctx context.Context, filter models.NewsFilter, q ListQuery) (*pagination.CursorPage, error) {
+	if q.Cursor == nil {
+		return nil, fmt.Errorf("%w: cursor parameter is required", ErrInvalidQuery)
+	}
+	if q.Page > 1 {
+		return nil, fmt.Errorf("%w: page and cursor parameters are mutually exclusive", ErrInvalidQuery)
+	}
+
+	order, err := keysetOrder(filter.Sort)
+	if err != nil {
+		return nil, err
+	}
+	var cur *pagination.Cursor
+	if *q.Cursor != "" {
+		decoded, err := uc.cursors.Decode(*q.Cursor)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidQuery, err)
+		}
+		if decoded.Order != order {
+			return nil, fmt.Errorf("%w: cursor was issued for another sort order", ErrInvalidQuery)
+		}
+		cur = &decoded
+		filter.Keyset = decoded.Keyset()
+	} else {
+		filter.Keyset = &models.Keyset{Order: order}
+	}
+
+	// Новость сверх страницы показывает, есть ли следующая страница.
+	filter.Limit = q.Limit + 1
+	news, err := uc.storage.GetNewsByFilter(ctx, filter)
+	if err != nil {
+		return nil, storageError(err, "failed to get news")
+	}
+	return pagination.NewCursorPage(uc.cursors, news, q.Limit, order, cur), nil
+}
+
+// requireSearchForRelevance отклоняет сортировку по релевантности без поискового запроса.
+func requireSearchForRelevance(filter models.NewsFilter) error {
+	for _, f := range filter.Sort {
+		if f.Field == models.SortRelevance && filter.Query == "" {
+			return fmt.Errorf("%w: sort by relevance requires a search query", ErrInvalidQuery)
+		}
+	}
+	return nil
+}
+
+// filter проверяет параметры и строит фильтр хранилища.
+func (q *ListQuery) filter() (models.NewsFilter, error) {
+	if q.Page < 0 {
+		return models.NewsFilter{}, fmt.Errorf("%w: page parameter must be a positive integer", ErrInvalidQuery)
+	}
+	if q.Page == 0 {
+		q.Page = 1
+	}
+	if q.Limit < 0 || q.Limit > MaxPageSize {
+		return models.NewsFilter{}, fmt.Errorf("%w: limit parameter must be between 1 and %d", ErrInvalidQuery, MaxPageSize)
+	}
+	if q.Limit == 0 {
+		q.Limit = pagination.NEWS_PER_PAGE
+	}
+
+	sort, err := parseSort(q.Sort)
+	if err != nil {
+		return models.NewsFilter{}, err
+	}
+	filter := models.NewsFilter{
+		Author: q.Author,
+		Entity: q.Entity,
+		Sort:   sort,
+	}
+	for _, set := range []struct {
+		name   string
+		values []string
+		dst    *[]string
+	}{
+		{"source", q.Sources, &filter.Sources},
+		{"exclude_source", q.ExcludeSources, &filter.ExcludeSources},
+		{"category", q.Categories, &filter.Categories},
+		{"exclude_category", q.ExcludeCategories, &filter.ExcludeCategories},
+		{"tag", q.Tags, &filter.Tags},
+		{"exclude_tag", q.ExcludeTags, &filter.ExcludeTags},
+	} {
+		if len(set.values) > MaxFilterValues {
+			return models.NewsFilter{}, fmt.Errorf("%w: %s parameter accepts at most %d values", ErrInvalidQuery, set.name, MaxFilterValues)
+		}
+		*set.dst = set.values
+	}
+	switch q.TagsMatch {
+	case "", models.TagsMatchAny, models.TagsMatchAll:
+		filter.TagsMatch = q.TagsMatch
+	default:
+		return models.NewsFilter{}, fmt.Errorf("%w: tags_match parameter must be any or all", ErrInvalidQuery)
+	}
+	if len(q.Languages) > MaxFilterValues {
+		return models.NewsFilter{}, fmt.Errorf("%w: lang parameter accepts at most %d values", ErrInvalidQuery, MaxFilterValues)
+	}
+	for _, lang := range q.Languages {
+		code := langdetect.Normalize(lang)
+		if code == "" {
+			return models.NewsFilter{}, fmt.Errorf("%w: invalid lang parameter %q", ErrInvalidQuery, lang)
+		}
+		filter.Languages = append(filter.Languages, code)
+	}
+	filter.HasImage = q.HasImage
+
+	if q.Date != "" {
+		if q.From != "" || q.To != "" {
+			return models.NewsFilter{}, fmt.Errorf("%w: date parameter cannot be combined with from or to", ErrInvalidQuery)
+		}
+		date, err := time.Parse("2006-01-02", q.Date)
+		if err != nil {
+			return models.NewsFilter{}, fmt.Errorf("%w: invalid date format, expected YYYY-MM-DD", ErrInvalidQuery)
+		}
+		filter.From, filter.To = date, date.AddDate(0, 0, 1)
+	}
+	if q.From != "" {
+		if filter.From, err = parseTimeBound(q.From, "from", false); err != nil {
+			return models.NewsFilter{}, err
+		}
+	}
+	if q.To != "" {
+		if filter.To, err = parseTimeBound(q.To, "to", true); err != nil {
+			return models.NewsFilter{}, err
+		}
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return models.NewsFilter{}, fmt.Errorf("%w: from must be earlier than to", ErrInvalidQuery)
+	}
+
+	switch q.Collapse {
+	case models.CollapseNone, models.CollapseEarliest, models.CollapsePrimary:
+		filter.Collapse = q.Collapse
+	default:
+		return models.NewsFilter{}, fmt.Errorf("%w: invalid collapse parameter", ErrInvalidQuery)
+	}
+	return filter, nil
+}
+
+// parseTimeBound разбирает границу периода в формате RFC 3339 или YYYY-MM-DD.
+// Дата без времени в верхней границе включает весь день.
+func parseTimeBound(raw, name string, upper bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t.UTC(), nil
+	}
+	date, err := time.Parse("2006-01-02", raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: invalid %s format, expected RFC 3339 timestamp or YYYY-MM-DD", ErrInvalidQuery, name)
+	}
+	if upper {
+		date = date.AddDate(0, 0, 1)
+	}
+	return date, nil
+}
+
+// storageError оборачивает ошибку хранилища. Неподдерживаемый хранилищем
+// параметр фильтра считается ошибкой запроса.
+func storageError(err error, message string) error {
+	if errors.Is(err, storage.ErrUnsupported) {
+		return fmt.Errorf("%w: %v", ErrInvalidQuery, err)
+	}
+	return fmt.Errorf("%s: %w", message, err)
+}
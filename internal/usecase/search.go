@@ -0,0 +1,103 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"newsservice/internal/langdetect"
+	"newsservice/internal/models"
+	"newsservice/internal/pagination"
+	"newsservice/internal/search"
+	"time"
+)
+
+// SearchQuery — параметры полнотекстового поиска. Date — день публикации в
+// формате YYYY-MM-DD; нулевые Page и Limit заменяются значениями по умолчанию.
+type SearchQuery struct {
+	Text     string
+	Page     int
+	Limit    int
+	Source   string
+	Category string
+	Language string
+	Author   string
+	Date     string
+	Facets   bool
+}
+
+// SearchPage — страница результатов полнотекстового поиска.
+type SearchPage struct {
+	*pagination.Pagination
+	Results []models.SearchResult `json:"results"`
+	Query   string                `json:"query"`
+	Facets  *search.Facets        `json:"facets,omitempty"`
+}
+
+// SearchUseCase — полнотекстовый поиск новостей с ранжированием.
+type SearchUseCase struct {
+	searcher search.Searcher
+}
+
+func NewSearchUseCase(searcher search.Searcher) *SearchUseCase {
+	return &SearchUseCase{searcher: searcher}
+}
+
+// Search разбирает запрос и возвращает страницу найденных новостей,
+// упорядоченных по релевантности.
+func (uc *SearchUseCase) Search(ctx context.Context, q SearchQuery) (*SearchPage, error) {
+	query, err := search.ParseQuery(q.Text)
+	if errors.Is(err, search.ErrInvalidQuery) {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidQuery, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if q.Page < 0 {
+		return nil, fmt.Errorf("%w: page parameter must be a positive integer", ErrInvalidQuery)
+	}
+	if q.Page == 0 {
+		q.Page = 1
+	}
+	if q.Limit < 0 || q.Limit > MaxPageSize {
+		return nil, fmt.Errorf("%w: limit parameter must be between 1 and %d", ErrInvalidQuery, MaxPageSize)
+	}
+	if q.Limit == 0 {
+		q.Limit = pagination.NEWS_PER_PAGE
+	}
+
+	filter := search.Filter{
+		Source:   q.Source,
+		Category: q.Category,
+		Author:   q.Author,
+	}
+	if q.Language != "" {
+		filter.Language = langdetect.Normalize(q.Language)
+		if filter.Language == "" {
+			return nil, fmt.Errorf("%w: invalid lang parameter", ErrInvalidQuery)
+		}
+	}
+	if q.Date != "" {
+		date, err := time.Parse("2006-01-02", q.Date)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid date format, expected YYYY-MM-DD", ErrInvalidQuery)
+		}
+		filter.From, filter.To = date, date.AddDate(0, 0, 1)
+	}
+
+	result, err := uc.searcher.Search(ctx, search.Request{
+		Query:  query,
+		Filter: filter,
+		Limit:  q.Limit,
+		Offset: (q.Page - 1) * q.Limit,
+		Facets: q.Facets,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &SearchPage{
+		Pagination: pagination.NewWithLimit(result.Total, q.Page, q.Limit),
+		Results:    result.Hits,
+		Query:      query.String(),
+		Facets:     result.Facets,
+	}, nil
+}
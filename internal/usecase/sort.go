@@ -0,0 +1,46 @@
+package usecase
+
+import (
+	"fmt"
+	"newsservice/internal/models"
+	"slices"
+	"strings"
+)
+
+// parseSort разбирает спецификацию сортировки вида "-published_at,title":
+// поля через запятую, минус перед полем задает убывание.
+func parseSort(raw string) ([]models.SortField, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	var sort []models.SortField
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		field := models.SortField{Field: part, Order: models.SortAsc}
+		if name, ok := strings.CutPrefix(part, "-"); ok {
+			field = models.SortField{Field: name, Order: models.SortDesc}
+		}
+		if !slices.Contains(models.SortFields, field.Field) {
+			return nil, fmt.Errorf("%w: invalid sort field %q, allowed fields: %s",
+				ErrInvalidQuery, field.Field, strings.Join(models.SortFields, ", "))
+		}
+		if slices.ContainsFunc(sort, func(f models.SortField) bool { return f.Field == field.Field }) {
+			return nil, fmt.Errorf("%w: duplicate sort field %q", ErrInvalidQuery, field.Field)
+		}
+		sort = append(sort, field)
+	}
+	return sort, nil
+}
+
+// keysetOrder возвращает направление вывода по курсору. Курсор хранит только
+// дату публикации и id, поэтому допускается лишь сортировка по published_at.
+func keysetOrder(sort []models.SortField) (string, error) {
+	switch {
+	case len(sort) == 0:
+		return models.SortDesc, nil
+	case len(sort) == 1 && sort[0].Field == models.SortPublishedAt:
+		return sort[0].Order, nil
+	default:
+		return "", fmt.Errorf("%w: cursor paging supports only sort=published_at or sort=-published_at", ErrInvalidQuery)
+	}
+}
@@ -0,0 +1,195 @@
+package usecase
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"newsservice/internal/langdetect"
+	"newsservice/internal/models"
+	"newsservice/internal/tagging"
+	"newsservice/storage"
+	"slices"
+)
+
+const (
+	// DefaultTagsLimit — число тегов в списке по умолчанию.
+	DefaultTagsLimit = 50
+	// MaxMergeTags ограничивает число тегов, объединяемых за один запрос.
+	MaxMergeTags = 50
+)
+
+// defaultCategoryLanguage — язык названия категории, если названия на
+// запрошенном языке нет.
+const defaultCategoryLanguage = "en"
+
+// TaxonomyUseCase — просмотр и администрирование справочников тегов и категорий.
+type TaxonomyUseCase struct {
+	storage storage.TaxonomyStorage
+	news    *NewsQueryUseCase
+}
+
+func NewTaxonomyUseCase(storage storage.TaxonomyStorage, news *NewsQueryUseCase) *TaxonomyUseCase {
+	return &TaxonomyUseCase{storage: storage, news: news}
+}
+
+// ListTags возвращает теги, slug которых начинается с prefix, начиная с самых частых.
+func (uc *TaxonomyUseCase) ListTags(ctx context.Context, prefix string, limit int) ([]models.Tag, error) {
+	if limit < 0 || limit > MaxPageSize {
+		return nil, fmt.Errorf("%w: limit parameter must be between 1 and %d", ErrInvalidQuery, MaxPageSize)
+	}
+	if limit == 0 {
+		limit = DefaultTagsLimit
+	}
+	tags, err := uc.storage.ListTags(ctx, tagging.NormalizeSlug(prefix), limit)
+	if err != nil {
+		return nil, err
+	}
+	if tags == nil {
+		tags = []models.Tag{}
+	}
+	return tags, nil
+}
+
+// NewsByTag возвращает страницу новостей с тегом slug, в том числе по прежнему
+// slug тега; storage.ErrNotFound, если тега нет. Остальные параметры q
+// применяются как в списке новостей.
+func (uc *TaxonomyUseCase) NewsByTag(ctx context.Context, slug string, q ListQuery) (any, error) {
+	tag, err := uc.storage.GetTag(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	q.Tags = append(q.Tags, tag.Slug)
+	q.TagsMatch = models.TagsMatchAll
+	if q.Cursor != nil {
+		return uc.news.ListNewsByCursor(ctx, q)
+	}
+	return uc.news.ListNews(ctx, q)
+}
+
+// RenameTag меняет slug и название тега. Пустой newSlug оставляет slug
+// прежним, пустое name заменяется slug.
+func (uc *TaxonomyUseCase) RenameTag(ctx context.Context, slug, newSlug, name string) (models.Tag, error) {
+	if newSlug == "" {
+		newSlug = slug
+	}
+	newSlug = tagging.NormalizeSlug(newSlug)
+	if newSlug == "" {
+		return models.Tag{}, fmt.Errorf("%w: tag slug must not be empty", ErrInvalidQuery)
+	}
+	if name == "" {
+		name = newSlug
+	}
+	return uc.storage.RenameTag(ctx, slug, newSlug, name)
+}
+
+// MergeTags переносит новости тегов from на тег into и удаляет теги from.
+func (uc *TaxonomyUseCase) MergeTags(ctx context.Context, from []string, into string) (models.Tag, error) {
+	if into == "" || len(from) == 0 {
+		return models.Tag{}, fmt.Errorf("%w: from and into are required", ErrInvalidQuery)
+	}
+	if len(from) > MaxMergeTags {
+		return models.Tag{}, fmt.Errorf("%w: at most %d tags can be merged at once", ErrInvalidQuery, MaxMergeTags)
+	}
+	return uc.storage.MergeTags(ctx, from, into)
+}
+
+// Categories возвращает дерево категорий с названиями на языке lang.
+func (uc *TaxonomyUseCase) Categories(ctx context.Context, lang string) ([]models.Category, error) {
+	if lang != "" {
+		lang = langdetect.Normalize(lang)
+		if lang == "" {
+			return nil, fmt.Errorf("%w: invalid lang parameter", ErrInvalidQuery)
+		}
+	}
+	categories, err := uc.storage.ListCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range categories {
+		categories[i].Name = categoryName(categories[i], lang)
+	}
+	return categoryTree(categories), nil
+}
+
+// SaveCategory создает или изменяет категорию. Родитель должен существовать
+// и не может быть самой категорией или ее потомком.
+func (uc *TaxonomyUseCase) SaveCategory(ctx context.Context, category models.Category) error {
+	category.Slug = tagging.NormalizeSlug(category.Slug)
+	category.Parent = tagging.NormalizeSlug(category.Parent)
+	if category.Slug == "" {
+		return fmt.Errorf("%w: category slug must not be empty", ErrInvalidQuery)
+	}
+	names := make(map[string]string, len(category.Names))
+	for lang, name := range category.Names {
+		code := langdetect.Normalize(lang)
+		if code == "" || name == "" {
+			return fmt.Errorf("%w: invalid category name for language %q", ErrInvalidQuery, lang)
+		}
+		names[code] = name
+	}
+	category.Names = names
+
+	if category.Parent != "" {
+		existing, err := uc.storage.ListCategories(ctx)
+		if err != nil {
+			return err
+		}
+		parents := make(map[string]string, len(existing))
+		for _, c := range existing {
+			parents[c.Slug] = c.Parent
+		}
+		if _, ok := parents[category.Parent]; !ok {
+			return fmt.Errorf("parent category %q: %w", category.Parent, storage.ErrNotFound)
+		}
+		for p := category.Parent; p != ""; p = parents[p] {
+			if p == category.Slug {
+				return fmt.Errorf("%w: category %q cannot be nested in itself", ErrInvalidQuery, category.Slug)
+			}
+		}
+	}
+	return uc.storage.SaveCategory(ctx, category)
+}
+
+// categoryName выбирает название категории на языке lang, затем на языке по
+// умолчанию; без названий возвращается slug.
+func categoryName(c models.Category, lang string) string {
+	if name, ok := c.Names[lang]; ok {
+		return name
+	}
+	if name, ok := c.Names[defaultCategoryLanguage]; ok {
+		return name
+	}
+	return c.Slug
+}
+
+// categoryTree собирает категории в дерево. Категории с неизвестным родителем
+// выводятся на верхнем уровне.
+func categoryTree(categories []models.Category) []models.Category {
+	children := make(map[string][]models.Category)
+	known := make(map[string]bool, len(categories))
+	for _, c := range categories {
+		known[c.Slug] = true
+	}
+	var roots []models.Category
+	for _, c := range categories {
+		if c.Parent == "" || !known[c.Parent] {
+			roots = append(roots, c)
+		} else {
+			children[c.Parent] = append(children[c.Parent], c)
+		}
+	}
+	var attach func(nodes []models.Category) []models.Category
+	attach = func(nodes []models.Category) []models.Category {
+		slices.SortFunc(nodes, func(a, b models.Category) int { return cmp.Compare(a.Slug, b.Slug) })
+		for i := range nodes {
+			if kids, ok := children[nodes[i].Slug]; ok {
+				nodes[i].Children = attach(kids)
+			}
+		}
+		return nodes
+	}
+	if roots == nil {
+		return []models.Category{}
+	}
+	return attach(roots)
+}
@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"newsservice/internal/langdetect"
+	"newsservice/internal/models"
+)
+
+var _ AdminStorage = (*Storage)(nil)
+
+// Метод для подсчета распределения новостей каждого источника по языкам
+func (s *Storage) GetLanguageStats(ctx context.Context) ([]models.SourceLanguageStats, error) {
+	query := `
+	SELECT source, language, COUNT(*)
+	FROM news
+	GROUP BY source, language
+	ORDER BY source, language;`
+
+	rows, err := s.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query language stats: %w", err)
+	}
+	defer rows.Close()
+
+	stats := []models.SourceLanguageStats{}
+	for rows.Next() {
+		var source, language string
+		var count int
+		if err := rows.Scan(&source, &language, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan language stats row: %w", err)
+		}
+		stats = addLanguageStats(stats, source, language, count)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return stats, nil
+}
+
+// addLanguageStats добавляет к статистике число новостей источника на языке.
+// Строки должны быть упорядочены по источнику.
+func addLanguageStats(stats []models.SourceLanguageStats, source, language string, count int) []models.SourceLanguageStats {
+	if language == "" {
+		language = langdetect.Undetermined
+	}
+	if len(stats) == 0 || stats[len(stats)-1].Source != source {
+		stats = append(stats, models.SourceLanguageStats{
+			Source:    source,
+			Languages: make(map[string]int),
+		})
+	}
+	current := &stats[len(stats)-1]
+	current.Languages[language] += count
+	current.Total += count
+	return stats
+}
@@ -0,0 +1,214 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"newsservice/internal/dedup"
+	"newsservice/internal/models"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	defaultClusterWindow      = 48 * time.Hour
+	defaultClusterMaxDistance = 3
+)
+
+// clusterItem — новость-кандидат для группировки в сюжет.
+type clusterItem struct {
+	id          int64
+	fingerprint uint64
+	publishedAt time.Time
+	clusterID   int64
+}
+
+// nullableFingerprint переводит отпечаток в значение колонки simhash (NULL для пустого отпечатка).
+func nullableFingerprint(fp uint64) *int64 {
+	if fp == 0 {
+		return nil
+	}
+	v := int64(fp)
+	return &v
+}
+
+// clusterNews относит только что сохраненные новости к сюжетам: новость
+// попадает в сюжет ближайшей по отпечатку новости, опубликованной в пределах
+// окна от нее. Кандидаты выбираются отдельно для каждой новости.
+func (s *Storage) clusterNews(ctx context.Context, tx pgx.Tx, items []clusterItem) error {
+	for _, item := range items {
+		candidates, err := s.clusterCandidates(ctx, tx, item)
+		if err != nil {
+			return err
+		}
+		fingerprints := make([]uint64, len(candidates))
+		for i, c := range candidates {
+			fingerprints[i] = c.fingerprint
+		}
+		best := dedup.Nearest(item.fingerprint, fingerprints, s.clusterMaxDistance)
+		if best < 0 {
+			continue
+		}
+
+		match := candidates[best]
+		if match.clusterID == 0 {
+			err := tx.QueryRow(ctx, `
+			INSERT INTO story_clusters (primary_news_id, earliest_news_id)
+			VALUES ($1, $1)
+			RETURNING id;`, match.id,
+			).Scan(&match.clusterID)
+			if err != nil {
+				return fmt.Errorf("failed to create story cluster: %w", err)
+			}
+			if _, err := tx.Exec(ctx, `UPDATE news SET cluster_id = $1 WHERE id = $2;`, match.clusterID, match.id); err != nil {
+				return fmt.Errorf("failed to assign story cluster: %w", err)
+			}
+		}
+		if _, err := tx.Exec(ctx, `UPDATE news SET cluster_id = $1 WHERE id = $2;`, match.clusterID, item.id); err != nil {
+			return fmt.Errorf("failed to assign story cluster: %w", err)
+		}
+	}
+	return nil
+}
+
+// clusterCandidates выбирает новости с отпечатком, опубликованные в пределах
+// окна от item. Если допустимое расстояние меньше числа полос отпечатка,
+// кандидаты ищутся по индексу полос.
+func (s *Storage) clusterCandidates(ctx context.Context, tx pgx.Tx, item clusterItem) ([]clusterItem, error) {
+	query := `
+	SELECT id, simhash, COALESCE(cluster_id, 0), published_at
+	FROM news
+	WHERE id <> $1 AND simhash IS NOT NULL AND published_at BETWEEN $2 AND $3`
+	args := []any{item.id, item.publishedAt.Add(-s.clusterWindow), item.publishedAt.Add(s.clusterWindow)}
+	if s.clusterMaxDistance < dedup.Bands {
+		bands := make([]string, dedup.Bands)
+		for i := range bands {
+			args = append(args, dedup.Band(item.fingerprint, i))
+			bands[i] = fmt.Sprintf("((simhash >> %d) & 65535) = $%d", 16*i, len(args))
+		}
+		query += " AND (" + strings.Join(bands, " OR ") + ")"
+	}
+	query += " ORDER BY published_at, id;"
+
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cluster candidates: %w", err)
+	}
+	defer rows.Close()
+
+	var candidates []clusterItem
+	for rows.Next() {
+		var c clusterItem
+		var fp int64
+		if err := rows.Scan(&c.id, &fp, &c.clusterID, &c.publishedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan cluster candidate: %w", err)
+		}
+		c.fingerprint = uint64(fp)
+		candidates = append(candidates, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("cluster candidates iteration error: %w", err)
+	}
+	return candidates, nil
+}
+
+// refreshClusters пересчитывает самую раннюю и основную новости сюжетов, в
+// которые входят новости newsIDs. Основная новость — самая полная версия
+// сюжета: с самым длинным текстом, а при равной длине самая ранняя.
+func refreshClusters(ctx context.Context, tx pgx.Tx, newsIDs []int64) error {
+	if len(newsIDs) == 0 {
+		return nil
+	}
+	_, err := tx.Exec(ctx, `
+	UPDATE story_clusters c SET
+		earliest_news_id = (
+			SELECT n.id FROM news n WHERE n.cluster_id = c.id
+			ORDER BY n.published_at, n.id
+			LIMIT 1
+		),
+		primary_news_id = (
+			SELECT n.id FROM news n WHERE n.cluster_id = c.id
+			ORDER BY length(COALESCE(NULLIF(n.content_text, ''), n.description_text, '')) DESC, n.published_at, n.id
+			LIMIT 1
+		),
+		updated_at = now()
+	WHERE c.id IN (SELECT cluster_id FROM news WHERE id = ANY($1) AND cluster_id IS NOT NULL);`, newsIDs,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update story clusters: %w", err)
+	}
+	return nil
+}
+
+// unclusteredCollapse проверяет режим свертки в хранилищах, где сюжеты не
+// строятся: любой режим, кроме CollapseNone, не поддерживается.
+func unclusteredCollapse(collapse string) error {
+	if _, err := collapseClause(collapse); err != nil {
+		return err
+	}
+	if collapse != models.CollapseNone {
+		return fmt.Errorf("collapse %q: %w", collapse, ErrUnsupported)
+	}
+	return nil
+}
+
+// collapseClause возвращает условие, оставляющее по одной новости из каждого сюжета.
+func collapseClause(collapse string) (string, error) {
+	switch collapse {
+	case models.CollapseNone:
+		return "", nil
+	case models.CollapseEarliest:
+		return "(cluster_id IS NULL OR id = (SELECT c.earliest_news_id FROM story_clusters c WHERE c.id = news.cluster_id))", nil
+	case models.CollapsePrimary:
+		return "(cluster_id IS NULL OR id = (SELECT c.primary_news_id FROM story_clusters c WHERE c.id = news.cluster_id))", nil
+	default:
+		return "", fmt.Errorf("unknown collapse mode %q", collapse)
+	}
+}
+
+// attachAlsoReportedBy дополняет новости ссылками на остальные новости их сюжетов.
+func (s *Storage) attachAlsoReportedBy(ctx context.Context, news []models.NewsFullDetailed, clusters []*int64) error {
+	byCluster := make(map[int64][]int)
+	ids := make([]int64, 0, len(clusters))
+	for i, clusterID := range clusters {
+		if clusterID == nil {
+			continue
+		}
+		if _, ok := byCluster[*clusterID]; !ok {
+			ids = append(ids, *clusterID)
+		}
+		byCluster[*clusterID] = append(byCluster[*clusterID], i)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	rows, err := s.db.Query(ctx, `
+	SELECT id, source, link, published_at, cluster_id
+	FROM news
+	WHERE cluster_id = ANY($1)
+	ORDER BY published_at;`, ids,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to query related news: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var related models.RelatedNews
+		var clusterID int64
+		if err := rows.Scan(&related.NewsID, &related.Source, &related.Link, &related.PublishedAt, &clusterID); err != nil {
+			return fmt.Errorf("failed to scan related news: %w", err)
+		}
+		for _, i := range byCluster[clusterID] {
+			if news[i].NewsID != related.NewsID {
+				news[i].AlsoReportedBy = append(news[i].AlsoReportedBy, related)
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("related news iteration error: %w", err)
+	}
+	return nil
+}
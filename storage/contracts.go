@@ -2,10 +2,23 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"newsservice/internal/domain"
 	"newsservice/internal/models"
+	"newsservice/internal/search"
+	"time"
 )
 
+// ErrNotFound возвращается, если запрошенная запись отсутствует в хранилище.
+var ErrNotFound = errors.New("not found")
+
+// ErrConflict возвращается, если изменение конфликтует с существующей записью.
+var ErrConflict = errors.New("conflict")
+
+// ErrUnsupported возвращается, если хранилище не поддерживает параметр фильтра
+// или возможность, нужную вызывающему коду.
+var ErrUnsupported = errors.New("not supported by this storage")
+
 type NewsStorage interface {
 	GetNewsCount(ctx context.Context, filter models.NewsFilter) (int, error)
 	GetDetailedNews(ctx context.Context, id int) (models.NewsFullDetailed, error)
@@ -13,3 +26,64 @@ type NewsStorage interface {
 	SaveNews(ctx context.Context, feed *domain.Feed) (int, error)
 	Close()
 }
+
+// Migrator — хранилище со схемой БД, которую нужно обновить перед работой.
+type Migrator interface {
+	Migrate(ctx context.Context) error
+}
+
+// AdminStorage — служебные методы хранилища для административного API.
+type AdminStorage interface {
+	GetLanguageStats(ctx context.Context) ([]models.SourceLanguageStats, error)
+}
+
+// TaggingStorage — методы хранилища для правил категоризации и повторного тегирования новостей.
+type TaggingStorage interface {
+	GetTaggingRules(ctx context.Context) ([]byte, error)
+	ListNewsForRetag(ctx context.Context, afterID int, limit int) ([]models.NewsFullDetailed, error)
+	UpdateNewsTags(ctx context.Context, newsID int, category string, tags []string) error
+}
+
+// RevisionStorage — история правок новостей издателями.
+type RevisionStorage interface {
+	GetNewsRevisions(ctx context.Context, newsID int) ([]models.NewsRevision, error)
+}
+
+// OutboxStorage — очередь сообщений, записанных вместе с новостями и ожидающих публикации.
+type OutboxStorage interface {
+	FetchPendingOutbox(ctx context.Context, limit int) ([]OutboxMessage, error)
+	MarkOutboxDelivered(ctx context.Context, id int64) error
+	MarkOutboxFailed(ctx context.Context, id int64, reason string, nextAttempt time.Time) error
+	MarkOutboxDead(ctx context.Context, id int64, reason string) error
+	DeleteDeliveredOutbox(ctx context.Context, before time.Time) (int64, error)
+	GetOutboxLag(ctx context.Context) (int, time.Time, error)
+}
+
+// SourceStorage — реестр источников, подключенных и отключенных во время работы сервиса.
+type SourceStorage interface {
+	ListSources(ctx context.Context) ([]domain.Source, error)
+	SaveSource(ctx context.Context, source domain.Source) error
+}
+
+// IdempotencyStorage — ключи идемпотентности уже обработанных входящих сообщений.
+type IdempotencyStorage interface {
+	IsMessageProcessed(ctx context.Context, key string) (bool, error)
+	MarkMessageProcessed(ctx context.Context, key string) error
+	DeleteProcessedMessages(ctx context.Context, before time.Time) (int64, error)
+}
+
+// SearchIndexStorage — выборка новостей для внешнего поискового индекса.
+type SearchIndexStorage interface {
+	ListNewsForIndex(ctx context.Context, afterID int64, limit int) ([]search.Document, error)
+	GetNewsForIndex(ctx context.Context, ids ...int64) ([]search.Document, error)
+}
+
+// TaxonomyStorage — справочники тегов и категорий новостей.
+type TaxonomyStorage interface {
+	ListTags(ctx context.Context, prefix string, limit int) ([]models.Tag, error)
+	GetTag(ctx context.Context, slug string) (models.Tag, error)
+	RenameTag(ctx context.Context, slug, newSlug, name string) (models.Tag, error)
+	MergeTags(ctx context.Context, from []string, into string) (models.Tag, error)
+	ListCategories(ctx context.Context) ([]models.Category, error)
+	SaveCategory(ctx context.Context, category models.Category) error
+}
@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"newsservice/internal/domain"
+	"newsservice/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// saveExtraction заменяет ключевые слова и упоминания сущностей новости.
+func (s *Storage) saveExtraction(ctx context.Context, tx pgx.Tx, newsID int64, item domain.Item) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM news_keywords WHERE news_id = $1;`, newsID); err != nil {
+		return fmt.Errorf("failed to clear news keywords: %w", err)
+	}
+	for i, keyword := range item.Keywords {
+		_, err := tx.Exec(ctx, `
+		INSERT INTO news_keywords (news_id, keyword, position)
+		VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING;`, newsID, keyword, i,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to save news keyword: %w", err)
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM news_entities WHERE news_id = $1;`, newsID); err != nil {
+		return fmt.Errorf("failed to clear news entities: %w", err)
+	}
+	for _, entity := range item.Entities {
+		var entityID int64
+		err := tx.QueryRow(ctx, `
+		INSERT INTO entities (kind, name)
+		VALUES ($1, $2)
+		ON CONFLICT (kind, name) DO UPDATE SET name = EXCLUDED.name
+		RETURNING id;`, entity.Kind, entity.Name,
+		).Scan(&entityID)
+		if err != nil {
+			return fmt.Errorf("failed to save entity: %w", err)
+		}
+		_, err = tx.Exec(ctx, `
+		INSERT INTO news_entities (news_id, entity_id, mentions)
+		VALUES ($1, $2, $3);`, newsID, entityID, entity.Mentions,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to link entity to news: %w", err)
+		}
+	}
+	return nil
+}
+
+// attachExtraction дополняет новость ключевыми словами и упомянутыми сущностями.
+func (s *Storage) attachExtraction(ctx context.Context, news *models.NewsFullDetailed) error {
+	rows, err := s.db.Query(ctx, `
+	SELECT keyword FROM news_keywords WHERE news_id = $1 ORDER BY position;`, news.NewsID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to query news keywords: %w", err)
+	}
+	news.Keywords, err = pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return fmt.Errorf("failed to scan news keywords: %w", err)
+	}
+
+	rows, err = s.db.Query(ctx, `
+	SELECT e.id, e.kind, e.name, ne.mentions
+	FROM news_entities ne
+	JOIN entities e ON e.id = ne.entity_id
+	WHERE ne.news_id = $1
+	ORDER BY ne.mentions DESC, e.name;`, news.NewsID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to query news entities: %w", err)
+	}
+	news.Entities, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.Entity, error) {
+		var e models.Entity
+		err := row.Scan(&e.ID, &e.Kind, &e.Name, &e.Mentions)
+		return e, err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to scan news entities: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ResetNews удаляет новости и связанные с ними записи между тестами.
+func (s *Storage) ResetNews(ctx context.Context) error {
+	_, err := s.db.Exec(ctx, `
+	TRUNCATE news, news_tags, tags, tag_aliases, category_names, categories, news_keywords,
+		news_entities, entities, news_revisions, story_clusters, outbox
+	RESTART IDENTITY CASCADE;`)
+	if err != nil {
+		return fmt.Errorf("failed to reset news tables: %w", err)
+	}
+	return nil
+}
+
+// SaveTaggingRules сохраняет новую версию правил категоризации.
+func (s *SQLiteStorage) SaveTaggingRules(ctx context.Context, document []byte) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO tagging_rules (document, created_at) VALUES ($1, $2);`, document, time.Now().UnixMicro(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save tagging rules: %w", err)
+	}
+	return nil
+}
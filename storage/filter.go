@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"fmt"
+	"newsservice/internal/models"
+	"strings"
+	"time"
+)
+
+// sqlConditions накапливает условия WHERE и их позиционные параметры.
+// Значения из запроса попадают в SQL только через параметры.
+type sqlConditions struct {
+	clauses []string
+	args    []any
+	// unixMicro — время хранится числом микросекунд Unix (SQLite), а не TIMESTAMPTZ.
+	unixMicro bool
+}
+
+// arg добавляет параметр и возвращает его плейсхолдер.
+func (c *sqlConditions) arg(value any) string {
+	c.args = append(c.args, value)
+	return fmt.Sprintf("$%d", len(c.args))
+}
+
+// timeArg добавляет параметр-время в формате хранения и возвращает его плейсхолдер.
+func (c *sqlConditions) timeArg(t time.Time) string {
+	if c.unixMicro {
+		return c.arg(t.UnixMicro())
+	}
+	return c.arg(t)
+}
+
+// add добавляет условие, объединяемое с остальными через AND.
+func (c *sqlConditions) add(clause string) {
+	c.clauses = append(c.clauses, clause)
+}
+
+// where возвращает предложение WHERE или пустую строку без условий.
+func (c *sqlConditions) where() string {
+	if len(c.clauses) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(c.clauses, " AND ")
+}
+
+// newsConditions строит условия выборки новостей по фильтру. Используется и
+// для подсчета, и для выборки страницы, чтобы их условия совпадали.
+func newsConditions(filter models.NewsFilter) (*sqlConditions, error) {
+	c := &sqlConditions{}
+
+	if len(filter.Sources) > 0 {
+		c.add("source = ANY(" + c.arg(filter.Sources) + ")")
+	}
+	if len(filter.ExcludeSources) > 0 {
+		c.add("source <> ALL(" + c.arg(filter.ExcludeSources) + ")")
+	}
+	if len(filter.Categories) > 0 {
+		c.add("category = ANY(" + c.arg(filter.Categories) + ")")
+	}
+	if len(filter.ExcludeCategories) > 0 {
+		c.add("category <> ALL(" + c.arg(filter.ExcludeCategories) + ")")
+	}
+	// Теги сравниваются по актуальным и прежним slug.
+	if len(filter.Tags) > 0 {
+		tags := c.arg(filter.Tags)
+		switch filter.TagsMatch {
+		case models.TagsMatchAny, "":
+			c.add(`EXISTS (
+			SELECT 1 FROM news_tags nt JOIN tag_slugs s ON s.tag_id = nt.tag_id
+			WHERE nt.news_id = news.id AND s.slug = ANY(` + tags + `))`)
+		case models.TagsMatchAll:
+			c.add(`NOT EXISTS (
+			SELECT 1 FROM unnest(` + tags + `::TEXT[]) AS want (slug)
+			WHERE NOT EXISTS (
+				SELECT 1 FROM news_tags nt JOIN tag_slugs s ON s.tag_id = nt.tag_id
+				WHERE nt.news_id = news.id AND s.slug = want.slug))`)
+		default:
+			return nil, fmt.Errorf("unknown tags match mode %q", filter.TagsMatch)
+		}
+	}
+	if len(filter.ExcludeTags) > 0 {
+		c.add(`NOT EXISTS (
+		SELECT 1 FROM news_tags nt JOIN tag_slugs s ON s.tag_id = nt.tag_id
+		WHERE nt.news_id = news.id AND s.slug = ANY(` + c.arg(filter.ExcludeTags) + `))`)
+	}
+	if len(filter.Languages) > 0 {
+		c.add("language = ANY(" + c.arg(filter.Languages) + ")")
+	}
+	if filter.Author != "" {
+		c.add("author = " + c.arg(filter.Author))
+	}
+	if !filter.From.IsZero() {
+		c.add("published_at >= " + c.timeArg(filter.From))
+	}
+	if !filter.To.IsZero() {
+		c.add("published_at < " + c.timeArg(filter.To))
+	}
+	if filter.HasImage != nil {
+		if *filter.HasImage {
+			c.add("COALESCE(image_key, '') <> ''")
+		} else {
+			c.add("COALESCE(image_key, '') = ''")
+		}
+	}
+	if filter.Entity != "" {
+		c.add(`EXISTS (
+		SELECT 1 FROM news_entities ne JOIN entities e ON e.id = ne.entity_id
+		WHERE ne.news_id = news.id AND lower(e.name) = lower(` + c.arg(filter.Entity) + `))`)
+	}
+	if filter.Query != "" {
+		pattern := c.arg(likePattern(filter.Query))
+		c.add(fmt.Sprintf("(title ILIKE %[1]s OR description_text ILIKE %[1]s OR content_text ILIKE %[1]s)", pattern))
+	}
+	collapse, err := collapseClause(filter.Collapse)
+	if err != nil {
+		return nil, err
+	}
+	if collapse != "" {
+		c.add(collapse)
+	}
+	return c, nil
+}
@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"newsservice/internal/domain"
+	"newsservice/internal/models"
+)
+
+// imageColumnsSelect — колонки ведущего изображения в порядке imageColumns.targets.
+const imageColumnsSelect = `image_key, image_source_url, image_origin, image_width, image_height, image_thumbnails`
+
+type storedThumbnail struct {
+	Key    string `json:"key"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// imageColumns принимает значения колонок изображения при сканировании строки.
+type imageColumns struct {
+	key        *string
+	sourceURL  *string
+	origin     *string
+	width      *int
+	height     *int
+	thumbnails []byte
+}
+
+func (c *imageColumns) targets() []any {
+	return []any{&c.key, &c.sourceURL, &c.origin, &c.width, &c.height, &c.thumbnails}
+}
+
+// model строит описание изображения для ответа API; nil, если изображения нет.
+func (c *imageColumns) model() (*models.Image, error) {
+	if c.key == nil || *c.key == "" {
+		return nil, nil
+	}
+	img := &models.Image{URL: models.ImageURL(*c.key)}
+	if c.sourceURL != nil {
+		img.SourceURL = *c.sourceURL
+	}
+	if c.origin != nil {
+		img.Origin = *c.origin
+	}
+	if c.width != nil {
+		img.Width = *c.width
+	}
+	if c.height != nil {
+		img.Height = *c.height
+	}
+	var thumbs []storedThumbnail
+	if len(c.thumbnails) > 0 {
+		if err := json.Unmarshal(c.thumbnails, &thumbs); err != nil {
+			return nil, fmt.Errorf("failed to decode image thumbnails: %w", err)
+		}
+	}
+	for _, t := range thumbs {
+		img.Thumbnails = append(img.Thumbnails, models.Thumbnail{
+			URL:    models.ImageURL(t.Key),
+			Width:  t.Width,
+			Height: t.Height,
+		})
+	}
+	return img, nil
+}
+
+// imageParams возвращает значения колонок изображения для записи элемента.
+func imageParams(img *domain.Image) (key, sourceURL, origin, width, height any, thumbnails []byte, err error) {
+	if img == nil {
+		return nil, nil, nil, nil, nil, []byte("[]"), nil
+	}
+	thumbs := make([]storedThumbnail, 0, len(img.Thumbnails))
+	for _, t := range img.Thumbnails {
+		thumbs = append(thumbs, storedThumbnail{Key: t.Key, Width: t.Width, Height: t.Height})
+	}
+	thumbnails, err = json.Marshal(thumbs)
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, fmt.Errorf("failed to encode image thumbnails: %w", err)
+	}
+	return img.Key, img.SourceURL, img.Origin, img.Width, img.Height, thumbnails, nil
+}
@@ -0,0 +1,31 @@
+package storage
+
+import (
+	"fmt"
+	"newsservice/internal/models"
+)
+
+// keysetClause добавляет условие выборки страницы после позиции keyset (или
+// перед ней при Backward) и возвращает порядок выборки. При движении назад
+// новости выбираются в обратном порядке, и вызывающий должен развернуть результат.
+func keysetClause(c *sqlConditions, keyset models.Keyset) (string, error) {
+	var desc bool
+	switch keyset.Order {
+	case models.SortDesc:
+		desc = true
+	case models.SortAsc:
+	default:
+		return "", fmt.Errorf("unknown keyset order %q", keyset.Order)
+	}
+	if keyset.Backward {
+		desc = !desc
+	}
+	order, cmp := " ORDER BY published_at, id", ">"
+	if desc {
+		order, cmp = " ORDER BY published_at DESC, id DESC", "<"
+	}
+	if keyset.ID != 0 {
+		c.add(fmt.Sprintf("(published_at, id) %s (%s, %s)", cmp, c.timeArg(keyset.PublishedAt), c.arg(keyset.ID)))
+	}
+	return order, nil
+}
@@ -0,0 +1,427 @@
+package storage
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"newsservice/internal/domain"
+	"newsservice/internal/models"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+var _ NewsStorage = (*MemoryStorage)(nil)
+
+// MemoryStorage — хранилище новостей в памяти для локальной разработки и
+// тестов. Повторяет поведение Storage при сохранении, фильтрации, подсчете и
+// сортировке новостей. Сюжеты не строятся, поэтому свертка по сюжетам
+// отклоняется ошибкой ErrUnsupported. Безопасно для одновременного использования.
+type MemoryStorage struct {
+	mutex    sync.RWMutex
+	news     map[int]*memoryNews
+	links    map[string]int
+	entities map[memoryEntityKey]int
+	lastID   int
+}
+
+// memoryNews — сохраненная новость. Keywords и Entities в item возвращаются
+// только вместе с отдельной новостью.
+type memoryNews struct {
+	item      models.NewsFullDetailed
+	fetchedAt time.Time
+}
+
+type memoryEntityKey struct {
+	kind string
+	name string
+}
+
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{
+		news:     make(map[int]*memoryNews),
+		links:    make(map[string]int),
+		entities: make(map[memoryEntityKey]int),
+	}
+}
+
+// Метод для подсчета количества новостей для пагинации
+func (s *MemoryStorage) GetNewsCount(ctx context.Context, filter models.NewsFilter) (int, error) {
+	if err := validateMemoryFilter(filter); err != nil {
+		return 0, err
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	count := 0
+	for _, n := range s.news {
+		if n.matches(filter) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// Метод для выборки новости по newsID
+func (s *MemoryStorage) GetDetailedNews(ctx context.Context, newsID int) (models.NewsFullDetailed, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	n, ok := s.news[newsID]
+	if !ok {
+		return models.NewsFullDetailed{}, fmt.Errorf("news with ID %d: %w", newsID, ErrNotFound)
+	}
+	item := n.copy()
+	item.Keywords = slices.Clone(n.item.Keywords)
+	item.Entities = slices.Clone(n.item.Entities)
+	return item, nil
+}
+
+// Метод для выборки новостей с фильтрацией и пагинацией
+func (s *MemoryStorage) GetNewsByFilter(ctx context.Context, filter models.NewsFilter) ([]models.NewsFullDetailed, error) {
+	if err := validateMemoryFilter(filter); err != nil {
+		return nil, err
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var matched []*memoryNews
+	for _, n := range s.news {
+		if n.matches(filter) {
+			matched = append(matched, n)
+		}
+	}
+
+	var compare func(a, b *memoryNews) int
+	if filter.Keyset != nil {
+		var err error
+		if matched, compare, err = memoryKeyset(matched, *filter.Keyset); err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		if compare, err = memoryOrder(filter.Sort, filter.Query); err != nil {
+			return nil, err
+		}
+	}
+	slices.SortFunc(matched, compare)
+
+	if filter.Offset > 0 {
+		matched = matched[min(filter.Offset, len(matched)):]
+	}
+	if filter.Limit > 0 && len(matched) > filter.Limit {
+		matched = matched[:filter.Limit]
+	}
+	if filter.Keyset != nil && filter.Keyset.Backward {
+		slices.Reverse(matched)
+	}
+
+	var news []models.NewsFullDetailed
+	for _, n := range matched {
+		news = append(news, n.copy())
+	}
+	return news, nil
+}
+
+func (s *MemoryStorage) Close() {}
+
+// Метод для сохранения новостей фида. Возвращает количество новых записей.
+// Как и Storage, перезаписывает новость с той же ссылкой, только если
+// изменились заголовок, описание, текст, дата публикации или изображение.
+func (s *MemoryStorage) SaveNews(ctx context.Context, feed *domain.Feed) (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	saved := 0
+	for i := range feed.Items {
+		item := &feed.Items[i]
+		id, exists := s.links[item.Link]
+		if exists {
+			n := s.news[id]
+			if !n.changed(*item) {
+				continue
+			}
+			n.update(*item)
+			item.Status = domain.StatusUpdated
+		} else {
+			s.lastID++
+			id = s.lastID
+			n := &memoryNews{
+				item: models.NewsFullDetailed{
+					NewsID:       id,
+					Source:       feed.Source,
+					Link:         item.Link,
+					OriginalLink: item.OriginalLink,
+				},
+				fetchedAt: time.Now(),
+			}
+			n.update(*item)
+			s.news[id] = n
+			s.links[item.Link] = id
+			item.Status = domain.StatusCreated
+			saved++
+		}
+		item.ID = int64(id)
+		s.saveExtraction(s.news[id], *item)
+	}
+	return saved, nil
+}
+
+// saveExtraction заменяет теги, ключевые слова и сущности новости.
+func (s *MemoryStorage) saveExtraction(n *memoryNews, item domain.Item) {
+	n.item.Tag = uniqueNonEmpty(item.Tags)
+	n.item.Keywords = uniqueNonEmpty(item.Keywords)
+	n.item.Entities = nil
+	for _, e := range item.Entities {
+		key := memoryEntityKey{kind: e.Kind, name: e.Name}
+		entityID, ok := s.entities[key]
+		if !ok {
+			entityID = len(s.entities) + 1
+			s.entities[key] = entityID
+		}
+		n.item.Entities = append(n.item.Entities, models.Entity{
+			ID:       entityID,
+			Kind:     e.Kind,
+			Name:     e.Name,
+			Mentions: e.Mentions,
+		})
+	}
+	slices.SortFunc(n.item.Entities, func(a, b models.Entity) int {
+		return cmp.Or(cmp.Compare(b.Mentions, a.Mentions), cmp.Compare(a.Name, b.Name))
+	})
+}
+
+// changed сообщает, нужно ли перезаписать новость элементом фида.
+func (n *memoryNews) changed(item domain.Item) bool {
+	if n.item.Title != item.Title || n.item.Description != item.Description ||
+		n.item.Content != item.Content || !n.item.PublishedAt.Equal(item.PubDate) {
+		return true
+	}
+	return item.Image != nil && (n.item.Image == nil || n.item.Image.URL != models.ImageURL(item.Image.Key))
+}
+
+// update переносит в новость поля элемента фида. Изображение сохраняется,
+// если в элементе его нет.
+func (n *memoryNews) update(item domain.Item) {
+	n.item.Title = item.Title
+	n.item.Description = item.Description
+	n.item.DescriptionText = item.DescriptionText
+	n.item.DescriptionGenerated = item.DescriptionGenerated
+	n.item.Content = item.Content
+	n.item.ContentText = item.ContentText
+	n.item.Author = item.Author
+	n.item.PublishedAt = item.PubDate
+	n.item.Language = item.Language
+	n.item.Category = item.Category
+	n.item.FeedCategories = slices.Clone(item.Categories)
+	if n.item.FeedCategories == nil {
+		n.item.FeedCategories = []string{}
+	}
+	if item.Image != nil && item.Image.Key != "" {
+		img := &models.Image{
+			URL:       models.ImageURL(item.Image.Key),
+			SourceURL: item.Image.SourceURL,
+			Origin:    item.Image.Origin,
+			Width:     item.Image.Width,
+			Height:    item.Image.Height,
+		}
+		for _, t := range item.Image.Thumbnails {
+			img.Thumbnails = append(img.Thumbnails, models.Thumbnail{
+				URL:    models.ImageURL(t.Key),
+				Width:  t.Width,
+				Height: t.Height,
+			})
+		}
+		n.item.Image = img
+	}
+}
+
+// copy возвращает новость для списка: без ключевых слов и сущностей и с
+// собственными копиями срезов.
+func (n *memoryNews) copy() models.NewsFullDetailed {
+	item := n.item
+	item.Tag = slices.Clone(n.item.Tag)
+	item.FeedCategories = slices.Clone(n.item.FeedCategories)
+	item.Keywords = nil
+	item.Entities = nil
+	if n.item.Image != nil {
+		img := *n.item.Image
+		img.Thumbnails = slices.Clone(img.Thumbnails)
+		item.Image = &img
+	}
+	return item
+}
+
+// matches проверяет новость по условиям фильтра, как newsConditions.
+func (n *memoryNews) matches(filter models.NewsFilter) bool {
+	item := &n.item
+	if len(filter.Sources) > 0 && !slices.Contains(filter.Sources, item.Source) {
+		return false
+	}
+	if slices.Contains(filter.ExcludeSources, item.Source) {
+		return false
+	}
+	if len(filter.Categories) > 0 && !slices.Contains(filter.Categories, item.Category) {
+		return false
+	}
+	if slices.Contains(filter.ExcludeCategories, item.Category) {
+		return false
+	}
+	if len(filter.Tags) > 0 {
+		hasTag := func(tag string) bool { return slices.Contains(item.Tag, tag) }
+		if filter.TagsMatch == models.TagsMatchAll {
+			for _, tag := range filter.Tags {
+				if !hasTag(tag) {
+					return false
+				}
+			}
+		} else if !slices.ContainsFunc(filter.Tags, hasTag) {
+			return false
+		}
+	}
+	for _, tag := range filter.ExcludeTags {
+		if slices.Contains(item.Tag, tag) {
+			return false
+		}
+	}
+	if len(filter.Languages) > 0 && !slices.Contains(filter.Languages, item.Language) {
+		return false
+	}
+	if filter.Author != "" && item.Author != filter.Author {
+		return false
+	}
+	if !filter.From.IsZero() && item.PublishedAt.Before(filter.From) {
+		return false
+	}
+	if !filter.To.IsZero() && !item.PublishedAt.Before(filter.To) {
+		return false
+	}
+	if filter.HasImage != nil && *filter.HasImage != (item.Image != nil) {
+		return false
+	}
+	if filter.Entity != "" && !slices.ContainsFunc(item.Entities, func(e models.Entity) bool {
+		return strings.EqualFold(e.Name, filter.Entity)
+	}) {
+		return false
+	}
+	if filter.Query != "" && n.occurrences(filter.Query) == 0 {
+		return false
+	}
+	return true
+}
+
+// occurrences считает вхождения query без учета регистра в заголовок,
+// описание и текст новости. Используется вместо ранга полнотекстового поиска.
+func (n *memoryNews) occurrences(query string) int {
+	query = strings.ToLower(query)
+	count := 0
+	for _, text := range []string{n.item.Title, n.item.DescriptionText, n.item.ContentText} {
+		count += strings.Count(strings.ToLower(text), query)
+	}
+	return count
+}
+
+// validateMemoryFilter отклоняет неизвестные режимы фильтра, как newsConditions,
+// и свертку: сюжеты в памяти не строятся.
+func validateMemoryFilter(filter models.NewsFilter) error {
+	switch filter.TagsMatch {
+	case "", models.TagsMatchAny, models.TagsMatchAll:
+	default:
+		return fmt.Errorf("unknown tags match mode %q", filter.TagsMatch)
+	}
+	return unclusteredCollapse(filter.Collapse)
+}
+
+// memoryOrder возвращает сравнение новостей по спецификации сортировки, как
+// orderClause: по умолчанию по убыванию даты публикации, последним ключом id.
+func memoryOrder(sort []models.SortField, query string) (func(a, b *memoryNews) int, error) {
+	if len(sort) == 0 {
+		sort = []models.SortField{{Field: models.SortPublishedAt, Order: models.SortDesc}}
+	}
+	keys := make([]func(a, b *memoryNews) int, 0, len(sort)+1)
+	for _, f := range sort {
+		var key func(a, b *memoryNews) int
+		switch f.Field {
+		case models.SortPublishedAt:
+			key = func(a, b *memoryNews) int { return a.item.PublishedAt.Compare(b.item.PublishedAt) }
+		case models.SortFetchedAt:
+			key = func(a, b *memoryNews) int { return a.fetchedAt.Compare(b.fetchedAt) }
+		case models.SortTitle:
+			key = func(a, b *memoryNews) int { return cmp.Compare(a.item.Title, b.item.Title) }
+		case models.SortSource:
+			key = func(a, b *memoryNews) int { return cmp.Compare(a.item.Source, b.item.Source) }
+		case models.SortRelevance:
+			if query == "" {
+				return nil, fmt.Errorf("sort by relevance requires a search query")
+			}
+			key = func(a, b *memoryNews) int { return cmp.Compare(a.occurrences(query), b.occurrences(query)) }
+		default:
+			return nil, fmt.Errorf("unknown sort field %q", f.Field)
+		}
+		switch f.Order {
+		case models.SortAsc:
+			keys = append(keys, key)
+		case models.SortDesc:
+			keys = append(keys, func(a, b *memoryNews) int { return key(b, a) })
+		default:
+			return nil, fmt.Errorf("unknown sort order %q", f.Order)
+		}
+	}
+	keys = append(keys, func(a, b *memoryNews) int { return cmp.Compare(b.item.NewsID, a.item.NewsID) })
+	return func(a, b *memoryNews) int {
+		for _, key := range keys {
+			if c := key(a, b); c != 0 {
+				return c
+			}
+		}
+		return 0
+	}, nil
+}
+
+// memoryKeyset оставляет новости после позиции keyset (или перед ней при
+// Backward) и возвращает порядок выборки, как keysetClause.
+func memoryKeyset(news []*memoryNews, keyset models.Keyset) ([]*memoryNews, func(a, b *memoryNews) int, error) {
+	var desc bool
+	switch keyset.Order {
+	case models.SortDesc:
+		desc = true
+	case models.SortAsc:
+	default:
+		return nil, nil, fmt.Errorf("unknown keyset order %q", keyset.Order)
+	}
+	if keyset.Backward {
+		desc = !desc
+	}
+	position := func(n *memoryNews) int {
+		return cmp.Or(n.item.PublishedAt.Compare(keyset.PublishedAt), cmp.Compare(n.item.NewsID, keyset.ID))
+	}
+	compare := func(a, b *memoryNews) int {
+		return cmp.Or(a.item.PublishedAt.Compare(b.item.PublishedAt), cmp.Compare(a.item.NewsID, b.item.NewsID))
+	}
+	if desc {
+		asc := compare
+		compare = func(a, b *memoryNews) int { return asc(b, a) }
+	}
+	if keyset.ID == 0 {
+		return news, compare, nil
+	}
+	after := news[:0:0]
+	for _, n := range news {
+		if p := position(n); (desc && p < 0) || (!desc && p > 0) {
+			after = append(after, n)
+		}
+	}
+	return after, compare, nil
+}
+
+// uniqueNonEmpty возвращает непустые значения без повторов в исходном порядке.
+func uniqueNonEmpty(values []string) []string {
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if v != "" && !slices.Contains(result, v) {
+			result = append(result, v)
+		}
+	}
+	return result
+}
@@ -0,0 +1,13 @@
+package storage_test
+
+import (
+	"newsservice/storage"
+	"newsservice/storage/storagetest"
+	"testing"
+)
+
+func TestMemoryStorage(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.NewsStorage {
+		return storage.NewMemoryStorage()
+	})
+}
@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"sort"
+	"strings"
+)
+
+var _ Migrator = (*Storage)(nil)
+
+//go:embed migrations/postgres/*.sql
+var postgresMigrations embed.FS
+
+// Migrate применяет к БД еще не выполненные миграции в порядке их номеров.
+func (s *Storage) Migrate(ctx context.Context) error {
+	_, err := s.db.Exec(ctx, `
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    TEXT PRIMARY KEY,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	);`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	files, err := migrationFiles(postgresMigrations, "migrations/postgres")
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		version := strings.TrimSuffix(path.Base(file), ".sql")
+
+		var applied bool
+		err := s.db.QueryRow(ctx,
+			`SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)`, version,
+		).Scan(&applied)
+		if err != nil {
+			return fmt.Errorf("failed to check migration %s: %w", version, err)
+		}
+		if applied {
+			continue
+		}
+
+		body, err := fs.ReadFile(postgresMigrations, file)
+		if err != nil {
+			return fmt.Errorf("failed to read migration %s: %w", version, err)
+		}
+
+		tx, err := s.db.Begin(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to begin migration %s: %w", version, err)
+		}
+		if _, err := tx.Exec(ctx, string(body)); err != nil {
+			tx.Rollback(ctx)
+			return fmt.Errorf("failed to apply migration %s: %w", version, err)
+		}
+		if _, err := tx.Exec(ctx, `INSERT INTO schema_migrations (version) VALUES ($1)`, version); err != nil {
+			tx.Rollback(ctx)
+			return fmt.Errorf("failed to record migration %s: %w", version, err)
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return fmt.Errorf("failed to commit migration %s: %w", version, err)
+		}
+		s.log.Info("Migration applied", slog.String("version", version))
+	}
+	return nil
+}
+
+// migrationFiles возвращает отсортированный список файлов миграций каталога dir.
+func migrationFiles(fsys fs.FS, dir string) ([]string, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+	files := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".sql") {
+			files = append(files, path.Join(dir, e.Name()))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS news (
+    id           BIGSERIAL PRIMARY KEY,
+    title        TEXT        NOT NULL,
+    description  TEXT        NOT NULL DEFAULT '',
+    content      TEXT        NOT NULL DEFAULT '',
+    author       TEXT        NOT NULL DEFAULT '',
+    published_at TIMESTAMPTZ NOT NULL,
+    fetched_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    source       TEXT        NOT NULL DEFAULT '',
+    link         TEXT        NOT NULL UNIQUE,
+    category     TEXT        NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS news_published_at_idx ON news (published_at DESC);
+CREATE INDEX IF NOT EXISTS news_category_idx ON news (category);
+CREATE INDEX IF NOT EXISTS news_author_idx ON news (author);
@@ -0,0 +1,3 @@
+ALTER TABLE news
+    ADD COLUMN IF NOT EXISTS description_text TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS content_text     TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,4 @@
+ALTER TABLE news
+    ADD COLUMN IF NOT EXISTS original_link TEXT NOT NULL DEFAULT '';
+
+UPDATE news SET original_link = link WHERE original_link = '';
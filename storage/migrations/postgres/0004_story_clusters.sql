@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS story_clusters (
+    id               BIGSERIAL PRIMARY KEY,
+    primary_news_id  BIGINT      NOT NULL,
+    earliest_news_id BIGINT      NOT NULL,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+ALTER TABLE news
+    ADD COLUMN IF NOT EXISTS simhash    BIGINT,
+    ADD COLUMN IF NOT EXISTS cluster_id BIGINT REFERENCES story_clusters (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS news_cluster_id_idx ON news (cluster_id);
@@ -0,0 +1,5 @@
+ALTER TABLE news
+    ADD COLUMN IF NOT EXISTS language TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS news_language_idx ON news (language);
+CREATE INDEX IF NOT EXISTS news_source_language_idx ON news (source, language);
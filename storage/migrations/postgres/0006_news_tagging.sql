@@ -0,0 +1,11 @@
+ALTER TABLE news
+    ADD COLUMN IF NOT EXISTS tags            TEXT[] NOT NULL DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS feed_categories TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS news_tags_idx ON news USING GIN (tags);
+
+CREATE TABLE IF NOT EXISTS tagging_rules (
+    version    BIGSERIAL PRIMARY KEY,
+    document   JSONB       NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
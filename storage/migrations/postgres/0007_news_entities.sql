@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS entities (
+    id   BIGSERIAL PRIMARY KEY,
+    kind TEXT NOT NULL,
+    name TEXT NOT NULL,
+    UNIQUE (kind, name)
+);
+
+CREATE INDEX IF NOT EXISTS entities_lower_name_idx ON entities (lower(name));
+
+CREATE TABLE IF NOT EXISTS news_entities (
+    news_id   BIGINT  NOT NULL REFERENCES news (id) ON DELETE CASCADE,
+    entity_id BIGINT  NOT NULL REFERENCES entities (id) ON DELETE CASCADE,
+    mentions  INTEGER NOT NULL DEFAULT 1,
+    PRIMARY KEY (news_id, entity_id)
+);
+
+CREATE INDEX IF NOT EXISTS news_entities_entity_id_idx ON news_entities (entity_id);
+
+CREATE TABLE IF NOT EXISTS news_keywords (
+    news_id  BIGINT  NOT NULL REFERENCES news (id) ON DELETE CASCADE,
+    keyword  TEXT    NOT NULL,
+    position INTEGER NOT NULL,
+    PRIMARY KEY (news_id, keyword)
+);
+
+CREATE INDEX IF NOT EXISTS news_keywords_keyword_idx ON news_keywords (keyword);
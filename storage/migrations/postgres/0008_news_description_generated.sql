@@ -0,0 +1,2 @@
+ALTER TABLE news
+    ADD COLUMN IF NOT EXISTS description_generated BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,7 @@
+ALTER TABLE news
+    ADD COLUMN IF NOT EXISTS image_source_url TEXT,
+    ADD COLUMN IF NOT EXISTS image_origin TEXT,
+    ADD COLUMN IF NOT EXISTS image_key TEXT,
+    ADD COLUMN IF NOT EXISTS image_width INTEGER,
+    ADD COLUMN IF NOT EXISTS image_height INTEGER,
+    ADD COLUMN IF NOT EXISTS image_thumbnails JSONB NOT NULL DEFAULT '[]'::JSONB;
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS news_revisions (
+    id           BIGSERIAL PRIMARY KEY,
+    news_id      BIGINT      NOT NULL REFERENCES news (id) ON DELETE CASCADE,
+    revision     INTEGER     NOT NULL,
+    title        TEXT        NOT NULL,
+    description  TEXT        NOT NULL,
+    content      TEXT        NOT NULL,
+    published_at TIMESTAMPTZ NOT NULL,
+    replaced_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    diff         JSONB       NOT NULL DEFAULT '{}'::JSONB,
+    UNIQUE (news_id, revision)
+);
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS sources (
+    url        TEXT PRIMARY KEY,
+    name       TEXT        NOT NULL DEFAULT '',
+    active     BOOLEAN     NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS processed_messages (
+    idempotency_key TEXT PRIMARY KEY,
+    processed_at    TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS processed_messages_processed_at_idx ON processed_messages (processed_at);
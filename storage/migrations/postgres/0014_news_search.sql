@@ -0,0 +1,33 @@
+-- Конфигурация полнотекстового поиска по коду языка новости.
+-- Должна совпадать с searchConfigs в storage/search.go.
+CREATE OR REPLACE FUNCTION news_search_config(lang TEXT) RETURNS regconfig AS $$
+    SELECT CASE lang
+        WHEN 'ru' THEN 'russian'::regconfig
+        WHEN 'en' THEN 'english'::regconfig
+        ELSE 'simple'::regconfig
+    END;
+$$ LANGUAGE SQL IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION news_search_vector(lang TEXT, title TEXT, description TEXT, content TEXT) RETURNS tsvector AS $$
+    SELECT setweight(to_tsvector(news_search_config(lang), COALESCE(title, '')), 'A')
+        || setweight(to_tsvector(news_search_config(lang), COALESCE(description, '')), 'B')
+        || setweight(to_tsvector(news_search_config(lang), COALESCE(content, '')), 'C');
+$$ LANGUAGE SQL IMMUTABLE;
+
+ALTER TABLE news ADD COLUMN IF NOT EXISTS search_vector tsvector;
+
+CREATE OR REPLACE FUNCTION news_search_vector_update() RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector := news_search_vector(NEW.language, NEW.title, NEW.description_text, NEW.content_text);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS news_search_vector_trigger ON news;
+CREATE TRIGGER news_search_vector_trigger
+    BEFORE INSERT OR UPDATE OF title, description_text, content_text, language ON news
+    FOR EACH ROW EXECUTE FUNCTION news_search_vector_update();
+
+UPDATE news SET search_vector = news_search_vector(language, title, description_text, content_text);
+
+CREATE INDEX IF NOT EXISTS news_search_vector_idx ON news USING GIN (search_vector);
@@ -0,0 +1,2 @@
+-- Индекс для постраничного вывода по курсору (published_at, id).
+CREATE INDEX IF NOT EXISTS news_published_at_id_idx ON news (published_at DESC, id DESC);
@@ -0,0 +1,62 @@
+CREATE TABLE IF NOT EXISTS tags (
+    id         BIGSERIAL PRIMARY KEY,
+    slug       TEXT        NOT NULL UNIQUE,
+    name       TEXT        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Прежние slug переименованных и объединенных тегов: новости с такими тегами
+-- привязываются к актуальному тегу.
+CREATE TABLE IF NOT EXISTS tag_aliases (
+    slug   TEXT   PRIMARY KEY,
+    tag_id BIGINT NOT NULL REFERENCES tags (id) ON DELETE CASCADE
+);
+
+CREATE OR REPLACE VIEW tag_slugs AS
+    SELECT slug, id AS tag_id FROM tags
+    UNION ALL
+    SELECT slug, tag_id FROM tag_aliases;
+
+CREATE TABLE IF NOT EXISTS news_tags (
+    news_id  BIGINT  NOT NULL REFERENCES news (id) ON DELETE CASCADE,
+    tag_id   BIGINT  NOT NULL REFERENCES tags (id) ON DELETE CASCADE,
+    position INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (news_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS news_tags_tag_id_idx ON news_tags (tag_id, news_id);
+
+INSERT INTO tags (slug, name)
+SELECT DISTINCT t.slug, t.slug
+FROM news CROSS JOIN LATERAL unnest(news.tags) AS t (slug)
+WHERE t.slug <> ''
+ON CONFLICT (slug) DO NOTHING;
+
+INSERT INTO news_tags (news_id, tag_id, position)
+SELECT news.id, tags.id, MIN(t.pos)
+FROM news
+CROSS JOIN LATERAL unnest(news.tags) WITH ORDINALITY AS t (slug, pos)
+JOIN tags ON tags.slug = t.slug
+GROUP BY news.id, tags.id
+ON CONFLICT DO NOTHING;
+
+DROP INDEX IF EXISTS news_tags_idx;
+ALTER TABLE news DROP COLUMN IF EXISTS tags;
+
+CREATE TABLE IF NOT EXISTS categories (
+    slug        TEXT PRIMARY KEY,
+    parent_slug TEXT REFERENCES categories (slug) ON UPDATE CASCADE ON DELETE SET NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK (parent_slug IS DISTINCT FROM slug)
+);
+
+CREATE TABLE IF NOT EXISTS category_names (
+    category_slug TEXT NOT NULL REFERENCES categories (slug) ON UPDATE CASCADE ON DELETE CASCADE,
+    language      TEXT NOT NULL,
+    name          TEXT NOT NULL,
+    PRIMARY KEY (category_slug, language)
+);
+
+INSERT INTO categories (slug)
+SELECT DISTINCT category FROM news WHERE category <> ''
+ON CONFLICT (slug) DO NOTHING;
@@ -0,0 +1,19 @@
+-- Индексы 16-битных полос отпечатка для поиска кандидатов в сюжеты.
+CREATE INDEX IF NOT EXISTS news_simhash_band0_idx ON news (((simhash >> 0) & 65535));
+CREATE INDEX IF NOT EXISTS news_simhash_band1_idx ON news (((simhash >> 16) & 65535));
+CREATE INDEX IF NOT EXISTS news_simhash_band2_idx ON news (((simhash >> 32) & 65535));
+CREATE INDEX IF NOT EXISTS news_simhash_band3_idx ON news (((simhash >> 48) & 65535));
+
+-- Основная новость сюжета — версия с самым длинным текстом.
+UPDATE story_clusters c SET
+    earliest_news_id = (
+        SELECT n.id FROM news n WHERE n.cluster_id = c.id
+        ORDER BY n.published_at, n.id
+        LIMIT 1
+    ),
+    primary_news_id = (
+        SELECT n.id FROM news n WHERE n.cluster_id = c.id
+        ORDER BY length(COALESCE(NULLIF(n.content_text, ''), n.description_text, '')) DESC, n.published_at, n.id
+        LIMIT 1
+    )
+WHERE EXISTS (SELECT 1 FROM news n WHERE n.cluster_id = c.id);
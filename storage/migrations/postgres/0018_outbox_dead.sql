@@ -0,0 +1,6 @@
+-- Сообщения, исчерпавшие попытки публикации, остаются в таблице для разбора.
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS dead_at TIMESTAMPTZ;
+
+DROP INDEX IF EXISTS outbox_pending_idx;
+CREATE INDEX IF NOT EXISTS outbox_pending_key_idx ON outbox (topic, message_key, id)
+    WHERE delivered_at IS NULL AND dead_at IS NULL;
@@ -0,0 +1,83 @@
+-- Время хранится числом микросекунд Unix (UTC).
+CREATE TABLE IF NOT EXISTS news (
+    id                    INTEGER PRIMARY KEY AUTOINCREMENT,
+    title                 TEXT    NOT NULL,
+    description           TEXT    NOT NULL DEFAULT '',
+    description_text      TEXT    NOT NULL DEFAULT '',
+    description_generated INTEGER NOT NULL DEFAULT 0,
+    content               TEXT    NOT NULL DEFAULT '',
+    content_text          TEXT    NOT NULL DEFAULT '',
+    author                TEXT    NOT NULL DEFAULT '',
+    published_at          INTEGER NOT NULL,
+    fetched_at            INTEGER NOT NULL,
+    source                TEXT    NOT NULL DEFAULT '',
+    link                  TEXT    NOT NULL UNIQUE,
+    original_link         TEXT    NOT NULL DEFAULT '',
+    language              TEXT    NOT NULL DEFAULT '',
+    category              TEXT    NOT NULL DEFAULT '',
+    feed_categories       TEXT    NOT NULL DEFAULT '[]',
+    image_key             TEXT,
+    image_source_url      TEXT,
+    image_origin          TEXT,
+    image_width           INTEGER,
+    image_height          INTEGER,
+    image_thumbnails      TEXT    NOT NULL DEFAULT '[]'
+);
+
+CREATE INDEX IF NOT EXISTS news_published_at_id_idx ON news (published_at, id);
+CREATE INDEX IF NOT EXISTS news_source_idx ON news (source);
+CREATE INDEX IF NOT EXISTS news_category_idx ON news (category);
+CREATE INDEX IF NOT EXISTS news_language_idx ON news (language);
+
+CREATE TABLE IF NOT EXISTS tags (
+    id   INTEGER PRIMARY KEY AUTOINCREMENT,
+    slug TEXT NOT NULL UNIQUE,
+    name TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS news_tags (
+    news_id  INTEGER NOT NULL REFERENCES news (id) ON DELETE CASCADE,
+    tag_id   INTEGER NOT NULL REFERENCES tags (id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    PRIMARY KEY (news_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS news_tags_tag_idx ON news_tags (tag_id);
+
+CREATE TABLE IF NOT EXISTS news_keywords (
+    news_id  INTEGER NOT NULL REFERENCES news (id) ON DELETE CASCADE,
+    keyword  TEXT    NOT NULL,
+    position INTEGER NOT NULL,
+    PRIMARY KEY (news_id, keyword)
+);
+
+CREATE TABLE IF NOT EXISTS entities (
+    id   INTEGER PRIMARY KEY AUTOINCREMENT,
+    kind TEXT NOT NULL,
+    name TEXT NOT NULL,
+    UNIQUE (kind, name)
+);
+
+CREATE TABLE IF NOT EXISTS news_entities (
+    news_id   INTEGER NOT NULL REFERENCES news (id) ON DELETE CASCADE,
+    entity_id INTEGER NOT NULL REFERENCES entities (id) ON DELETE CASCADE,
+    mentions  INTEGER NOT NULL DEFAULT 1,
+    PRIMARY KEY (news_id, entity_id)
+);
+
+CREATE INDEX IF NOT EXISTS news_entities_entity_idx ON news_entities (entity_id);
+
+CREATE TABLE IF NOT EXISTS sources (
+    url        TEXT PRIMARY KEY,
+    name       TEXT    NOT NULL DEFAULT '',
+    active     INTEGER NOT NULL DEFAULT 1,
+    created_at INTEGER NOT NULL,
+    updated_at INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS processed_messages (
+    idempotency_key TEXT PRIMARY KEY,
+    processed_at    INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS processed_messages_processed_at_idx ON processed_messages (processed_at);
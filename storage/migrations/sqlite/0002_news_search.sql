@@ -0,0 +1,30 @@
+-- Полнотекстовый индекс FTS5 по заголовку, описанию и тексту новостей.
+-- Индекс хранит только термы; текст берется из таблицы news и
+-- синхронизируется триггерами.
+CREATE VIRTUAL TABLE IF NOT EXISTS news_fts USING fts5 (
+    title,
+    description_text,
+    content_text,
+    content = 'news',
+    content_rowid = 'id',
+    tokenize = 'unicode61 remove_diacritics 2'
+);
+
+CREATE TRIGGER IF NOT EXISTS news_fts_insert AFTER INSERT ON news BEGIN
+    INSERT INTO news_fts (rowid, title, description_text, content_text)
+    VALUES (new.id, new.title, new.description_text, new.content_text);
+END;
+
+CREATE TRIGGER IF NOT EXISTS news_fts_delete AFTER DELETE ON news BEGIN
+    INSERT INTO news_fts (news_fts, rowid, title, description_text, content_text)
+    VALUES ('delete', old.id, old.title, old.description_text, old.content_text);
+END;
+
+CREATE TRIGGER IF NOT EXISTS news_fts_update AFTER UPDATE OF title, description_text, content_text ON news BEGIN
+    INSERT INTO news_fts (news_fts, rowid, title, description_text, content_text)
+    VALUES ('delete', old.id, old.title, old.description_text, old.content_text);
+    INSERT INTO news_fts (rowid, title, description_text, content_text)
+    VALUES (new.id, new.title, new.description_text, new.content_text);
+END;
+
+INSERT INTO news_fts (news_fts) VALUES ('rebuild');
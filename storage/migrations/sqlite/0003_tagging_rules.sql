@@ -0,0 +1,6 @@
+-- Документы с правилами категоризации; действует последняя версия.
+CREATE TABLE IF NOT EXISTS tagging_rules (
+    version    INTEGER PRIMARY KEY AUTOINCREMENT,
+    document   TEXT    NOT NULL,
+    created_at INTEGER NOT NULL
+);
@@ -0,0 +1,35 @@
+package storage
+
+import (
+	"fmt"
+	"log/slog"
+	"newsservice/internal/infrastructure/config"
+)
+
+// Open подключается к хранилищу новостей, выбранному в cfg.DB.Driver.
+// Дополнительные возможности хранилища проверяются приведением к
+// интерфейсам этого пакета.
+func Open(cfg config.Config, log *slog.Logger) (NewsStorage, error) {
+	switch cfg.DB.Driver {
+	case "", "postgres":
+		db, err := NewStorage(cfg, log)
+		if err != nil {
+			return nil, err
+		}
+		return db, nil
+	case "sqlite":
+		db, err := NewSQLiteStorage(cfg, log)
+		if err != nil {
+			return nil, err
+		}
+		return db, nil
+	default:
+		return nil, fmt.Errorf("unknown database driver %q", cfg.DB.Driver)
+	}
+}
+
+// Unsupported возвращает ошибку ErrUnsupported о том, что драйвер БД не
+// поддерживает возможность feature, нужную вызывающему коду.
+func Unsupported(driver, feature string) error {
+	return fmt.Errorf("database driver %q does not support %s: %w", driver, feature, ErrUnsupported)
+}
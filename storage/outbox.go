@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"newsservice/internal/domain"
+	"newsservice/internal/events"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var _ OutboxStorage = (*Storage)(nil)
+
+// OutboxMessage — сообщение, ожидающее публикации в Kafka.
+type OutboxMessage struct {
+	ID            int64
+	Topic         string
+	Key           string
+	Payload       []byte
+	Attempts      int
+	CreatedAt     time.Time
+	NextAttemptAt time.Time
+}
+
+// enqueueNewsEvents записывает события о созданных и измененных новостях фида
+// в outbox в той же транзакции, что и сами новости.
+func (s *Storage) enqueueNewsEvents(ctx context.Context, tx pgx.Tx, feed *domain.Feed) error {
+	if s.eventsTopic == "" {
+		return nil
+	}
+	for _, event := range events.FromFeed(feed, time.Now()) {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to encode news event: %w", err)
+		}
+		_, err = tx.Exec(ctx, `
+		INSERT INTO outbox (topic, message_key, payload)
+		VALUES ($1, $2, $3);`,
+			s.eventsTopic, strconv.FormatInt(event.News.ID, 10), payload,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to enqueue news event: %w", err)
+		}
+	}
+	return nil
+}
+
+// Метод для выборки недоставленных сообщений outbox в порядке записи. Сообщения
+// ключа выбираются, только если подошло время попытки для самого раннего из них,
+// поэтому ключи с повторяющимися ошибками не занимают пачку.
+func (s *Storage) FetchPendingOutbox(ctx context.Context, limit int) ([]OutboxMessage, error) {
+	rows, err := s.db.Query(ctx, `
+	SELECT id, topic, message_key, payload, attempts, created_at, next_attempt_at
+	FROM (
+		SELECT *, first_value(next_attempt_at) OVER (PARTITION BY topic, message_key ORDER BY id) AS head_attempt_at
+		FROM outbox
+		WHERE delivered_at IS NULL AND dead_at IS NULL
+	) pending
+	WHERE head_attempt_at <= now()
+	ORDER BY id
+	LIMIT $1;`, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query outbox: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []OutboxMessage
+	for rows.Next() {
+		var m OutboxMessage
+		if err := rows.Scan(&m.ID, &m.Topic, &m.Key, &m.Payload, &m.Attempts, &m.CreatedAt, &m.NextAttemptAt); err != nil {
+			return nil, fmt.Errorf("failed to scan outbox row: %w", err)
+		}
+		messages = append(messages, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return messages, nil
+}
+
+// Метод для отметки сообщения outbox доставленным
+func (s *Storage) MarkOutboxDelivered(ctx context.Context, id int64) error {
+	_, err := s.db.Exec(ctx, `UPDATE outbox SET delivered_at = now() WHERE id = $1;`, id)
+	if err != nil {
+		return fmt.Errorf("failed to mark outbox message delivered: %w", err)
+	}
+	return nil
+}
+
+// Метод для записи неудачной попытки публикации и времени следующей попытки
+func (s *Storage) MarkOutboxFailed(ctx context.Context, id int64, reason string, nextAttempt time.Time) error {
+	_, err := s.db.Exec(ctx, `
+	UPDATE outbox
+	SET attempts = attempts + 1, last_error = $2, next_attempt_at = $3
+	WHERE id = $1;`, id, reason, nextAttempt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to mark outbox message failed: %w", err)
+	}
+	return nil
+}
+
+// Метод для отметки сообщения outbox, исчерпавшего попытки публикации. Такое
+// сообщение больше не отправляется и не задерживает следующие сообщения ключа.
+func (s *Storage) MarkOutboxDead(ctx context.Context, id int64, reason string) error {
+	_, err := s.db.Exec(ctx, `
+	UPDATE outbox
+	SET attempts = attempts + 1, last_error = $2, dead_at = now()
+	WHERE id = $1;`, id, reason,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to mark outbox message dead: %w", err)
+	}
+	return nil
+}
+
+// Метод для удаления доставленных сообщений outbox старше before
+func (s *Storage) DeleteDeliveredOutbox(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := s.db.Exec(ctx, `DELETE FROM outbox WHERE delivered_at IS NOT NULL AND delivered_at < $1;`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to clean up outbox: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// Метод для подсчета отставания outbox: число недоставленных сообщений и время записи самого старого
+func (s *Storage) GetOutboxLag(ctx context.Context) (int, time.Time, error) {
+	var pending int
+	var oldest *time.Time
+	err := s.db.QueryRow(ctx, `
+	SELECT COUNT(*), MIN(created_at)
+	FROM outbox
+	WHERE delivered_at IS NULL AND dead_at IS NULL;`,
+	).Scan(&pending, &oldest)
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("failed to query outbox lag: %w", err)
+	}
+	if oldest == nil {
+		return pending, time.Time{}, nil
+	}
+	return pending, *oldest, nil
+}
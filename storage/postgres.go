@@ -5,26 +5,42 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
+	"net"
+	"net/url"
+	"newsservice/internal/domain"
 	"newsservice/internal/infrastructure/config"
 	"newsservice/internal/models"
+	"slices"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+var _ NewsStorage = (*Storage)(nil)
+
 type Storage struct {
 	db       *pgxpool.Pool
 	log      *slog.Logger
 	isClosed bool
 	mutex    sync.RWMutex
+
+	clusterWindow      time.Duration
+	clusterMaxDistance int
+	eventsTopic        string
 }
 
 func NewStorage(cfg config.Config, log *slog.Logger) (*Storage, error) {
-	connStr := fmt.Sprintf(
-		"postgres://%s:%s@%s:%d/%s?sslode=%s",
-		cfg.DB.Host, cfg.DB.Port, cfg.DB.UserName, cfg.DB.Password, cfg.DB.DBName, cfg.DB.SSLMode,
-	)
+	connStr := (&url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(cfg.DB.UserName, cfg.DB.Password),
+		Host:     net.JoinHostPort(cfg.DB.Host, cfg.DB.Port),
+		Path:     "/" + cfg.DB.DBName,
+		RawQuery: url.Values{"sslmode": {cfg.DB.SSLMode}}.Encode(),
+	}).String()
 
 	db, err := pgxpool.New(context.Background(), connStr)
 	if err != nil {
@@ -36,36 +52,33 @@ func NewStorage(cfg config.Config, log *slog.Logger) (*Storage, error) {
 	}
 
 	log.Info("Database connettion established")
+	clusterWindow := cfg.Ingest.Dedup.Window
+	if clusterWindow <= 0 {
+		clusterWindow = defaultClusterWindow
+	}
+	clusterMaxDistance := cfg.Ingest.Dedup.MaxDistance
+	if clusterMaxDistance <= 0 {
+		clusterMaxDistance = defaultClusterMaxDistance
+	}
+
 	return &Storage{
-		db:  db,
-		log: log,
+		db:                 db,
+		log:                log,
+		clusterWindow:      clusterWindow,
+		clusterMaxDistance: clusterMaxDistance,
+		eventsTopic:        cfg.Kafka.Topics.NewsEvents,
 	}, nil
 }
 
 // Метод для подсчета количества новостей для пагинации
 func (s *Storage) GetNewsCount(ctx context.Context, filter models.NewsFilter) (int, error) {
-	query := `SELECT COUNT(*) FROM news WHERE 1=1`
-	args := []interface{}{}
-	argPos := 1
-
-	if filter.Category != "" {
-		query += fmt.Sprintf(" AND category = $%d", argPos)
-		args = append(args, filter.Category)
-		argPos++
-	}
-	if filter.Author != "" {
-		query += fmt.Sprintf(" AND author = $%d", argPos)
-		args = append(args, filter.Author)
-		argPos++
-	}
-	if !filter.Date.IsZero() {
-		query += fmt.Sprintf(" AND DATE(publisher_at) = $%d", argPos)
-		args = append(args, filter.Date.Format("2006-01-02"))
-		argPos++
+	c, err := newsConditions(filter)
+	if err != nil {
+		return 0, err
 	}
 
 	var count int
-	err := s.db.QueryRow(ctx, query, args...).Scan(&count)
+	err = s.db.QueryRow(ctx, `SELECT COUNT(*) FROM news`+c.where(), c.args...).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count news: %w", err)
 	}
@@ -75,23 +88,53 @@ func (s *Storage) GetNewsCount(ctx context.Context, filter models.NewsFilter) (i
 
 // Метод для выборки новостей из БД по newsID
 func (s *Storage) GetDetailedNews(ctx context.Context, newsID int) (models.NewsFullDetailed, error) {
-	query := `SELECT id, title, description, content, author, published_at, source, link FROM news WHERE id = $1;`
+	query := `
+	SELECT
+	id,
+	title,
+	description,
+	description_text,
+	description_generated,
+	content,
+	content_text,
+	author,
+	published_at,
+	source,
+	link,
+	original_link,
+	language,
+	category,
+	` + newsTagsSelect + `,
+	feed_categories,
+	` + imageColumnsSelect + `
+	FROM news
+	WHERE id = $1;`
 	rows := s.db.QueryRow(ctx, query, newsID)
 
 	post := models.NewsFullDetailed{}
-	err := rows.Scan(
+	var image imageColumns
+	err := rows.Scan(append([]any{
 		&post.NewsID,
 		&post.Title,
 		&post.Description,
+		&post.DescriptionText,
+		&post.DescriptionGenerated,
+		&post.Content,
+		&post.ContentText,
 		&post.Author,
 		&post.PublishedAt,
 		&post.Source,
 		&post.Link,
-	)
+		&post.OriginalLink,
+		&post.Language,
+		&post.Category,
+		&post.Tag,
+		&post.FeedCategories,
+	}, image.targets()...)...)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			s.log.Warn("News not found", "newsID", newsID)
-			return models.NewsFullDetailed{}, fmt.Errorf("news with ID %d not found", newsID)
+			return models.NewsFullDetailed{}, fmt.Errorf("news with ID %d: %w", newsID, ErrNotFound)
 		}
 
 		s.log.Error(
@@ -101,6 +144,13 @@ func (s *Storage) GetDetailedNews(ctx context.Context, newsID int) (models.NewsF
 		)
 		return models.NewsFullDetailed{}, fmt.Errorf("unable scan row: %w", err)
 	}
+	if post.Image, err = image.model(); err != nil {
+		return models.NewsFullDetailed{}, err
+	}
+
+	if err := s.attachExtraction(ctx, &post); err != nil {
+		return models.NewsFullDetailed{}, err
+	}
 
 	return post, nil
 }
@@ -109,87 +159,115 @@ func (s *Storage) GetDetailedNews(ctx context.Context, newsID int) (models.NewsF
 func (s *Storage) GetNewsByFilter(ctx context.Context, filter models.NewsFilter) ([]models.NewsFullDetailed, error) {
 	query := `
 	SELECT
-	news_id,
+	id,
 	title,
 	description,
+	description_text,
+	description_generated,
 	content,
+	content_text,
 	author,
 	published_at,
 	source,
 	link,
-	category
-	FROM news
-	WHERE 1=1
-	`
-
-	args := []interface{}{}
-	argPos := 1
-
-	if filter.Category != "" {
-		query += fmt.Sprintf(" AND category = $%d", argPos)
-		args = append(args, filter.Category)
-		argPos++
-	}
-	if filter.Author != "" {
-		query += fmt.Sprintf(" AND author = $%d", argPos)
-		args = append(args, filter.Author)
-		argPos++
-	}
-	if !filter.Date.IsZero() {
-		query += fmt.Sprintf(" AND DATE(published_at) = $%d", argPos)
-		args = append(args, filter.Date.Format("2006-01-02"))
-		argPos++
+	original_link,
+	language,
+	category,
+	` + newsTagsSelect + `,
+	feed_categories,
+	cluster_id,
+	` + imageColumnsSelect + `
+	FROM news`
+
+	c, err := newsConditions(filter)
+	if err != nil {
+		return nil, err
 	}
-	if filter.OrderBy != "" {
-		query += " ORDER BY " + filter.OrderBy
+	var order string
+	if filter.Keyset != nil {
+		order, err = keysetClause(c, *filter.Keyset)
 	} else {
-		query += " ORDER BY published_at DESC"
+		order, err = orderClause(c, filter.Sort, filter.Query)
 	}
+	if err != nil {
+		return nil, err
+	}
+	query += c.where() + order
 	if filter.Limit > 0 {
-		query += fmt.Sprintf(" LIMIT $%d", argPos)
-		args = append(args, filter.Limit)
-		argPos++
+		query += " LIMIT " + c.arg(filter.Limit)
 	}
 	if filter.Offset > 0 {
-		query += fmt.Sprintf(" OFFSET $%d", argPos)
-		args = append(args, filter.Offset)
+		query += " OFFSET " + c.arg(filter.Offset)
 	}
 
-	rows, err := s.db.Query(ctx, query, args...)
+	rows, err := s.db.Query(ctx, query, c.args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query news: %w", err)
 	}
 	defer rows.Close()
 
 	var news []models.NewsFullDetailed
+	var clusters []*int64
 	for rows.Next() {
 		var item models.NewsFullDetailed
+		var clusterID *int64
+		var image imageColumns
 
-		err := rows.Scan(
+		err := rows.Scan(append([]any{
 			&item.NewsID,
 			&item.Title,
 			&item.Description,
+			&item.DescriptionText,
+			&item.DescriptionGenerated,
 			&item.Content,
+			&item.ContentText,
 			&item.Author,
 			&item.PublishedAt,
 			&item.Source,
 			&item.Link,
-		)
+			&item.OriginalLink,
+			&item.Language,
+			&item.Category,
+			&item.Tag,
+			&item.FeedCategories,
+			&clusterID,
+		}, image.targets()...)...)
 
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan news row: %w", err)
 		}
+		if item.Image, err = image.model(); err != nil {
+			return nil, err
+		}
 
 		news = append(news, item)
+		clusters = append(clusters, clusterID)
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("rows iteration error: %w", err)
 	}
+	rows.Close()
+
+	if filter.Keyset != nil && filter.Keyset.Backward {
+		slices.Reverse(news)
+		slices.Reverse(clusters)
+	}
+	if filter.Collapse != models.CollapseNone {
+		if err := s.attachAlsoReportedBy(ctx, news, clusters); err != nil {
+			return nil, err
+		}
+	}
 
 	return news, nil
 }
 
+// likePattern экранирует спецсимволы LIKE и ищет подстроку целиком.
+func likePattern(text string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(text)
+	return "%" + escaped + "%"
+}
+
 func (s *Storage) Close() {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
@@ -208,7 +286,205 @@ func (s *Storage) Close() {
 	s.isClosed = true
 }
 
-// TO DO: func (s *Storage) SaveNews(ctx context.Context, feed *domain.Feed) (int, error)
+// Метод для сохранения новостей фида в БД. Возвращает количество новых записей.
+func (s *Storage) SaveNews(ctx context.Context, feed *domain.Feed) (int, error) {
+	if len(feed.Items) == 0 {
+		return 0, nil
+	}
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		s.log.Error(
+			"Failed to begin transaction",
+			slog.Any("error", err),
+		)
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	versions, err := loadVersions(ctx, tx, feed.Items)
+	if err != nil {
+		s.log.Error(
+			"Failed to load current news versions",
+			slog.Any("error", err),
+		)
+		return 0, err
+	}
+
+	query := `
+	INSERT INTO news (
+		title, description, description_text, content, content_text, published_at, source, link, original_link,
+		author, simhash, language, category, feed_categories, description_generated,
+		image_key, image_source_url, image_origin, image_width, image_height, image_thumbnails
+	)
+	VALUES (
+		$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13,
+		COALESCE($14, '{}'::TEXT[]), $15,
+		$16, $17, $18, $19, $20, $21
+	)
+	ON CONFLICT (link) DO UPDATE SET
+		title = EXCLUDED.title,
+		description = EXCLUDED.description,
+		description_text = EXCLUDED.description_text,
+		description_generated = EXCLUDED.description_generated,
+		content = EXCLUDED.content,
+		content_text = EXCLUDED.content_text,
+		author = EXCLUDED.author,
+		published_at = EXCLUDED.published_at,
+		simhash = EXCLUDED.simhash,
+		language = EXCLUDED.language,
+		category = EXCLUDED.category,
+		feed_categories = EXCLUDED.feed_categories,
+		image_key = COALESCE(EXCLUDED.image_key, news.image_key),
+		image_source_url = COALESCE(EXCLUDED.image_source_url, news.image_source_url),
+		image_origin = COALESCE(EXCLUDED.image_origin, news.image_origin),
+		image_width = COALESCE(EXCLUDED.image_width, news.image_width),
+		image_height = COALESCE(EXCLUDED.image_height, news.image_height),
+		image_thumbnails = CASE WHEN EXCLUDED.image_key IS NULL
+			THEN news.image_thumbnails ELSE EXCLUDED.image_thumbnails END
+	WHERE (news.title, news.description, news.content, news.published_at)
+		IS DISTINCT FROM (EXCLUDED.title, EXCLUDED.description, EXCLUDED.content, EXCLUDED.published_at)
+		OR (EXCLUDED.image_key IS NOT NULL AND news.image_key IS DISTINCT FROM EXCLUDED.image_key)
+	RETURNING id, (xmax = 0) AS inserted;
+	`
+	batch := &pgx.Batch{}
+	for _, item := range feed.Items {
+		imageKey, imageSource, imageOrigin, imageWidth, imageHeight, thumbnails, err := imageParams(item.Image)
+		if err != nil {
+			return 0, err
+		}
+		batch.Queue(
+			query,
+			item.Title,
+			item.Description,
+			item.DescriptionText,
+			item.Content,
+			item.ContentText,
+			item.PubDate,
+			feed.Source,
+			item.Link,
+			item.OriginalLink,
+			item.Author,
+			nullableFingerprint(item.Fingerprint),
+			item.Language,
+			item.Category,
+			item.Categories,
+			item.DescriptionGenerated,
+			imageKey,
+			imageSource,
+			imageOrigin,
+			imageWidth,
+			imageHeight,
+			thumbnails,
+		)
+	}
+
+	results := tx.SendBatch(ctx, batch)
+	saved := 0
+	var created []clusterItem
+	written := make(map[int64]domain.Item)
+	for i := range feed.Items {
+		item := &feed.Items[i]
+		var id int64
+		var inserted bool
+		err := results.QueryRow().Scan(&id, &inserted)
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			results.Close()
+			s.log.Error(
+				"Failed to execute batch",
+				slog.Any("error", err),
+			)
+			return 0, fmt.Errorf("failed to execute batch: %w", err)
+		}
+		item.ID = id
+		item.Status = domain.StatusUpdated
+		written[id] = *item
+		if inserted {
+			item.Status = domain.StatusCreated
+			saved++
+			if item.Fingerprint != 0 {
+				created = append(created, clusterItem{
+					id:          id,
+					fingerprint: item.Fingerprint,
+					publishedAt: item.PubDate,
+				})
+			}
+		}
+	}
+	if err := results.Close(); err != nil {
+		return 0, fmt.Errorf("failed to close batch: %w", err)
+	}
+
+	for id, item := range written {
+		if prev, ok := versions[item.Link]; ok && prev.id == id && prev.changed(item) {
+			if err := saveRevision(ctx, tx, prev, item); err != nil {
+				s.log.Error(
+					"Failed to save news revision",
+					slog.Any("error", err),
+				)
+				return 0, err
+			}
+		}
+		if err := saveNewsTags(ctx, tx, id, item.Category, item.Tags); err != nil {
+			s.log.Error(
+				"Failed to save news tags",
+				slog.Any("error", err),
+			)
+			return 0, err
+		}
+		if err := s.saveExtraction(ctx, tx, id, item); err != nil {
+			s.log.Error(
+				"Failed to save extracted keywords and entities",
+				slog.Any("error", err),
+			)
+			return 0, err
+		}
+	}
+
+	if err := s.clusterNews(ctx, tx, created); err != nil {
+		s.log.Error(
+			"Failed to cluster news",
+			slog.Any("error", err),
+		)
+		return 0, fmt.Errorf("failed to cluster news: %w", err)
+	}
+	if err := refreshClusters(ctx, tx, slices.Collect(maps.Keys(written))); err != nil {
+		s.log.Error(
+			"Failed to refresh story clusters",
+			slog.Any("error", err),
+		)
+		return 0, err
+	}
+
+	if err := s.enqueueNewsEvents(ctx, tx, feed); err != nil {
+		s.log.Error(
+			"Failed to enqueue news events",
+			slog.Any("error", err),
+		)
+		return 0, err
+	}
+	if feed.IdempotencyKey != "" {
+		if err := markProcessed(ctx, tx, feed.IdempotencyKey); err != nil {
+			s.log.Error(
+				"Failed to save idempotency key",
+				slog.Any("error", err),
+			)
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		s.log.Error(
+			"Failed to commit transaction",
+			slog.Any("error", err),
+		)
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return saved, nil
+}
 
 // ---------------------------------------------------------------------------------------------------------------------------
 // Метод для выборки из БД всех новостей
@@ -0,0 +1,139 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"newsservice/internal/domain"
+	"newsservice/internal/models"
+	"newsservice/internal/textdiff"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var _ RevisionStorage = (*Storage)(nil)
+
+// storedVersion — версия новости, хранящаяся в БД до обновления.
+type storedVersion struct {
+	id              int64
+	title           string
+	description     string
+	descriptionText string
+	content         string
+	contentText     string
+	publishedAt     time.Time
+}
+
+// changed сообщает, изменился ли текст заголовка, описания или статьи.
+// Правки только пробелов или разметки ревизию не создают.
+func (v storedVersion) changed(item domain.Item) bool {
+	return textdiff.Changed(v.title, item.Title) ||
+		textdiff.Changed(v.descriptionText, item.DescriptionText) ||
+		textdiff.Changed(v.contentText, item.ContentText)
+}
+
+// loadVersions блокирует существующие записи с указанными ссылками и
+// возвращает их текущие версии.
+func loadVersions(ctx context.Context, tx pgx.Tx, items []domain.Item) (map[string]storedVersion, error) {
+	links := make([]string, len(items))
+	for i, item := range items {
+		links[i] = item.Link
+	}
+	rows, err := tx.Query(ctx, `
+	SELECT id, link, title, description, description_text, content, content_text, published_at
+	FROM news
+	WHERE link = ANY($1)
+	FOR UPDATE;`, links,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query current news versions: %w", err)
+	}
+	defer rows.Close()
+
+	versions := make(map[string]storedVersion)
+	for rows.Next() {
+		var v storedVersion
+		var link string
+		err := rows.Scan(&v.id, &link, &v.title, &v.description, &v.descriptionText, &v.content, &v.contentText, &v.publishedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan news version: %w", err)
+		}
+		versions[link] = v
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return versions, nil
+}
+
+// saveRevision сохраняет предыдущую версию новости вместе с пословным сравнением
+// измененных полей.
+func saveRevision(ctx context.Context, tx pgx.Tx, prev storedVersion, item domain.Item) error {
+	diff := make(map[string][]textdiff.Op)
+	if textdiff.Changed(prev.title, item.Title) {
+		diff["title"] = textdiff.Words(prev.title, item.Title)
+	}
+	if textdiff.Changed(prev.descriptionText, item.DescriptionText) {
+		diff["description"] = textdiff.Words(prev.descriptionText, item.DescriptionText)
+	}
+	if textdiff.Changed(prev.contentText, item.ContentText) {
+		diff["content"] = textdiff.Words(prev.contentText, item.ContentText)
+	}
+	encoded, err := json.Marshal(diff)
+	if err != nil {
+		return fmt.Errorf("failed to encode revision diff: %w", err)
+	}
+	_, err = tx.Exec(ctx, `
+	INSERT INTO news_revisions (news_id, revision, title, description, content, published_at, diff)
+	SELECT $1, COALESCE(MAX(revision), 0) + 1, $2, $3, $4, $5, $6
+	FROM news_revisions
+	WHERE news_id = $1;`,
+		prev.id, prev.title, prev.description, prev.content, prev.publishedAt, encoded,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save news revision: %w", err)
+	}
+	return nil
+}
+
+// Метод для выборки истории изменений новости, от новых правок к старым
+func (s *Storage) GetNewsRevisions(ctx context.Context, newsID int) ([]models.NewsRevision, error) {
+	var exists bool
+	err := s.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM news WHERE id = $1);`, newsID).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check news existence: %w", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("news with ID %d: %w", newsID, ErrNotFound)
+	}
+
+	rows, err := s.db.Query(ctx, `
+	SELECT revision, title, description, content, published_at, replaced_at, diff
+	FROM news_revisions
+	WHERE news_id = $1
+	ORDER BY revision DESC;`, newsID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query news revisions: %w", err)
+	}
+	defer rows.Close()
+
+	revisions := []models.NewsRevision{}
+	for rows.Next() {
+		var rev models.NewsRevision
+		var diff []byte
+		err := rows.Scan(&rev.Revision, &rev.Title, &rev.Description, &rev.Content, &rev.PublishedAt, &rev.ReplacedAt, &diff)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan news revision: %w", err)
+		}
+		if err := json.Unmarshal(diff, &rev.Diff); err != nil {
+			return nil, fmt.Errorf("failed to decode revision diff: %w", err)
+		}
+		revisions = append(revisions, rev)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return revisions, nil
+}
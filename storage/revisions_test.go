@@ -0,0 +1,36 @@
+package storage
+
+import (
+	"newsservice/internal/domain"
+	"testing"
+)
+
+func TestStoredVersionChanged(t *testing.T) {
+	prev := storedVersion{
+		title:           "Курс рубля",
+		description:     "<p>Курс  вырос</p>",
+		descriptionText: "Курс  вырос",
+		content:         "<p>Текст</p>",
+		contentText:     "Текст",
+	}
+	cases := []struct {
+		name string
+		item domain.Item
+		want bool
+	}{
+		{"same", domain.Item{Title: "Курс рубля", Description: "<p>Курс  вырос</p>", DescriptionText: "Курс  вырос",
+			Content: "<p>Текст</p>", ContentText: "Текст"}, false},
+		{"whitespace only", domain.Item{Title: " Курс\nрубля ", Description: "<p>Курс вырос</p>", DescriptionText: "Курс вырос",
+			Content: "<p>Текст</p>\n", ContentText: "Текст "}, false},
+		{"markup only", domain.Item{Title: "Курс рубля", Description: "<p><b>Курс</b> вырос</p>", DescriptionText: "Курс вырос",
+			Content: "<div>Текст</div>", ContentText: "Текст"}, false},
+		{"title", domain.Item{Title: "Курс евро", DescriptionText: "Курс вырос", ContentText: "Текст"}, true},
+		{"description", domain.Item{Title: "Курс рубля", DescriptionText: "Курс упал", ContentText: "Текст"}, true},
+		{"content", domain.Item{Title: "Курс рубля", DescriptionText: "Курс вырос", ContentText: "Новый текст"}, true},
+	}
+	for _, c := range cases {
+		if got := prev.changed(c.item); got != c.want {
+			t.Fatalf("%s: changed() = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,300 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"newsservice/internal/models"
+	"newsservice/internal/search"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	_ search.Searcher    = (*Storage)(nil)
+	_ SearchIndexStorage = (*Storage)(nil)
+)
+
+// searchConfigs — конфигурации полнотекстового поиска для языков новостей.
+// Должны совпадать с функцией news_search_config из миграции 0014.
+var searchConfigs = []struct {
+	language string
+	config   string
+}{
+	{"ru", "russian"},
+	{"en", "english"},
+}
+
+// defaultSearchConfig используется для остальных языков.
+const defaultSearchConfig = "simple"
+
+const (
+	highlightStart  = "<mark>"
+	highlightStop   = "</mark>"
+	headlineOptions = "StartSel=<mark>, StopSel=</mark>, MaxWords=35, MinWords=15, MaxFragments=2, FragmentDelimiter=\" … \""
+)
+
+// Метод для полнотекстового поиска новостей с ранжированием по релевантности
+func (s *Storage) Search(ctx context.Context, req search.Request) (search.Result, error) {
+	args := []any{tsQuery(req.Query)}
+	where := searchMatchClause(1)
+	conditions, args := searchFilterClause(req.Filter, args)
+	where += conditions
+
+	var total int
+	if err := s.db.QueryRow(ctx, `SELECT COUNT(*) FROM news WHERE `+where, args...).Scan(&total); err != nil {
+		return search.Result{}, fmt.Errorf("failed to count search results: %w", err)
+	}
+	result := search.Result{Total: total, Hits: []models.SearchResult{}}
+	if req.Facets {
+		facets, err := s.searchFacets(ctx, where, args)
+		if err != nil {
+			return search.Result{}, err
+		}
+		result.Facets = facets
+	}
+	if total == 0 {
+		return result, nil
+	}
+
+	tsq := `to_tsquery(news_search_config(language), $1)`
+	sql := `
+	SELECT
+	id,
+	title,
+	ts_headline(news_search_config(language), title, ` + tsq + `, 'HighlightAll=true'),
+	ts_headline(news_search_config(language), COALESCE(NULLIF(content_text, ''), description_text), ` + tsq + `, '` + headlineOptions + `'),
+	link,
+	source,
+	language,
+	category,
+	published_at,
+	ts_rank_cd(search_vector, ` + tsq + `, 32) AS rank
+	FROM news
+	WHERE ` + where + `
+	ORDER BY rank DESC, published_at DESC, id DESC`
+	if req.Limit > 0 {
+		args = append(args, req.Limit)
+		sql += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	if req.Offset > 0 {
+		args = append(args, req.Offset)
+		sql += fmt.Sprintf(" OFFSET $%d", len(args))
+	}
+
+	rows, err := s.db.Query(ctx, sql, args...)
+	if err != nil {
+		return search.Result{}, fmt.Errorf("failed to search news: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r models.SearchResult
+		err := rows.Scan(
+			&r.NewsID,
+			&r.Title,
+			&r.TitleHighlight,
+			&r.Snippet,
+			&r.Link,
+			&r.Source,
+			&r.Language,
+			&r.Category,
+			&r.PublishedAt,
+			&r.Rank,
+		)
+		if err != nil {
+			return search.Result{}, fmt.Errorf("failed to scan search result: %w", err)
+		}
+		r.TitleHighlight = escapeHighlight(r.TitleHighlight)
+		r.Snippet = escapeHighlight(r.Snippet)
+		result.Hits = append(result.Hits, r)
+	}
+	if err := rows.Err(); err != nil {
+		return search.Result{}, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return result, nil
+}
+
+// Index не требуется: search_vector обновляется триггером при сохранении новости.
+func (s *Storage) Index(ctx context.Context, docs ...search.Document) error {
+	return nil
+}
+
+// searchFacets подсчитывает фасеты по всем новостям, удовлетворяющим условию where.
+func (s *Storage) searchFacets(ctx context.Context, where string, args []any) (*search.Facets, error) {
+	facets := &search.Facets{}
+	for _, f := range []struct {
+		column string
+		dst    *[]search.FacetValue
+	}{
+		{"source", &facets.Sources},
+		{"category", &facets.Categories},
+		{"language", &facets.Languages},
+	} {
+		rows, err := s.db.Query(ctx, fmt.Sprintf(`
+		SELECT %[1]s, COUNT(*) FROM news
+		WHERE %[2]s AND %[1]s <> ''
+		GROUP BY %[1]s
+		ORDER BY 2 DESC, 1
+		LIMIT %[3]d`, f.column, where, search.MaxFacetValues), args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count %s facet: %w", f.column, err)
+		}
+		values, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (search.FacetValue, error) {
+			var v search.FacetValue
+			err := row.Scan(&v.Value, &v.Count)
+			return v, err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan %s facet: %w", f.column, err)
+		}
+		*f.dst = values
+	}
+
+	rows, err := s.db.Query(ctx, `
+	SELECT to_char(published_at AT TIME ZONE 'UTC', 'YYYY-MM-DD') AS day, COUNT(*) FROM news
+	WHERE `+where+`
+	GROUP BY day
+	ORDER BY day`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count date facet: %w", err)
+	}
+	facets.Dates, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (search.DateBucket, error) {
+		var b search.DateBucket
+		err := row.Scan(&b.Date, &b.Count)
+		return b, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan date facet: %w", err)
+	}
+	return facets, nil
+}
+
+// Метод для выборки новостей в поисковый индекс порциями по возрастанию ID
+func (s *Storage) ListNewsForIndex(ctx context.Context, afterID int64, limit int) ([]search.Document, error) {
+	return s.queryIndexDocuments(ctx, `WHERE id > $1 ORDER BY id LIMIT $2`, afterID, limit)
+}
+
+// Метод для выборки новостей в поисковый индекс по ID; отсутствующие пропускаются
+func (s *Storage) GetNewsForIndex(ctx context.Context, ids ...int64) ([]search.Document, error) {
+	return s.queryIndexDocuments(ctx, `WHERE id = ANY($1) ORDER BY id`, ids)
+}
+
+func (s *Storage) queryIndexDocuments(ctx context.Context, tail string, args ...any) ([]search.Document, error) {
+	rows, err := s.db.Query(ctx, `
+	SELECT id, source, title, description_text, content_text, link, author, language, category, published_at
+	FROM news `+tail, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query news for search index: %w", err)
+	}
+	defer rows.Close()
+
+	var docs []search.Document
+	for rows.Next() {
+		var d search.Document
+		var description, content string
+		err := rows.Scan(&d.ID, &d.Source, &d.Title, &description, &content, &d.Link, &d.Author, &d.Language, &d.Category, &d.PublishedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan news row: %w", err)
+		}
+		d.Text = strings.TrimSpace(description + "\n\n" + content)
+		docs = append(docs, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return docs, nil
+}
+
+// searchMatchClause сопоставляет запрос с search_vector в конфигурации языка
+// новости. Каждая ветка использует постоянную конфигурацию, поэтому
+// условие обслуживается GIN-индексом.
+func searchMatchClause(argPos int) string {
+	branches := make([]string, 0, len(searchConfigs)+1)
+	languages := make([]string, 0, len(searchConfigs))
+	for _, c := range searchConfigs {
+		branches = append(branches, fmt.Sprintf(
+			"(language = '%s' AND search_vector @@ to_tsquery('%s', $%d))", c.language, c.config, argPos))
+		languages = append(languages, "'"+c.language+"'")
+	}
+	branches = append(branches, fmt.Sprintf(
+		"(language NOT IN (%s) AND search_vector @@ to_tsquery('%s', $%d))",
+		strings.Join(languages, ", "), defaultSearchConfig, argPos))
+	return "(" + strings.Join(branches, " OR ") + ")"
+}
+
+// searchFilterClause добавляет к поиску условия фильтра.
+func searchFilterClause(filter search.Filter, args []any) (string, []any) {
+	var clause strings.Builder
+	add := func(format string, value any) {
+		args = append(args, value)
+		fmt.Fprintf(&clause, format, len(args))
+	}
+	if filter.Source != "" {
+		add(" AND source = $%d", filter.Source)
+	}
+	if filter.Category != "" {
+		add(" AND category = $%d", filter.Category)
+	}
+	if filter.Language != "" {
+		add(" AND language = $%d", filter.Language)
+	}
+	if filter.Author != "" {
+		add(" AND author = $%d", filter.Author)
+	}
+	if !filter.From.IsZero() {
+		add(" AND published_at >= $%d", filter.From)
+	}
+	if !filter.To.IsZero() {
+		add(" AND published_at < $%d", filter.To)
+	}
+	return clause.String(), args
+}
+
+// tsQuery переводит запрос в синтаксис to_tsquery. Слова запроса состоят
+// только из букв и цифр, поэтому экранирование не требуется.
+func tsQuery(q search.Query) string {
+	groups := make([]string, 0, len(q.Groups))
+	for _, g := range q.Groups {
+		terms := make([]string, 0, len(g.Terms))
+		for _, t := range g.Terms {
+			expr := strings.Join(t.Words, " <-> ")
+			if t.Prefix {
+				expr += ":*"
+			}
+			if t.Negated {
+				if t.Phrase() {
+					expr = "(" + expr + ")"
+				}
+				expr = "!" + expr
+			}
+			terms = append(terms, expr)
+		}
+		groups = append(groups, "("+strings.Join(terms, " & ")+")")
+	}
+	return strings.Join(groups, " | ")
+}
+
+// escapeHighlight экранирует текст фрагмента, сохраняя теги выделения.
+func escapeHighlight(text string) string {
+	var b strings.Builder
+	for {
+		start := strings.Index(text, highlightStart)
+		if start < 0 {
+			break
+		}
+		stop := strings.Index(text[start:], highlightStop)
+		if stop < 0 {
+			break
+		}
+		stop += start
+		b.WriteString(html.EscapeString(text[:start]))
+		b.WriteString(highlightStart)
+		b.WriteString(html.EscapeString(text[start+len(highlightStart) : stop]))
+		b.WriteString(highlightStop)
+		text = text[stop+len(highlightStop):]
+	}
+	b.WriteString(html.EscapeString(text))
+	return b.String()
+}
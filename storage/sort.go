@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"fmt"
+	"newsservice/internal/models"
+	"strings"
+)
+
+// sortColumns — SQL-выражения для полей сортировки. В ORDER BY попадают
+// только выражения из этого списка, значения из запроса туда не подставляются.
+var sortColumns = map[string]string{
+	models.SortPublishedAt: "published_at",
+	models.SortFetchedAt:   "fetched_at",
+	models.SortTitle:       "title",
+	models.SortSource:      "source",
+}
+
+// orderClause строит ORDER BY по спецификации сортировки. Сортировка по
+// релевантности ранжирует совпадения с query, передаваемым параметром c.
+func orderClause(c *sqlConditions, sort []models.SortField, query string) (string, error) {
+	return sortClause(sort, func(field string) (string, error) {
+		if field == models.SortRelevance {
+			if query == "" {
+				return "", fmt.Errorf("sort by relevance requires a search query")
+			}
+			return "ts_rank(search_vector, plainto_tsquery(news_search_config(language), " + c.arg(query) + "))", nil
+		}
+		column, ok := sortColumns[field]
+		if !ok {
+			return "", fmt.Errorf("unknown sort field %q", field)
+		}
+		return column, nil
+	})
+}
+
+// sortClause строит ORDER BY из выражений, которые column возвращает для
+// полей сортировки; пустая спецификация сортирует по убыванию даты
+// публикации. Последним ключом всегда идет id, чтобы порядок страниц был устойчивым.
+func sortClause(sort []models.SortField, column func(field string) (string, error)) (string, error) {
+	if len(sort) == 0 {
+		return " ORDER BY published_at DESC, id DESC", nil
+	}
+	keys := make([]string, 0, len(sort)+1)
+	for _, f := range sort {
+		var direction string
+		switch f.Order {
+		case models.SortAsc:
+			direction = "ASC"
+		case models.SortDesc:
+			direction = "DESC"
+		default:
+			return "", fmt.Errorf("unknown sort order %q", f.Order)
+		}
+		expr, err := column(f.Field)
+		if err != nil {
+			return "", err
+		}
+		keys = append(keys, expr+" "+direction)
+	}
+	keys = append(keys, "id DESC")
+	return " ORDER BY " + strings.Join(keys, ", "), nil
+}
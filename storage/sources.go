@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"newsservice/internal/domain"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	_ SourceStorage      = (*Storage)(nil)
+	_ IdempotencyStorage = (*Storage)(nil)
+)
+
+// Метод для получения всех источников реестра, включая отключенные
+func (s *Storage) ListSources(ctx context.Context) ([]domain.Source, error) {
+	rows, err := s.db.Query(ctx,
+		`SELECT url, name, active, updated_at FROM sources ORDER BY created_at, url;`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sources: %w", err)
+	}
+	defer rows.Close()
+
+	var sources []domain.Source
+	for rows.Next() {
+		var src domain.Source
+		if err := rows.Scan(&src.URL, &src.Name, &src.Active, &src.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan source row: %w", err)
+		}
+		sources = append(sources, src)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return sources, nil
+}
+
+// Метод для добавления источника или изменения его имени и состояния.
+// Пустое имя не затирает сохраненное.
+func (s *Storage) SaveSource(ctx context.Context, source domain.Source) error {
+	_, err := s.db.Exec(ctx, `
+	INSERT INTO sources (url, name, active)
+	VALUES ($1, $2, $3)
+	ON CONFLICT (url) DO UPDATE SET
+		name = COALESCE(NULLIF(EXCLUDED.name, ''), sources.name),
+		active = EXCLUDED.active,
+		updated_at = now();`,
+		source.URL, source.Name, source.Active,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save source %s: %w", source.URL, err)
+	}
+	return nil
+}
+
+// Метод для проверки, было ли сообщение с ключом уже обработано
+func (s *Storage) IsMessageProcessed(ctx context.Context, key string) (bool, error) {
+	var exists bool
+	err := s.db.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM processed_messages WHERE idempotency_key = $1);`, key,
+	).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check idempotency key: %w", err)
+	}
+	return exists, nil
+}
+
+// Метод для сохранения ключа обработанного сообщения
+func (s *Storage) MarkMessageProcessed(ctx context.Context, key string) error {
+	_, err := s.db.Exec(ctx, markProcessedQuery, key)
+	if err != nil {
+		return fmt.Errorf("failed to save idempotency key: %w", err)
+	}
+	return nil
+}
+
+const markProcessedQuery = `INSERT INTO processed_messages (idempotency_key) VALUES ($1) ON CONFLICT DO NOTHING;`
+
+// markProcessed сохраняет ключ обработанного сообщения в транзакции сохранения новостей.
+func markProcessed(ctx context.Context, tx pgx.Tx, key string) error {
+	if _, err := tx.Exec(ctx, markProcessedQuery, key); err != nil {
+		return fmt.Errorf("failed to save idempotency key: %w", err)
+	}
+	return nil
+}
+
+// Метод для удаления ключей сообщений, обработанных раньше before
+func (s *Storage) DeleteProcessedMessages(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := s.db.Exec(ctx,
+		`DELETE FROM processed_messages WHERE processed_at < $1;`, before,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete processed messages: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
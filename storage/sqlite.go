@@ -0,0 +1,672 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/url"
+	"newsservice/internal/domain"
+	"newsservice/internal/infrastructure/config"
+	"newsservice/internal/models"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"modernc.org/sqlite"
+)
+
+var (
+	_ NewsStorage        = (*SQLiteStorage)(nil)
+	_ SourceStorage      = (*SQLiteStorage)(nil)
+	_ IdempotencyStorage = (*SQLiteStorage)(nil)
+	_ Migrator           = (*SQLiteStorage)(nil)
+)
+
+//go:embed migrations/sqlite/*.sql
+var sqliteMigrations embed.FS
+
+// sqliteMemory — путь БД SQLite в памяти. Такая БД живет, пока открыто
+// единственное соединение.
+const sqliteMemory = ":memory:"
+
+func init() {
+	// lower в SQLite приводит к нижнему регистру только латиницу.
+	sqlite.MustRegisterDeterministicScalarFunction("unicode_lower", 1,
+		func(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+			s, ok := args[0].(string)
+			if !ok {
+				return args[0], nil
+			}
+			return strings.ToLower(s), nil
+		})
+}
+
+// SQLiteStorage — хранилище новостей в файле SQLite для небольших
+// развертываний с одним узлом. Поддерживает выборку, фильтрацию и поиск
+// новостей, реестр источников, ключи идемпотентности, правила и повторное
+// тегирование, выборку для поискового индекса и статистику языков.
+// Сюжеты, история правок, справочники тегов и категорий и outbox не ведутся:
+// свертка по сюжетам отклоняется ошибкой ErrUnsupported, маршруты истории
+// правок и справочников не регистрируются, а события news_events не публикуются.
+type SQLiteStorage struct {
+	db       *sql.DB
+	log      *slog.Logger
+	isClosed bool
+	mutex    sync.Mutex
+}
+
+func NewSQLiteStorage(cfg config.Config, log *slog.Logger) (*SQLiteStorage, error) {
+	dbPath := cfg.DB.Path
+	if dbPath == "" {
+		return nil, errors.New("sqlite database path is empty")
+	}
+	if dbPath != sqliteMemory {
+		if err := os.MkdirAll(filepath.Dir(dbPath), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create sqlite database directory: %w", err)
+		}
+	}
+	// Транзакции сразу берут блокировку записи, поэтому одновременные
+	// записи ждут друг друга busy_timeout вместо ошибки SQLITE_BUSY.
+	params := url.Values{
+		"_pragma": {"foreign_keys(1)", "busy_timeout(5000)", "journal_mode(WAL)"},
+		"_txlock": {"immediate"},
+	}
+	db, err := sql.Open("sqlite", "file:"+dbPath+"?"+params.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite database: %w", err)
+	}
+	if dbPath == sqliteMemory {
+		db.SetMaxOpenConns(1)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to open sqlite database: %w", err)
+	}
+
+	log.Info("SQLite database opened", slog.String("path", dbPath))
+	return &SQLiteStorage{db: db, log: log}, nil
+}
+
+// Migrate применяет к БД еще не выполненные миграции в порядке их номеров.
+func (s *SQLiteStorage) Migrate(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, `
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    TEXT PRIMARY KEY,
+		applied_at INTEGER NOT NULL
+	);`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	files, err := migrationFiles(sqliteMigrations, "migrations/sqlite")
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		version := strings.TrimSuffix(path.Base(file), ".sql")
+
+		var applied bool
+		err := s.db.QueryRowContext(ctx,
+			`SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)`, version,
+		).Scan(&applied)
+		if err != nil {
+			return fmt.Errorf("failed to check migration %s: %w", version, err)
+		}
+		if applied {
+			continue
+		}
+
+		body, err := fs.ReadFile(sqliteMigrations, file)
+		if err != nil {
+			return fmt.Errorf("failed to read migration %s: %w", version, err)
+		}
+
+		tx, err := s.db.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("failed to begin migration %s: %w", version, err)
+		}
+		if _, err := tx.ExecContext(ctx, string(body)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to apply migration %s: %w", version, err)
+		}
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO schema_migrations (version, applied_at) VALUES ($1, $2)`, version, time.Now().UnixMicro(),
+		)
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to record migration %s: %w", version, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit migration %s: %w", version, err)
+		}
+		s.log.Info("Migration applied", slog.String("version", version))
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) Close() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.isClosed {
+		s.log.Debug("Storage already closed")
+		return
+	}
+	if err := s.db.Close(); err != nil {
+		s.log.Error("Failed to close SQLite database", slog.Any("error", err))
+	} else {
+		s.log.Info("SQLite database closed successfully")
+	}
+	s.isClosed = true
+}
+
+// sqliteNewsColumns — колонки новости в порядке scanSQLiteNews.
+const sqliteNewsColumns = `
+	id,
+	title,
+	description,
+	description_text,
+	description_generated,
+	content,
+	content_text,
+	author,
+	published_at,
+	source,
+	link,
+	original_link,
+	language,
+	category,
+	(SELECT json_group_array(t.slug ORDER BY nt.position)
+		FROM news_tags nt JOIN tags t ON t.id = nt.tag_id
+		WHERE nt.news_id = news.id),
+	feed_categories,
+	` + imageColumnsSelect
+
+type sqlScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanSQLiteNews читает новость, выбранную колонками sqliteNewsColumns.
+func scanSQLiteNews(row sqlScanner) (models.NewsFullDetailed, error) {
+	var news models.NewsFullDetailed
+	var publishedAt int64
+	var tags, feedCategories string
+	var image imageColumns
+	err := row.Scan(append([]any{
+		&news.NewsID,
+		&news.Title,
+		&news.Description,
+		&news.DescriptionText,
+		&news.DescriptionGenerated,
+		&news.Content,
+		&news.ContentText,
+		&news.Author,
+		&publishedAt,
+		&news.Source,
+		&news.Link,
+		&news.OriginalLink,
+		&news.Language,
+		&news.Category,
+		&tags,
+		&feedCategories,
+	}, image.targets()...)...)
+	if err != nil {
+		return models.NewsFullDetailed{}, err
+	}
+	news.PublishedAt = time.UnixMicro(publishedAt).UTC()
+	if err := json.Unmarshal([]byte(tags), &news.Tag); err != nil {
+		return models.NewsFullDetailed{}, fmt.Errorf("failed to decode news tags: %w", err)
+	}
+	if err := json.Unmarshal([]byte(feedCategories), &news.FeedCategories); err != nil {
+		return models.NewsFullDetailed{}, fmt.Errorf("failed to decode feed categories: %w", err)
+	}
+	if news.Image, err = image.model(); err != nil {
+		return models.NewsFullDetailed{}, err
+	}
+	return news, nil
+}
+
+// Метод для подсчета количества новостей для пагинации
+func (s *SQLiteStorage) GetNewsCount(ctx context.Context, filter models.NewsFilter) (int, error) {
+	c, err := sqliteNewsConditions(filter)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	err = s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM news`+c.where(), c.args...).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count news: %w", err)
+	}
+	return count, nil
+}
+
+// Метод для выборки новости по newsID
+func (s *SQLiteStorage) GetDetailedNews(ctx context.Context, newsID int) (models.NewsFullDetailed, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+sqliteNewsColumns+` FROM news WHERE id = $1;`, newsID)
+	news, err := scanSQLiteNews(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			s.log.Warn("News not found", "newsID", newsID)
+			return models.NewsFullDetailed{}, fmt.Errorf("news with ID %d: %w", newsID, ErrNotFound)
+		}
+		s.log.Error(
+			"Failed to get detailed news from database",
+			slog.Any("error", err),
+			slog.Int("newsID", newsID),
+		)
+		return models.NewsFullDetailed{}, fmt.Errorf("unable scan row: %w", err)
+	}
+
+	if err := s.attachExtraction(ctx, &news); err != nil {
+		return models.NewsFullDetailed{}, err
+	}
+	return news, nil
+}
+
+// attachExtraction дополняет новость ключевыми словами и упомянутыми сущностями.
+func (s *SQLiteStorage) attachExtraction(ctx context.Context, news *models.NewsFullDetailed) error {
+	rows, err := s.db.QueryContext(ctx, `
+	SELECT keyword FROM news_keywords WHERE news_id = $1 ORDER BY position;`, news.NewsID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to query news keywords: %w", err)
+	}
+	defer rows.Close()
+	news.Keywords = []string{}
+	for rows.Next() {
+		var keyword string
+		if err := rows.Scan(&keyword); err != nil {
+			return fmt.Errorf("failed to scan news keywords: %w", err)
+		}
+		news.Keywords = append(news.Keywords, keyword)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to scan news keywords: %w", err)
+	}
+
+	rows, err = s.db.QueryContext(ctx, `
+	SELECT e.id, e.kind, e.name, ne.mentions
+	FROM news_entities ne
+	JOIN entities e ON e.id = ne.entity_id
+	WHERE ne.news_id = $1
+	ORDER BY ne.mentions DESC, e.name;`, news.NewsID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to query news entities: %w", err)
+	}
+	defer rows.Close()
+	news.Entities = []models.Entity{}
+	for rows.Next() {
+		var e models.Entity
+		if err := rows.Scan(&e.ID, &e.Kind, &e.Name, &e.Mentions); err != nil {
+			return fmt.Errorf("failed to scan news entities: %w", err)
+		}
+		news.Entities = append(news.Entities, e)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to scan news entities: %w", err)
+	}
+	return nil
+}
+
+// Метод для выборки новостей из БД с фильтрацией и пагинацией
+func (s *SQLiteStorage) GetNewsByFilter(ctx context.Context, filter models.NewsFilter) ([]models.NewsFullDetailed, error) {
+	c, err := sqliteNewsConditions(filter)
+	if err != nil {
+		return nil, err
+	}
+	var order string
+	if filter.Keyset != nil {
+		order, err = keysetClause(c, *filter.Keyset)
+	} else {
+		order, err = sqliteOrderClause(c, filter.Sort, filter.Query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	query := `SELECT ` + sqliteNewsColumns + ` FROM news` + c.where() + order
+	if filter.Limit > 0 {
+		query += " LIMIT " + c.arg(filter.Limit)
+	} else if filter.Offset > 0 {
+		query += " LIMIT -1"
+	}
+	if filter.Offset > 0 {
+		query += " OFFSET " + c.arg(filter.Offset)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, c.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query news: %w", err)
+	}
+	defer rows.Close()
+
+	var news []models.NewsFullDetailed
+	for rows.Next() {
+		item, err := scanSQLiteNews(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan news row: %w", err)
+		}
+		news = append(news, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	if filter.Keyset != nil && filter.Keyset.Backward {
+		slices.Reverse(news)
+	}
+	return news, nil
+}
+
+// Метод для сохранения новостей фида в БД. Возвращает количество новых записей.
+// Как и Storage, перезаписывает новость с той же ссылкой, только если
+// изменились заголовок, описание, текст, дата публикации или изображение.
+func (s *SQLiteStorage) SaveNews(ctx context.Context, feed *domain.Feed) (int, error) {
+	if len(feed.Items) == 0 {
+		return 0, nil
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		s.log.Error(
+			"Failed to begin transaction",
+			slog.Any("error", err),
+		)
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	saved := 0
+	for i := range feed.Items {
+		item := &feed.Items[i]
+		id, status, err := saveSQLiteNews(ctx, tx, feed.Source, *item)
+		if err != nil {
+			s.log.Error(
+				"Failed to save news",
+				slog.Any("error", err),
+				slog.String("link", item.Link),
+			)
+			return 0, err
+		}
+		if status == domain.StatusUnchanged {
+			continue
+		}
+		item.ID = id
+		item.Status = status
+		if status == domain.StatusCreated {
+			saved++
+		}
+
+		if err := saveSQLiteExtraction(ctx, tx, id, *item); err != nil {
+			s.log.Error(
+				"Failed to save news tags, keywords and entities",
+				slog.Any("error", err),
+			)
+			return 0, err
+		}
+	}
+	if feed.IdempotencyKey != "" {
+		if err := markSQLiteProcessed(ctx, tx, feed.IdempotencyKey); err != nil {
+			s.log.Error(
+				"Failed to save idempotency key",
+				slog.Any("error", err),
+			)
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.log.Error(
+			"Failed to commit transaction",
+			slog.Any("error", err),
+		)
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return saved, nil
+}
+
+// saveSQLiteNews добавляет новость или перезаписывает измененную новость с той же ссылкой.
+func saveSQLiteNews(ctx context.Context, tx *sql.Tx, source string, item domain.Item) (int64, domain.SaveStatus, error) {
+	imageKey, imageSource, imageOrigin, imageWidth, imageHeight, thumbnails, err := imageParams(item.Image)
+	if err != nil {
+		return 0, "", err
+	}
+	feedCategories, err := json.Marshal(item.Categories)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to encode feed categories: %w", err)
+	}
+	if item.Categories == nil {
+		feedCategories = []byte("[]")
+	}
+
+	var id int64
+	var title, description, content string
+	var publishedAt int64
+	var storedImageKey sql.NullString
+	err = tx.QueryRowContext(ctx, `
+	SELECT id, title, description, content, published_at, image_key FROM news WHERE link = $1;`, item.Link,
+	).Scan(&id, &title, &description, &content, &publishedAt, &storedImageKey)
+	if errors.Is(err, sql.ErrNoRows) {
+		res, err := tx.ExecContext(ctx, `
+		INSERT INTO news (
+			title, description, description_text, content, content_text, published_at, fetched_at, source, link,
+			original_link, author, language, category, feed_categories, description_generated,
+			image_key, image_source_url, image_origin, image_width, image_height, image_thumbnails
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21);`,
+			item.Title, item.Description, item.DescriptionText, item.Content, item.ContentText,
+			item.PubDate.UnixMicro(), time.Now().UnixMicro(), source, item.Link,
+			item.OriginalLink, item.Author, item.Language, item.Category, string(feedCategories), item.DescriptionGenerated,
+			imageKey, imageSource, imageOrigin, imageWidth, imageHeight, string(thumbnails),
+		)
+		if err != nil {
+			return 0, "", fmt.Errorf("failed to insert news: %w", err)
+		}
+		if id, err = res.LastInsertId(); err != nil {
+			return 0, "", fmt.Errorf("failed to get news id: %w", err)
+		}
+		return id, domain.StatusCreated, nil
+	}
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to load current news version: %w", err)
+	}
+
+	changed := title != item.Title || description != item.Description || content != item.Content ||
+		publishedAt != item.PubDate.UnixMicro() ||
+		(item.Image != nil && (!storedImageKey.Valid || storedImageKey.String != item.Image.Key))
+	if !changed {
+		return id, domain.StatusUnchanged, nil
+	}
+	_, err = tx.ExecContext(ctx, `
+	UPDATE news SET
+		title = $2,
+		description = $3,
+		description_text = $4,
+		description_generated = $5,
+		content = $6,
+		content_text = $7,
+		author = $8,
+		published_at = $9,
+		language = $10,
+		category = $11,
+		feed_categories = $12,
+		image_key = COALESCE($13, image_key),
+		image_source_url = COALESCE($14, image_source_url),
+		image_origin = COALESCE($15, image_origin),
+		image_width = COALESCE($16, image_width),
+		image_height = COALESCE($17, image_height),
+		image_thumbnails = CASE WHEN $13 IS NULL THEN image_thumbnails ELSE $18 END
+	WHERE id = $1;`,
+		id, item.Title, item.Description, item.DescriptionText, item.DescriptionGenerated,
+		item.Content, item.ContentText, item.Author, item.PubDate.UnixMicro(),
+		item.Language, item.Category, string(feedCategories),
+		imageKey, imageSource, imageOrigin, imageWidth, imageHeight, string(thumbnails),
+	)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to update news: %w", err)
+	}
+	return id, domain.StatusUpdated, nil
+}
+
+// saveSQLiteTags заменяет теги новости; неизвестные теги создаются.
+func saveSQLiteTags(ctx context.Context, tx *sql.Tx, newsID int64, tags []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM news_tags WHERE news_id = $1;`, newsID); err != nil {
+		return fmt.Errorf("failed to clear news tags: %w", err)
+	}
+	for i, slug := range uniqueNonEmpty(tags) {
+		_, err := tx.ExecContext(ctx, `INSERT INTO tags (slug, name) VALUES ($1, $1) ON CONFLICT (slug) DO NOTHING;`, slug)
+		if err != nil {
+			return fmt.Errorf("failed to save tag: %w", err)
+		}
+		_, err = tx.ExecContext(ctx, `
+		INSERT INTO news_tags (news_id, tag_id, position)
+		SELECT $1, id, $3 FROM tags WHERE slug = $2;`, newsID, slug, i,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to link tag to news: %w", err)
+		}
+	}
+	return nil
+}
+
+// saveSQLiteExtraction заменяет теги, ключевые слова и упоминания сущностей новости.
+func saveSQLiteExtraction(ctx context.Context, tx *sql.Tx, newsID int64, item domain.Item) error {
+	if err := saveSQLiteTags(ctx, tx, newsID, item.Tags); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM news_keywords WHERE news_id = $1;`, newsID); err != nil {
+		return fmt.Errorf("failed to clear news keywords: %w", err)
+	}
+	for i, keyword := range item.Keywords {
+		_, err := tx.ExecContext(ctx, `
+		INSERT INTO news_keywords (news_id, keyword, position)
+		VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING;`, newsID, keyword, i,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to save news keyword: %w", err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM news_entities WHERE news_id = $1;`, newsID); err != nil {
+		return fmt.Errorf("failed to clear news entities: %w", err)
+	}
+	for _, entity := range item.Entities {
+		var entityID int64
+		err := tx.QueryRowContext(ctx, `
+		INSERT INTO entities (kind, name)
+		VALUES ($1, $2)
+		ON CONFLICT (kind, name) DO UPDATE SET name = excluded.name
+		RETURNING id;`, entity.Kind, entity.Name,
+		).Scan(&entityID)
+		if err != nil {
+			return fmt.Errorf("failed to save entity: %w", err)
+		}
+		_, err = tx.ExecContext(ctx, `
+		INSERT INTO news_entities (news_id, entity_id, mentions)
+		VALUES ($1, $2, $3);`, newsID, entityID, entity.Mentions,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to link entity to news: %w", err)
+		}
+	}
+	return nil
+}
+
+// Метод для получения всех источников реестра, включая отключенные
+func (s *SQLiteStorage) ListSources(ctx context.Context) ([]domain.Source, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT url, name, active, updated_at FROM sources ORDER BY created_at, url;`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sources: %w", err)
+	}
+	defer rows.Close()
+
+	var sources []domain.Source
+	for rows.Next() {
+		var src domain.Source
+		var updatedAt int64
+		if err := rows.Scan(&src.URL, &src.Name, &src.Active, &updatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan source row: %w", err)
+		}
+		src.UpdatedAt = time.UnixMicro(updatedAt).UTC()
+		sources = append(sources, src)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return sources, nil
+}
+
+// Метод для добавления источника или изменения его имени и состояния.
+// Пустое имя не затирает сохраненное.
+func (s *SQLiteStorage) SaveSource(ctx context.Context, source domain.Source) error {
+	_, err := s.db.ExecContext(ctx, `
+	INSERT INTO sources (url, name, active, created_at, updated_at)
+	VALUES ($1, $2, $3, $4, $4)
+	ON CONFLICT (url) DO UPDATE SET
+		name = COALESCE(NULLIF(excluded.name, ''), sources.name),
+		active = excluded.active,
+		updated_at = excluded.updated_at;`,
+		source.URL, source.Name, source.Active, time.Now().UnixMicro(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save source %s: %w", source.URL, err)
+	}
+	return nil
+}
+
+// Метод для проверки, было ли сообщение с ключом уже обработано
+func (s *SQLiteStorage) IsMessageProcessed(ctx context.Context, key string) (bool, error) {
+	var exists bool
+	err := s.db.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM processed_messages WHERE idempotency_key = $1);`, key,
+	).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check idempotency key: %w", err)
+	}
+	return exists, nil
+}
+
+// Метод для сохранения ключа обработанного сообщения
+func (s *SQLiteStorage) MarkMessageProcessed(ctx context.Context, key string) error {
+	_, err := s.db.ExecContext(ctx, markSQLiteProcessedQuery, key, time.Now().UnixMicro())
+	if err != nil {
+		return fmt.Errorf("failed to save idempotency key: %w", err)
+	}
+	return nil
+}
+
+const markSQLiteProcessedQuery = `INSERT INTO processed_messages (idempotency_key, processed_at) VALUES ($1, $2) ON CONFLICT DO NOTHING;`
+
+// markSQLiteProcessed сохраняет ключ обработанного сообщения в транзакции сохранения новостей.
+func markSQLiteProcessed(ctx context.Context, tx *sql.Tx, key string) error {
+	if _, err := tx.ExecContext(ctx, markSQLiteProcessedQuery, key, time.Now().UnixMicro()); err != nil {
+		return fmt.Errorf("failed to save idempotency key: %w", err)
+	}
+	return nil
+}
+
+// Метод для удаления ключей сообщений, обработанных раньше before
+func (s *SQLiteStorage) DeleteProcessedMessages(ctx context.Context, before time.Time) (int64, error) {
+	res, err := s.db.ExecContext(ctx,
+		`DELETE FROM processed_messages WHERE processed_at < $1;`, before.UnixMicro(),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete processed messages: %w", err)
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"fmt"
+	"newsservice/internal/models"
+	"strings"
+	"unicode"
+)
+
+// sqliteNewsConditions строит условия выборки новостей по фильтру для SQLite
+// с той же семантикой, что и newsConditions.
+func sqliteNewsConditions(filter models.NewsFilter) (*sqlConditions, error) {
+	c := &sqlConditions{unixMicro: true}
+
+	if len(filter.Sources) > 0 {
+		c.add("source IN (" + argList(c, filter.Sources) + ")")
+	}
+	if len(filter.ExcludeSources) > 0 {
+		c.add("source NOT IN (" + argList(c, filter.ExcludeSources) + ")")
+	}
+	if len(filter.Categories) > 0 {
+		c.add("category IN (" + argList(c, filter.Categories) + ")")
+	}
+	if len(filter.ExcludeCategories) > 0 {
+		c.add("category NOT IN (" + argList(c, filter.ExcludeCategories) + ")")
+	}
+	const newsTagSlugs = `SELECT t.slug FROM news_tags nt JOIN tags t ON t.id = nt.tag_id WHERE nt.news_id = news.id`
+	if len(filter.Tags) > 0 {
+		tags := uniqueNonEmpty(filter.Tags)
+		switch filter.TagsMatch {
+		case models.TagsMatchAny, "":
+			c.add("EXISTS (" + newsTagSlugs + " AND t.slug IN (" + argList(c, tags) + "))")
+		case models.TagsMatchAll:
+			c.add(fmt.Sprintf("(SELECT COUNT(*) FROM (%s AND t.slug IN (%s))) = %d", newsTagSlugs, argList(c, tags), len(tags)))
+		default:
+			return nil, fmt.Errorf("unknown tags match mode %q", filter.TagsMatch)
+		}
+	}
+	if len(filter.ExcludeTags) > 0 {
+		c.add("NOT EXISTS (" + newsTagSlugs + " AND t.slug IN (" + argList(c, filter.ExcludeTags) + "))")
+	}
+	if len(filter.Languages) > 0 {
+		c.add("language IN (" + argList(c, filter.Languages) + ")")
+	}
+	if filter.Author != "" {
+		c.add("author = " + c.arg(filter.Author))
+	}
+	if !filter.From.IsZero() {
+		c.add("published_at >= " + c.timeArg(filter.From))
+	}
+	if !filter.To.IsZero() {
+		c.add("published_at < " + c.timeArg(filter.To))
+	}
+	if filter.HasImage != nil {
+		if *filter.HasImage {
+			c.add("COALESCE(image_key, '') <> ''")
+		} else {
+			c.add("COALESCE(image_key, '') = ''")
+		}
+	}
+	if filter.Entity != "" {
+		c.add(`EXISTS (
+		SELECT 1 FROM news_entities ne JOIN entities e ON e.id = ne.entity_id
+		WHERE ne.news_id = news.id AND unicode_lower(e.name) = ` + c.arg(strings.ToLower(filter.Entity)) + `)`)
+	}
+	if filter.Query != "" {
+		query := c.arg(strings.ToLower(filter.Query))
+		c.add(fmt.Sprintf("(instr(unicode_lower(title), %[1]s) > 0 OR instr(unicode_lower(description_text), %[1]s) > 0 "+
+			"OR instr(unicode_lower(content_text), %[1]s) > 0)", query))
+	}
+	// Сюжеты в SQLite не строятся, свертка не поддерживается.
+	if err := unclusteredCollapse(filter.Collapse); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// sqliteOrderClause строит ORDER BY для SQLite. Релевантность — ранг bm25
+// совпадения слов query в полнотекстовом индексе.
+func sqliteOrderClause(c *sqlConditions, sort []models.SortField, query string) (string, error) {
+	return sortClause(sort, func(field string) (string, error) {
+		if field == models.SortRelevance {
+			if query == "" {
+				return "", fmt.Errorf("sort by relevance requires a search query")
+			}
+			match := ftsWords(query)
+			if match == "" {
+				return "0", nil
+			}
+			return "COALESCE((SELECT -bm25(news_fts) FROM news_fts WHERE news_fts MATCH " + c.arg(match) +
+				" AND news_fts.rowid = news.id), 0)", nil
+		}
+		column, ok := sortColumns[field]
+		if !ok {
+			return "", fmt.Errorf("unknown sort field %q", field)
+		}
+		return column, nil
+	})
+}
+
+// argList добавляет значения параметрами и возвращает их плейсхолдеры через запятую.
+func argList(c *sqlConditions, values []string) string {
+	placeholders := make([]string, len(values))
+	for i, v := range values {
+		placeholders[i] = c.arg(v)
+	}
+	return strings.Join(placeholders, ", ")
+}
+
+// ftsWords составляет запрос FTS5, в котором должны встретиться все слова text.
+func ftsWords(text string) string {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, w := range words {
+		words[i] = `"` + w + `"`
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,274 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"newsservice/internal/models"
+	"newsservice/internal/search"
+	"strings"
+	"time"
+)
+
+var (
+	_ search.Searcher    = (*SQLiteStorage)(nil)
+	_ SearchIndexStorage = (*SQLiteStorage)(nil)
+)
+
+const (
+	// ftsSearchFrom — найденные новости вместе с их записями в news_fts.
+	ftsSearchFrom = ` FROM news_fts JOIN news ON news.id = news_fts.rowid`
+	// ftsSnippetWords — число слов во фрагменте текста.
+	ftsSnippetWords = 35
+)
+
+// Метод для полнотекстового поиска новостей с ранжированием по релевантности.
+// В отличие от PostgreSQL слова не приводятся к основе: для поиска форм
+// слова используется префиксный запрос.
+func (s *SQLiteStorage) Search(ctx context.Context, req search.Request) (search.Result, error) {
+	c := &sqlConditions{unixMicro: true}
+	c.add("news_fts MATCH " + c.arg(ftsQuery(req.Query)))
+	sqliteSearchConditions(c, req.Filter)
+	where := c.where()
+
+	var total int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*)`+ftsSearchFrom+where, c.args...).Scan(&total); err != nil {
+		return search.Result{}, fmt.Errorf("failed to count search results: %w", err)
+	}
+	result := search.Result{Total: total, Hits: []models.SearchResult{}}
+	if req.Facets {
+		facets, err := s.searchFacets(ctx, where, c.args)
+		if err != nil {
+			return search.Result{}, err
+		}
+		result.Facets = facets
+	}
+	if total == 0 {
+		return result, nil
+	}
+
+	query := fmt.Sprintf(`
+	SELECT
+	news.id,
+	news.title,
+	highlight(news_fts, 0, '%[1]s', '%[2]s'),
+	CASE WHEN news.content_text <> ''
+		THEN snippet(news_fts, 2, '%[1]s', '%[2]s', ' … ', %[3]d)
+		ELSE snippet(news_fts, 1, '%[1]s', '%[2]s', ' … ', %[3]d)
+	END,
+	news.link,
+	news.source,
+	news.language,
+	news.category,
+	news.published_at,
+	-bm25(news_fts, 4.0, 2.0, 1.0) AS rank`, highlightStart, highlightStop, ftsSnippetWords) +
+		ftsSearchFrom + where + `
+	ORDER BY rank DESC, news.published_at DESC, news.id DESC`
+	if req.Limit > 0 {
+		query += " LIMIT " + c.arg(req.Limit)
+	} else if req.Offset > 0 {
+		query += " LIMIT -1"
+	}
+	if req.Offset > 0 {
+		query += " OFFSET " + c.arg(req.Offset)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, c.args...)
+	if err != nil {
+		return search.Result{}, fmt.Errorf("failed to search news: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r models.SearchResult
+		var publishedAt int64
+		err := rows.Scan(
+			&r.NewsID,
+			&r.Title,
+			&r.TitleHighlight,
+			&r.Snippet,
+			&r.Link,
+			&r.Source,
+			&r.Language,
+			&r.Category,
+			&publishedAt,
+			&r.Rank,
+		)
+		if err != nil {
+			return search.Result{}, fmt.Errorf("failed to scan search result: %w", err)
+		}
+		r.PublishedAt = time.UnixMicro(publishedAt).UTC()
+		r.TitleHighlight = escapeHighlight(r.TitleHighlight)
+		r.Snippet = escapeHighlight(r.Snippet)
+		result.Hits = append(result.Hits, r)
+	}
+	if err := rows.Err(); err != nil {
+		return search.Result{}, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return result, nil
+}
+
+// Index не требуется: news_fts обновляется триггерами при сохранении новости.
+func (s *SQLiteStorage) Index(ctx context.Context, docs ...search.Document) error {
+	return nil
+}
+
+// Метод для постраничной выборки новостей в поисковый индекс, упорядоченных по id
+func (s *SQLiteStorage) ListNewsForIndex(ctx context.Context, afterID int64, limit int) ([]search.Document, error) {
+	return s.queryIndexDocuments(ctx, `WHERE id > $1 ORDER BY id LIMIT $2`, afterID, limit)
+}
+
+// Метод для выборки новостей в поисковый индекс по ID; отсутствующие пропускаются
+func (s *SQLiteStorage) GetNewsForIndex(ctx context.Context, ids ...int64) ([]search.Document, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	c := &sqlConditions{}
+	placeholders := make([]string, len(ids))
+	for i, id := range ids {
+		placeholders[i] = c.arg(id)
+	}
+	return s.queryIndexDocuments(ctx, `WHERE id IN (`+strings.Join(placeholders, ", ")+`) ORDER BY id`, c.args...)
+}
+
+func (s *SQLiteStorage) queryIndexDocuments(ctx context.Context, tail string, args ...any) ([]search.Document, error) {
+	rows, err := s.db.QueryContext(ctx, `
+	SELECT id, source, title, description_text, content_text, link, author, language, category, published_at
+	FROM news `+tail, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query news for search index: %w", err)
+	}
+
+	var docs []search.Document
+	err = scanRows(rows, func(rows *sql.Rows) error {
+		var d search.Document
+		var description, content string
+		var publishedAt int64
+		if err := rows.Scan(&d.ID, &d.Source, &d.Title, &description, &content, &d.Link, &d.Author, &d.Language, &d.Category, &publishedAt); err != nil {
+			return err
+		}
+		d.PublishedAt = time.UnixMicro(publishedAt).UTC()
+		d.Text = strings.TrimSpace(description + "\n\n" + content)
+		docs = append(docs, d)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan news row: %w", err)
+	}
+	return docs, nil
+}
+
+// searchFacets подсчитывает фасеты по всем новостям, удовлетворяющим условию where.
+func (s *SQLiteStorage) searchFacets(ctx context.Context, where string, args []any) (*search.Facets, error) {
+	facets := &search.Facets{}
+	for _, f := range []struct {
+		column string
+		dst    *[]search.FacetValue
+	}{
+		{"source", &facets.Sources},
+		{"category", &facets.Categories},
+		{"language", &facets.Languages},
+	} {
+		rows, err := s.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT news.%[1]s, COUNT(*)`+ftsSearchFrom+where+` AND news.%[1]s <> ''
+		GROUP BY news.%[1]s
+		ORDER BY 2 DESC, 1
+		LIMIT %[2]d`, f.column, search.MaxFacetValues), args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count %s facet: %w", f.column, err)
+		}
+		values := []search.FacetValue{}
+		err = scanRows(rows, func(rows *sql.Rows) error {
+			var v search.FacetValue
+			if err := rows.Scan(&v.Value, &v.Count); err != nil {
+				return err
+			}
+			values = append(values, v)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan %s facet: %w", f.column, err)
+		}
+		*f.dst = values
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+	SELECT strftime('%Y-%m-%d', news.published_at / 1000000, 'unixepoch') AS day, COUNT(*)`+ftsSearchFrom+where+`
+	GROUP BY day
+	ORDER BY day`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count date facet: %w", err)
+	}
+	facets.Dates = []search.DateBucket{}
+	err = scanRows(rows, func(rows *sql.Rows) error {
+		var b search.DateBucket
+		if err := rows.Scan(&b.Date, &b.Count); err != nil {
+			return err
+		}
+		facets.Dates = append(facets.Dates, b)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan date facet: %w", err)
+	}
+	return facets, nil
+}
+
+// scanRows вызывает scan для каждой строки и закрывает rows.
+func scanRows(rows *sql.Rows, scan func(rows *sql.Rows) error) error {
+	defer rows.Close()
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// sqliteSearchConditions добавляет к поиску условия фильтра.
+func sqliteSearchConditions(c *sqlConditions, filter search.Filter) {
+	if filter.Source != "" {
+		c.add("news.source = " + c.arg(filter.Source))
+	}
+	if filter.Category != "" {
+		c.add("news.category = " + c.arg(filter.Category))
+	}
+	if filter.Language != "" {
+		c.add("news.language = " + c.arg(filter.Language))
+	}
+	if filter.Author != "" {
+		c.add("news.author = " + c.arg(filter.Author))
+	}
+	if !filter.From.IsZero() {
+		c.add("news.published_at >= " + c.timeArg(filter.From))
+	}
+	if !filter.To.IsZero() {
+		c.add("news.published_at < " + c.timeArg(filter.To))
+	}
+}
+
+// ftsQuery переводит запрос в синтаксис FTS5. Слова запроса состоят только
+// из букв и цифр и заключаются в кавычки, поэтому экранирование не требуется.
+func ftsQuery(q search.Query) string {
+	groups := make([]string, 0, len(q.Groups))
+	for _, g := range q.Groups {
+		var positive, negative []string
+		for _, t := range g.Terms {
+			expr := `"` + strings.Join(t.Words, " ") + `"`
+			if t.Prefix {
+				expr += " *"
+			}
+			if t.Negated {
+				negative = append(negative, expr)
+			} else {
+				positive = append(positive, expr)
+			}
+		}
+		expr := strings.Join(positive, " AND ")
+		for _, n := range negative {
+			expr += " NOT " + n
+		}
+		groups = append(groups, "("+expr+")")
+	}
+	return strings.Join(groups, " OR ")
+}
@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"newsservice/internal/models"
+)
+
+var (
+	_ TaggingStorage = (*SQLiteStorage)(nil)
+	_ AdminStorage   = (*SQLiteStorage)(nil)
+)
+
+// Метод для получения актуального документа с правилами категоризации
+func (s *SQLiteStorage) GetTaggingRules(ctx context.Context) ([]byte, error) {
+	var document []byte
+	err := s.db.QueryRowContext(ctx,
+		`SELECT document FROM tagging_rules ORDER BY version DESC LIMIT 1;`,
+	).Scan(&document)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("no tagging rules stored in database")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tagging rules: %w", err)
+	}
+	return document, nil
+}
+
+// Метод для постраничной выборки новостей, упорядоченных по id, для повторного тегирования
+func (s *SQLiteStorage) ListNewsForRetag(ctx context.Context, afterID int, limit int) ([]models.NewsFullDetailed, error) {
+	rows, err := s.db.QueryContext(ctx, `
+	SELECT `+sqliteNewsColumns+`
+	FROM news
+	WHERE id > $1
+	ORDER BY id
+	LIMIT $2;`, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query news for retag: %w", err)
+	}
+
+	var news []models.NewsFullDetailed
+	err = scanRows(rows, func(rows *sql.Rows) error {
+		item, err := scanSQLiteNews(rows)
+		if err != nil {
+			return err
+		}
+		news = append(news, item)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan news row: %w", err)
+	}
+	return news, nil
+}
+
+// Метод для обновления категории и тегов новости
+func (s *SQLiteStorage) UpdateNewsTags(ctx context.Context, newsID int, category string, tags []string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `UPDATE news SET category = $2 WHERE id = $1;`, newsID, category); err != nil {
+		return fmt.Errorf("failed to update category of news %d: %w", newsID, err)
+	}
+	if err := saveSQLiteTags(ctx, tx, int64(newsID), tags); err != nil {
+		return fmt.Errorf("failed to update tags of news %d: %w", newsID, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// Метод для подсчета распределения новостей каждого источника по языкам
+func (s *SQLiteStorage) GetLanguageStats(ctx context.Context) ([]models.SourceLanguageStats, error) {
+	rows, err := s.db.QueryContext(ctx, `
+	SELECT source, language, COUNT(*)
+	FROM news
+	GROUP BY source, language
+	ORDER BY source, language;`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query language stats: %w", err)
+	}
+
+	stats := []models.SourceLanguageStats{}
+	err = scanRows(rows, func(rows *sql.Rows) error {
+		var source, language string
+		var count int
+		if err := rows.Scan(&source, &language, &count); err != nil {
+			return err
+		}
+		stats = addLanguageStats(stats, source, language, count)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan language stats row: %w", err)
+	}
+	return stats, nil
+}
@@ -0,0 +1,339 @@
+package storage_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"maps"
+	"newsservice/internal/domain"
+	"newsservice/internal/infrastructure/config"
+	"newsservice/internal/models"
+	"newsservice/internal/search"
+	"newsservice/storage"
+	"newsservice/storage/storagetest"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func openSQLite(t *testing.T) *storage.SQLiteStorage {
+	t.Helper()
+	var cfg config.Config
+	cfg.DB.Path = filepath.Join(t.TempDir(), "news.db")
+	db, err := storage.NewSQLiteStorage(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(db.Close)
+	if err := db.Migrate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestSQLiteStorage(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.NewsStorage {
+		return openSQLite(t)
+	})
+}
+
+func TestSQLiteSearch(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	published := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	feed := &domain.Feed{Source: "bbc", Items: []domain.Item{
+		{Title: "Oil prices climb", Link: "a", PubDate: published, Language: "en", Category: "economy",
+			ContentText: "Brent crude <rose> after the OPEC meeting in Vienna"},
+		{Title: "Gas exports fall", Link: "b", PubDate: published.Add(time.Hour), Language: "en", Category: "economy",
+			DescriptionText: "Oil and gas markets react"},
+		{Title: "Нефть дорожает", Link: "c", PubDate: published.AddDate(0, 0, 1), Language: "ru", Category: "economy",
+			ContentText: "Цены на нефть выросли"},
+	}}
+	if _, err := db.SaveNews(ctx, feed); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(text string, filter search.Filter) search.Result {
+		t.Helper()
+		q, err := search.ParseQuery(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := db.Search(ctx, search.Request{Query: q, Filter: filter, Limit: 10, Facets: true})
+		if err != nil {
+			t.Fatalf("Search(%q): %v", text, err)
+		}
+		return result
+	}
+	links := func(r search.Result) []string {
+		var links []string
+		for _, h := range r.Hits {
+			links = append(links, h.Link)
+		}
+		return links
+	}
+
+	r := run("oil", search.Filter{})
+	if r.Total != 2 || !slices.Equal(links(r), []string{"a", "b"}) {
+		t.Fatalf("oil: total = %d, hits = %v; want title match first", r.Total, links(r))
+	}
+	hit := r.Hits[0]
+	if hit.TitleHighlight != "<mark>Oil</mark> prices climb" || hit.Rank <= 0 || !hit.PublishedAt.Equal(published) {
+		t.Errorf("hit = %+v", hit)
+	}
+	if r.Facets == nil || len(r.Facets.Languages) != 1 || r.Facets.Languages[0] != (search.FacetValue{Value: "en", Count: 2}) {
+		t.Errorf("facets = %+v", r.Facets)
+	}
+	if len(r.Facets.Dates) != 1 || r.Facets.Dates[0] != (search.DateBucket{Date: "2025-03-01", Count: 2}) {
+		t.Errorf("date facet = %+v", r.Facets.Dates)
+	}
+
+	if r := run("crude", search.Filter{}); len(r.Hits) != 1 || r.Hits[0].Snippet != "Brent <mark>crude</mark> &lt;rose&gt; after the OPEC meeting in Vienna" {
+		t.Errorf("snippet = %+v", r.Hits)
+	}
+	for _, tc := range []struct {
+		query  string
+		filter search.Filter
+		want   []string
+	}{
+		{"oil -gas", search.Filter{}, []string{"a"}},
+		{`"opec meeting"`, search.Filter{}, []string{"a"}},
+		{"нефт*", search.Filter{}, []string{"c"}},
+		{"crude OR exports", search.Filter{}, []string{"b", "a"}},
+		{"oil", search.Filter{From: published.Add(time.Minute)}, []string{"b"}},
+		{"oil", search.Filter{Language: "ru"}, nil},
+	} {
+		if got := links(run(tc.query, tc.filter)); !slices.Equal(got, tc.want) {
+			t.Errorf("%q %+v: hits = %v, want %v", tc.query, tc.filter, got, tc.want)
+		}
+	}
+
+	// Текст новости обновляется в индексе вместе с новостью.
+	feed.Items[0].Title = "Coal prices climb"
+	if _, err := db.SaveNews(ctx, &domain.Feed{Source: "bbc", Items: feed.Items[:1]}); err != nil {
+		t.Fatal(err)
+	}
+	if r := run("coal", search.Filter{}); !slices.Equal(links(r), []string{"a"}) {
+		t.Errorf("coal after update: hits = %v", links(r))
+	}
+}
+
+func TestSQLiteMigrateTwice(t *testing.T) {
+	db := openSQLite(t)
+	if err := db.Migrate(context.Background()); err != nil {
+		t.Errorf("second Migrate: %v", err)
+	}
+}
+
+func TestSQLiteSourcesAndIdempotency(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+
+	if err := db.SaveSource(ctx, domain.Source{URL: "https://example.com/rss", Name: "Example", Active: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SaveSource(ctx, domain.Source{URL: "https://example.com/rss", Active: false}); err != nil {
+		t.Fatal(err)
+	}
+	sources, err := db.ListSources(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sources) != 1 || sources[0].Name != "Example" || sources[0].Active || sources[0].UpdatedAt.IsZero() {
+		t.Errorf("sources = %+v", sources)
+	}
+
+	if done, err := db.IsMessageProcessed(ctx, "key"); err != nil || done {
+		t.Fatalf("IsMessageProcessed before mark = %v, %v", done, err)
+	}
+	if err := db.MarkMessageProcessed(ctx, "key"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.MarkMessageProcessed(ctx, "key"); err != nil {
+		t.Fatalf("repeated MarkMessageProcessed: %v", err)
+	}
+	if done, err := db.IsMessageProcessed(ctx, "key"); err != nil || !done {
+		t.Fatalf("IsMessageProcessed after mark = %v, %v", done, err)
+	}
+	if n, err := db.DeleteProcessedMessages(ctx, time.Now().Add(time.Minute)); err != nil || n != 1 {
+		t.Errorf("DeleteProcessedMessages = %d, %v", n, err)
+	}
+}
+
+func TestSQLiteSaveNewsMarksMessage(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	item := domain.Item{Title: "Oil", Link: "https://example.com/1", PubDate: time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)}
+
+	// Ключ отмечается, даже если статьи не изменились с прошлого сохранения.
+	for _, key := range []string{"wire-1", "wire-2"} {
+		feed := &domain.Feed{Source: "wire", IdempotencyKey: key, Items: []domain.Item{item}}
+		if _, err := db.SaveNews(ctx, feed); err != nil {
+			t.Fatal(err)
+		}
+		if done, err := db.IsMessageProcessed(ctx, key); err != nil || !done {
+			t.Fatalf("IsMessageProcessed(%s) after SaveNews = %v, %v", key, done, err)
+		}
+	}
+	if _, err := db.SaveNews(ctx, &domain.Feed{Source: "wire", Items: []domain.Item{item}}); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := db.DeleteProcessedMessages(ctx, time.Now().Add(time.Minute)); err != nil || n != 2 {
+		t.Errorf("DeleteProcessedMessages = %d, %v, want only the two message keys", n, err)
+	}
+}
+
+func TestSQLiteCapabilities(t *testing.T) {
+	var cfg config.Config
+	cfg.DB.Driver = "sqlite"
+	cfg.DB.Path = filepath.Join(t.TempDir(), "news.db")
+	db, err := storage.Open(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	capabilities := []struct {
+		feature   string
+		supported bool
+		check     func(storage.NewsStorage) bool
+	}{
+		{"migrations", true, func(db storage.NewsStorage) bool { _, ok := db.(storage.Migrator); return ok }},
+		{"source registry", true, func(db storage.NewsStorage) bool { _, ok := db.(storage.SourceStorage); return ok }},
+		{"idempotency keys", true, func(db storage.NewsStorage) bool { _, ok := db.(storage.IdempotencyStorage); return ok }},
+		{"tagging backfill", true, func(db storage.NewsStorage) bool { _, ok := db.(storage.TaggingStorage); return ok }},
+		{"search index source", true, func(db storage.NewsStorage) bool { _, ok := db.(storage.SearchIndexStorage); return ok }},
+		{"full-text search", true, func(db storage.NewsStorage) bool { _, ok := db.(search.Searcher); return ok }},
+		{"admin stats", true, func(db storage.NewsStorage) bool { _, ok := db.(storage.AdminStorage); return ok }},
+		{"outbox", false, func(db storage.NewsStorage) bool { _, ok := db.(storage.OutboxStorage); return ok }},
+		{"revisions", false, func(db storage.NewsStorage) bool { _, ok := db.(storage.RevisionStorage); return ok }},
+		{"taxonomy", false, func(db storage.NewsStorage) bool { _, ok := db.(storage.TaxonomyStorage); return ok }},
+	}
+	for _, c := range capabilities {
+		if got := c.check(db); got != c.supported {
+			t.Errorf("%s supported = %v, want %v", c.feature, got, c.supported)
+		}
+	}
+
+	if err := db.(storage.Migrator).Migrate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for _, collapse := range []string{models.CollapseEarliest, models.CollapsePrimary} {
+		_, err := db.GetNewsByFilter(context.Background(), models.NewsFilter{Collapse: collapse})
+		if !errors.Is(err, storage.ErrUnsupported) {
+			t.Errorf("GetNewsByFilter(collapse %s) error = %v, want ErrUnsupported", collapse, err)
+		}
+	}
+	if err := storage.Unsupported(cfg.DB.Driver, "revisions"); !errors.Is(err, storage.ErrUnsupported) ||
+		!strings.Contains(err.Error(), `database driver "sqlite" does not support revisions`) {
+		t.Errorf("Unsupported() = %v", err)
+	}
+}
+
+func TestSQLiteTagging(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	published := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	feed := &domain.Feed{Source: "bbc", Items: []domain.Item{
+		{Title: "Oil", Link: "a", PubDate: published, Language: "en", Category: "economy", Tags: []string{"oil", "energy"},
+			DescriptionText: "Brent", ContentText: "Crude rose", Categories: []string{"Business"}},
+		{Title: "Gas", Link: "b", PubDate: published, Language: "", Tags: []string{"gas"}},
+		{Title: "Нефть", Link: "c", PubDate: published, Language: "ru"},
+	}}
+	if _, err := db.SaveNews(ctx, feed); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := db.GetTaggingRules(ctx); err == nil {
+		t.Fatal("GetTaggingRules() without stored rules succeeded")
+	}
+	for _, doc := range []string{`{"rules":[]}`, `{"rules":[{"name":"oil"}]}`} {
+		if err := db.SaveTaggingRules(ctx, []byte(doc)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if rules, err := db.GetTaggingRules(ctx); err != nil || string(rules) != `{"rules":[{"name":"oil"}]}` {
+		t.Fatalf("GetTaggingRules() = %s, %v, want the latest version", rules, err)
+	}
+
+	first, err := db.ListNewsForRetag(ctx, 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first) != 2 || first[0].Link != "a" || first[1].Link != "b" {
+		t.Fatalf("first batch = %+v", first)
+	}
+	if n := first[0]; n.Category != "economy" || !slices.Equal(n.Tag, []string{"oil", "energy"}) ||
+		!slices.Equal(n.FeedCategories, []string{"Business"}) || n.DescriptionText != "Brent" || n.ContentText != "Crude rose" {
+		t.Fatalf("news for retag = %+v", n)
+	}
+	rest, err := db.ListNewsForRetag(ctx, first[1].NewsID, 2)
+	if err != nil || len(rest) != 1 || rest[0].Link != "c" {
+		t.Fatalf("second batch = %+v, %v", rest, err)
+	}
+
+	if err := db.UpdateNewsTags(ctx, first[0].NewsID, "energy", []string{"brent", "oil"}); err != nil {
+		t.Fatal(err)
+	}
+	news, err := db.GetDetailedNews(ctx, first[0].NewsID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if news.Category != "energy" || !slices.Equal(news.Tag, []string{"brent", "oil"}) {
+		t.Fatalf("retagged news = %q %v", news.Category, news.Tag)
+	}
+
+	stats, err := db.GetLanguageStats(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []models.SourceLanguageStats{{Source: "bbc", Total: 3, Languages: map[string]int{"en": 1, "ru": 1, "und": 1}}}
+	if len(stats) != 1 || stats[0].Source != want[0].Source || stats[0].Total != want[0].Total || !maps.Equal(stats[0].Languages, want[0].Languages) {
+		t.Fatalf("language stats = %+v, want %+v", stats, want)
+	}
+}
+
+func TestSQLiteIndexDocuments(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	published := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	feed := &domain.Feed{Source: "bbc", Items: []domain.Item{
+		{Title: "Oil", Link: "a", PubDate: published, Language: "en", Category: "economy", Author: "Jane",
+			DescriptionText: "Brent", ContentText: "Crude rose"},
+		{Title: "Gas", Link: "b", PubDate: published.Add(time.Hour)},
+		{Title: "Coal", Link: "c", PubDate: published.Add(2 * time.Hour)},
+	}}
+	if _, err := db.SaveNews(ctx, feed); err != nil {
+		t.Fatal(err)
+	}
+
+	docs, err := db.ListNewsForIndex(ctx, 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 2 {
+		t.Fatalf("docs = %+v, want 2", docs)
+	}
+	d := docs[0]
+	if d.ID != feed.Items[0].ID || d.Source != "bbc" || d.Title != "Oil" || d.Text != "Brent\n\nCrude rose" || d.Link != "a" ||
+		d.Author != "Jane" || d.Language != "en" || d.Category != "economy" || !d.PublishedAt.Equal(published) {
+		t.Fatalf("document = %+v", d)
+	}
+	if rest, err := db.ListNewsForIndex(ctx, docs[1].ID, 2); err != nil || len(rest) != 1 || rest[0].Title != "Coal" {
+		t.Fatalf("second batch = %+v, %v", rest, err)
+	}
+
+	byID, err := db.GetNewsForIndex(ctx, feed.Items[2].ID, 999, feed.Items[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(byID) != 2 || byID[0].Title != "Oil" || byID[1].Title != "Coal" {
+		t.Fatalf("GetNewsForIndex() = %+v", byID)
+	}
+	if none, err := db.GetNewsForIndex(ctx); err != nil || len(none) != 0 {
+		t.Fatalf("GetNewsForIndex() without ids = %+v, %v", none, err)
+	}
+}
@@ -0,0 +1,497 @@
+// Package storagetest — общий набор проверок поведения реализаций
+// storage.NewsStorage. Каждая реализация запускает его в своих тестах, чтобы
+// различия в сохранении, фильтрации, подсчете и сортировке новостей
+// обнаруживались автоматически.
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"newsservice/internal/domain"
+	"newsservice/internal/models"
+	"newsservice/storage"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Open возвращает пустое хранилище для одного теста. Освобождение ресурсов
+// регистрируется через t.Cleanup.
+type Open func(t *testing.T) storage.NewsStorage
+
+// base — начало отсчета дат публикации тестовых новостей. Даты целые в
+// секундах, чтобы совпадать после сохранения в любой БД.
+var base = time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// Run запускает все проверки набора для хранилищ, создаваемых open.
+func Run(t *testing.T, open Open) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, db storage.NewsStorage)
+	}{
+		{"SaveAndGet", testSaveAndGet},
+		{"SaveUnchanged", testSaveUnchanged},
+		{"SaveUpdated", testSaveUpdated},
+		{"GetNotFound", testGetNotFound},
+		{"Filter", testFilter},
+		{"Sort", testSort},
+		{"LimitOffset", testLimitOffset},
+		{"Keyset", testKeyset},
+		{"Collapse", testCollapse},
+		{"InvalidFilter", testInvalidFilter},
+		{"ConcurrentSave", testConcurrentSave},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.run(t, open(t))
+		})
+	}
+}
+
+func testSaveAndGet(t *testing.T, db storage.NewsStorage) {
+	ctx := context.Background()
+	feed := &domain.Feed{Source: "bbc", Items: []domain.Item{{
+		Title:           "Oil prices rise",
+		Link:            "https://example.com/oil",
+		OriginalLink:    "https://example.com/oil?utm_source=rss",
+		Author:          "Jane Doe",
+		Description:     "<p>Oil prices rose</p>",
+		DescriptionText: "Oil prices rose",
+		Content:         "<p>Brent crude rose on Monday</p>",
+		ContentText:     "Brent crude rose on Monday",
+		PubDate:         base,
+		Language:        "en",
+		Categories:      []string{"Business", "Energy"},
+		Category:        "economy",
+		Tags:            []string{"oil", "opec", "oil", ""},
+		Keywords:        []string{"brent", "crude"},
+		Entities: []domain.Entity{
+			{Kind: "org", Name: "OPEC", Mentions: 1},
+			{Kind: "place", Name: "London", Mentions: 3},
+		},
+		Image: &domain.Image{
+			SourceURL:  "https://example.com/oil.jpg",
+			Origin:     domain.ImageOriginEnclosure,
+			Key:        "ab/oil.jpg",
+			Width:      800,
+			Height:     600,
+			Thumbnails: []domain.Thumbnail{{Key: "ab/oil-320.jpg", Width: 320, Height: 240}},
+		},
+	}}}
+
+	saved, err := db.SaveNews(ctx, feed)
+	if err != nil {
+		t.Fatalf("SaveNews: %v", err)
+	}
+	item := feed.Items[0]
+	if saved != 1 || item.Status != domain.StatusCreated || item.ID <= 0 {
+		t.Fatalf("saved = %d, status = %q, id = %d; want 1 created news", saved, item.Status, item.ID)
+	}
+
+	got, err := db.GetDetailedNews(ctx, int(item.ID))
+	if err != nil {
+		t.Fatalf("GetDetailedNews: %v", err)
+	}
+	if got.NewsID != int(item.ID) || got.Title != item.Title || got.Source != "bbc" || got.Link != item.Link ||
+		got.OriginalLink != item.OriginalLink || got.Author != item.Author {
+		t.Errorf("news = %+v", got)
+	}
+	if got.Description != item.Description || got.DescriptionText != item.DescriptionText ||
+		got.Content != item.Content || got.ContentText != item.ContentText {
+		t.Errorf("texts = %q / %q / %q / %q", got.Description, got.DescriptionText, got.Content, got.ContentText)
+	}
+	if !got.PublishedAt.Equal(base) {
+		t.Errorf("published_at = %v, want %v", got.PublishedAt, base)
+	}
+	if got.Language != "en" || got.Category != "economy" || !slices.Equal(got.FeedCategories, item.Categories) {
+		t.Errorf("language/category/feed categories = %q/%q/%v", got.Language, got.Category, got.FeedCategories)
+	}
+	if !slices.Equal(got.Tag, []string{"oil", "opec"}) {
+		t.Errorf("tags = %v, want [oil opec]", got.Tag)
+	}
+	if !slices.Equal(got.Keywords, []string{"brent", "crude"}) {
+		t.Errorf("keywords = %v", got.Keywords)
+	}
+	if len(got.Entities) != 2 || got.Entities[0].Name != "London" || got.Entities[0].Mentions != 3 || got.Entities[1].Name != "OPEC" {
+		t.Errorf("entities = %+v, want London then OPEC", got.Entities)
+	}
+	img := got.Image
+	if img == nil || img.URL != models.ImageURL("ab/oil.jpg") || img.SourceURL != "https://example.com/oil.jpg" ||
+		img.Origin != domain.ImageOriginEnclosure || img.Width != 800 || img.Height != 600 {
+		t.Fatalf("image = %+v", img)
+	}
+	if len(img.Thumbnails) != 1 || img.Thumbnails[0].URL != models.ImageURL("ab/oil-320.jpg") || img.Thumbnails[0].Width != 320 {
+		t.Errorf("thumbnails = %+v", img.Thumbnails)
+	}
+
+	list, err := db.GetNewsByFilter(ctx, models.NewsFilter{})
+	if err != nil {
+		t.Fatalf("GetNewsByFilter: %v", err)
+	}
+	if len(list) != 1 || list[0].NewsID != got.NewsID || !slices.Equal(list[0].Tag, got.Tag) || list[0].Image == nil {
+		t.Errorf("list = %+v", list)
+	}
+
+	if saved, err := db.SaveNews(ctx, &domain.Feed{Source: "bbc"}); err != nil || saved != 0 {
+		t.Errorf("empty feed: saved = %d, err = %v", saved, err)
+	}
+}
+
+func testSaveUnchanged(t *testing.T, db storage.NewsStorage) {
+	ctx := context.Background()
+	item := domain.Item{Title: "Title", Link: "https://example.com/a", Description: "d", PubDate: base}
+	if _, err := db.SaveNews(ctx, &domain.Feed{Source: "bbc", Items: []domain.Item{item}}); err != nil {
+		t.Fatalf("SaveNews: %v", err)
+	}
+
+	again := &domain.Feed{Source: "bbc", Items: []domain.Item{item}}
+	saved, err := db.SaveNews(ctx, again)
+	if err != nil {
+		t.Fatalf("SaveNews: %v", err)
+	}
+	if saved != 0 || again.Items[0].Status != domain.StatusUnchanged {
+		t.Errorf("saved = %d, status = %q; want unchanged news", saved, again.Items[0].Status)
+	}
+	if count := mustCount(t, db, models.NewsFilter{}); count != 1 {
+		t.Errorf("count = %d, want 1", count)
+	}
+}
+
+func testSaveUpdated(t *testing.T, db storage.NewsStorage) {
+	ctx := context.Background()
+	item := domain.Item{
+		Title:   "Draft title",
+		Link:    "https://example.com/a",
+		PubDate: base,
+		Tags:    []string{"draft"},
+		Image:   &domain.Image{Key: "ab/a.jpg", SourceURL: "https://example.com/a.jpg", Width: 100, Height: 50},
+	}
+	first := &domain.Feed{Source: "bbc", Items: []domain.Item{item}}
+	if _, err := db.SaveNews(ctx, first); err != nil {
+		t.Fatalf("SaveNews: %v", err)
+	}
+
+	item.Title = "Final title"
+	item.Tags = []string{"final"}
+	item.Image = nil
+	second := &domain.Feed{Source: "bbc", Items: []domain.Item{item}}
+	saved, err := db.SaveNews(ctx, second)
+	if err != nil {
+		t.Fatalf("SaveNews: %v", err)
+	}
+	if saved != 0 || second.Items[0].Status != domain.StatusUpdated || second.Items[0].ID != first.Items[0].ID {
+		t.Fatalf("saved = %d, status = %q, id = %d; want update of news %d",
+			saved, second.Items[0].Status, second.Items[0].ID, first.Items[0].ID)
+	}
+
+	got, err := db.GetDetailedNews(ctx, int(first.Items[0].ID))
+	if err != nil {
+		t.Fatalf("GetDetailedNews: %v", err)
+	}
+	if got.Title != "Final title" || !slices.Equal(got.Tag, []string{"final"}) {
+		t.Errorf("title = %q, tags = %v", got.Title, got.Tag)
+	}
+	if got.Image == nil || got.Image.URL != models.ImageURL("ab/a.jpg") {
+		t.Errorf("image = %+v, want the previous image kept", got.Image)
+	}
+}
+
+func testGetNotFound(t *testing.T, db storage.NewsStorage) {
+	_, err := db.GetDetailedNews(context.Background(), 424242)
+	if !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("err = %v, want storage.ErrNotFound", err)
+	}
+}
+
+// fixture сохраняет тестовые новости и возвращает их ID по ссылкам.
+func fixture(t *testing.T, db storage.NewsStorage) map[string]int {
+	t.Helper()
+	img := &domain.Image{Key: "img/1.jpg", SourceURL: "https://example.com/1.jpg", Width: 10, Height: 10}
+	feeds := []*domain.Feed{
+		{Source: "bbc", Items: []domain.Item{
+			{Title: "Alpha oil deal", Link: "bbc/1", PubDate: base, Language: "en", Category: "economy",
+				Tags: []string{"oil", "opec"}, Author: "Ann", Image: img, ContentText: "Brent crude and OPEC",
+				Entities: []domain.Entity{{Kind: "org", Name: "OPEC", Mentions: 2}}},
+			{Title: "Bravo football final", Link: "bbc/2", PubDate: base.Add(time.Hour), Language: "en", Category: "sport",
+				Tags: []string{"football"}, Author: "Bob"},
+		}},
+		{Source: "reuters", Items: []domain.Item{
+			{Title: "Charlie gas prices", Link: "reuters/1", PubDate: base.Add(2 * time.Hour), Language: "en", Category: "economy",
+				Tags: []string{"gas"}, Author: "Ann", DescriptionText: "Oil and gas markets"},
+			{Title: "Delta election", Link: "reuters/2", PubDate: base.Add(24 * time.Hour), Language: "en", Category: "politics",
+				Tags: []string{"election", "oil"}},
+		}},
+		{Source: "tass", Items: []domain.Item{
+			{Title: "Echo oil exports", Link: "tass/1", PubDate: base.Add(24 * time.Hour), Language: "ru", Category: "economy",
+				Tags: []string{"oil"}, Image: &domain.Image{Key: "img/2.jpg", SourceURL: "https://example.com/2.jpg"}},
+			{Title: "Foxtrot hockey", Link: "tass/2", PubDate: base.Add(48 * time.Hour), Language: "ru", Category: "sport"},
+		}},
+	}
+	ids := make(map[string]int)
+	for _, feed := range feeds {
+		if _, err := db.SaveNews(context.Background(), feed); err != nil {
+			t.Fatalf("SaveNews: %v", err)
+		}
+		for _, item := range feed.Items {
+			ids[item.Link] = int(item.ID)
+		}
+	}
+	return ids
+}
+
+func testFilter(t *testing.T, db storage.NewsStorage) {
+	fixture(t, db)
+	yes, no := true, false
+	tests := []struct {
+		name   string
+		filter models.NewsFilter
+		want   []string
+	}{
+		{"all", models.NewsFilter{},
+			[]string{"tass/2", "tass/1", "reuters/2", "reuters/1", "bbc/2", "bbc/1"}},
+		{"sources", models.NewsFilter{Sources: []string{"bbc", "tass"}},
+			[]string{"tass/2", "tass/1", "bbc/2", "bbc/1"}},
+		{"exclude sources", models.NewsFilter{ExcludeSources: []string{"bbc", "tass"}},
+			[]string{"reuters/2", "reuters/1"}},
+		{"categories", models.NewsFilter{Categories: []string{"sport", "politics"}},
+			[]string{"tass/2", "reuters/2", "bbc/2"}},
+		{"exclude categories", models.NewsFilter{ExcludeCategories: []string{"economy"}},
+			[]string{"tass/2", "reuters/2", "bbc/2"}},
+		{"any tag", models.NewsFilter{Tags: []string{"gas", "football"}},
+			[]string{"reuters/1", "bbc/2"}},
+		{"all tags", models.NewsFilter{Tags: []string{"oil", "opec"}, TagsMatch: models.TagsMatchAll},
+			[]string{"bbc/1"}},
+		{"exclude tags", models.NewsFilter{Tags: []string{"oil"}, ExcludeTags: []string{"election"}},
+			[]string{"tass/1", "bbc/1"}},
+		{"languages", models.NewsFilter{Languages: []string{"ru"}},
+			[]string{"tass/2", "tass/1"}},
+		{"author", models.NewsFilter{Author: "Ann"},
+			[]string{"reuters/1", "bbc/1"}},
+		{"period", models.NewsFilter{From: base.Add(time.Hour), To: base.Add(24 * time.Hour)},
+			[]string{"reuters/1", "bbc/2"}},
+		{"with image", models.NewsFilter{HasImage: &yes},
+			[]string{"tass/1", "bbc/1"}},
+		{"without image", models.NewsFilter{HasImage: &no, Sources: []string{"bbc"}},
+			[]string{"bbc/2"}},
+		{"entity", models.NewsFilter{Entity: "opec"},
+			[]string{"bbc/1"}},
+		{"query", models.NewsFilter{Query: "OIL"},
+			[]string{"tass/1", "reuters/1", "bbc/1"}},
+		{"query with wildcard", models.NewsFilter{Query: "o%l"},
+			nil},
+		{"combined", models.NewsFilter{Tags: []string{"oil"}, Categories: []string{"economy"}, Languages: []string{"en"}},
+			[]string{"bbc/1"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := mustLinks(t, db, tc.filter); !slices.Equal(got, tc.want) {
+				t.Errorf("news = %v, want %v", got, tc.want)
+			}
+			if count := mustCount(t, db, tc.filter); count != len(tc.want) {
+				t.Errorf("count = %d, want %d", count, len(tc.want))
+			}
+		})
+	}
+}
+
+// testCollapse проверяет свертку сюжетов: новости с близкими отпечатками
+// текста сворачиваются в одну. Хранилище без сюжетов должно отклонять свертку
+// ошибкой storage.ErrUnsupported, а не возвращать несвернутый результат.
+func testCollapse(t *testing.T, db storage.NewsStorage) {
+	const fingerprint uint64 = 0x0123456789abcdef
+	story := "Central bank keeps the key rate unchanged"
+	feeds := []*domain.Feed{
+		{Source: "bbc", Items: []domain.Item{
+			{Title: "Rate unchanged", Link: "bbc/rate", PubDate: base, ContentText: story, Fingerprint: fingerprint},
+			{Title: "Football final", Link: "bbc/football", PubDate: base.Add(2 * time.Hour), ContentText: "Final ends in a draw",
+				Fingerprint: ^fingerprint},
+		}},
+		{Source: "reuters", Items: []domain.Item{
+			{Title: "Key rate kept", Link: "reuters/rate", PubDate: base.Add(time.Hour), ContentText: story + " as inflation slows",
+				Fingerprint: fingerprint ^ 1},
+		}},
+	}
+	for _, feed := range feeds {
+		if _, err := db.SaveNews(context.Background(), feed); err != nil {
+			t.Fatalf("SaveNews: %v", err)
+		}
+	}
+
+	if got, want := mustLinks(t, db, models.NewsFilter{}), []string{"bbc/football", "reuters/rate", "bbc/rate"}; !slices.Equal(got, want) {
+		t.Fatalf("news = %v, want %v", got, want)
+	}
+	_, err := db.GetNewsByFilter(context.Background(), models.NewsFilter{Collapse: models.CollapseEarliest})
+	if errors.Is(err, storage.ErrUnsupported) {
+		if _, err := db.GetNewsCount(context.Background(), models.NewsFilter{Collapse: models.CollapseEarliest}); !errors.Is(err, storage.ErrUnsupported) {
+			t.Fatalf("GetNewsCount err = %v, want storage.ErrUnsupported", err)
+		}
+		t.Skip("storage does not build story clusters")
+	}
+	tests := []struct {
+		collapse string
+		want     []string
+	}{
+		{models.CollapseEarliest, []string{"bbc/football", "bbc/rate"}},
+		{models.CollapsePrimary, []string{"bbc/football", "reuters/rate"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.collapse, func(t *testing.T) {
+			filter := models.NewsFilter{Collapse: tc.collapse}
+			if got := mustLinks(t, db, filter); !slices.Equal(got, tc.want) {
+				t.Errorf("news = %v, want %v", got, tc.want)
+			}
+			if count := mustCount(t, db, filter); count != len(tc.want) {
+				t.Errorf("count = %d, want %d", count, len(tc.want))
+			}
+		})
+	}
+}
+
+func testSort(t *testing.T, db storage.NewsStorage) {
+	fixture(t, db)
+	tests := []struct {
+		name string
+		sort []models.SortField
+		want []string
+	}{
+		{"published asc", []models.SortField{{Field: models.SortPublishedAt, Order: models.SortAsc}},
+			// Новости с одной датой упорядочиваются по убыванию ID.
+			[]string{"bbc/1", "bbc/2", "reuters/1", "tass/1", "reuters/2", "tass/2"}},
+		{"title desc", []models.SortField{{Field: models.SortTitle, Order: models.SortDesc}},
+			[]string{"tass/2", "tass/1", "reuters/2", "reuters/1", "bbc/2", "bbc/1"}},
+		{"source then published", []models.SortField{
+			{Field: models.SortSource, Order: models.SortDesc},
+			{Field: models.SortPublishedAt, Order: models.SortAsc},
+		}, []string{"tass/1", "tass/2", "reuters/1", "reuters/2", "bbc/1", "bbc/2"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := mustLinks(t, db, models.NewsFilter{Sort: tc.sort}); !slices.Equal(got, tc.want) {
+				t.Errorf("news = %v, want %v", got, tc.want)
+			}
+		})
+	}
+
+	t.Run("relevance", func(t *testing.T) {
+		filter := models.NewsFilter{Query: "oil", Sort: []models.SortField{{Field: models.SortRelevance, Order: models.SortDesc}}}
+		got := mustLinks(t, db, filter)
+		slices.Sort(got)
+		if want := []string{"bbc/1", "reuters/1", "tass/1"}; !slices.Equal(got, want) {
+			t.Errorf("news = %v, want %v in any order", got, want)
+		}
+	})
+}
+
+func testLimitOffset(t *testing.T, db storage.NewsStorage) {
+	fixture(t, db)
+	got := mustLinks(t, db, models.NewsFilter{Limit: 2, Offset: 1})
+	if want := []string{"tass/1", "reuters/2"}; !slices.Equal(got, want) {
+		t.Errorf("news = %v, want %v", got, want)
+	}
+	if got := mustLinks(t, db, models.NewsFilter{Offset: 10}); len(got) != 0 {
+		t.Errorf("news past the end = %v, want none", got)
+	}
+}
+
+func testKeyset(t *testing.T, db storage.NewsStorage) {
+	ids := fixture(t, db)
+	desc := []string{"tass/2", "tass/1", "reuters/2", "reuters/1", "bbc/2", "bbc/1"}
+
+	// Первая страница и переходы вперед и назад от позиции tass/1.
+	first := mustLinks(t, db, models.NewsFilter{Limit: 2, Keyset: &models.Keyset{Order: models.SortDesc}})
+	if !slices.Equal(first, desc[:2]) {
+		t.Errorf("first page = %v, want %v", first, desc[:2])
+	}
+	after := models.Keyset{PublishedAt: base.Add(24 * time.Hour), ID: ids["tass/1"], Order: models.SortDesc}
+	next := mustLinks(t, db, models.NewsFilter{Limit: 2, Keyset: &after})
+	if !slices.Equal(next, desc[2:4]) {
+		t.Errorf("next page = %v, want %v", next, desc[2:4])
+	}
+	before := models.Keyset{PublishedAt: base.Add(2 * time.Hour), ID: ids["reuters/1"], Order: models.SortDesc, Backward: true}
+	prev := mustLinks(t, db, models.NewsFilter{Limit: 2, Keyset: &before})
+	if !slices.Equal(prev, desc[1:3]) {
+		t.Errorf("previous page = %v, want %v", prev, desc[1:3])
+	}
+
+	asc := models.Keyset{PublishedAt: base.Add(time.Hour), ID: ids["bbc/2"], Order: models.SortAsc}
+	if got := mustLinks(t, db, models.NewsFilter{Limit: 2, Keyset: &asc}); !slices.Equal(got, []string{"reuters/1", "reuters/2"}) {
+		t.Errorf("ascending page = %v", got)
+	}
+}
+
+func testInvalidFilter(t *testing.T, db storage.NewsStorage) {
+	for _, filter := range []models.NewsFilter{
+		{TagsMatch: "some", Tags: []string{"oil"}},
+		{Collapse: "latest"},
+		{Sort: []models.SortField{{Field: "link", Order: models.SortAsc}}},
+		{Sort: []models.SortField{{Field: models.SortTitle, Order: "up"}}},
+		{Sort: []models.SortField{{Field: models.SortRelevance, Order: models.SortDesc}}},
+		{Keyset: &models.Keyset{Order: "up"}},
+	} {
+		if _, err := db.GetNewsByFilter(context.Background(), filter); err == nil {
+			t.Errorf("GetNewsByFilter(%+v) succeeded, want error", filter)
+		}
+	}
+	if _, err := db.GetNewsCount(context.Background(), models.NewsFilter{Collapse: "latest"}); err == nil {
+		t.Error("GetNewsCount with unknown collapse mode succeeded, want error")
+	}
+}
+
+func testConcurrentSave(t *testing.T, db storage.NewsStorage) {
+	const workers, perWorker = 4, 10
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for w := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range perWorker {
+				feed := &domain.Feed{Source: fmt.Sprintf("source-%d", w), Items: []domain.Item{{
+					Title:   fmt.Sprintf("News %d-%d", w, i),
+					Link:    fmt.Sprintf("https://example.com/%d/%d", w, i),
+					PubDate: base.Add(time.Duration(i) * time.Minute),
+				}}}
+				if _, err := db.SaveNews(context.Background(), feed); err != nil {
+					errs <- err
+					return
+				}
+				if _, err := db.GetNewsByFilter(context.Background(), models.NewsFilter{Limit: 5}); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+	if count := mustCount(t, db, models.NewsFilter{}); count != workers*perWorker {
+		t.Errorf("count = %d, want %d", count, workers*perWorker)
+	}
+}
+
+func mustLinks(t *testing.T, db storage.NewsStorage, filter models.NewsFilter) []string {
+	t.Helper()
+	news, err := db.GetNewsByFilter(context.Background(), filter)
+	if err != nil {
+		t.Fatalf("GetNewsByFilter: %v", err)
+	}
+	var links []string
+	for _, n := range news {
+		links = append(links, n.Link)
+	}
+	return links
+}
+
+func mustCount(t *testing.T, db storage.NewsStorage, filter models.NewsFilter) int {
+	t.Helper()
+	count, err := db.GetNewsCount(context.Background(), filter)
+	if err != nil {
+		t.Fatalf("GetNewsCount: %v", err)
+	}
+	return count
+}
@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"newsservice/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var _ TaggingStorage = (*Storage)(nil)
+
+// Метод для получения актуального документа с правилами категоризации
+func (s *Storage) GetTaggingRules(ctx context.Context) ([]byte, error) {
+	var document []byte
+	err := s.db.QueryRow(ctx,
+		`SELECT document FROM tagging_rules ORDER BY version DESC LIMIT 1;`,
+	).Scan(&document)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("no tagging rules stored in database")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tagging rules: %w", err)
+	}
+	return document, nil
+}
+
+// Метод для постраничной выборки новостей, упорядоченных по id, для повторного тегирования
+func (s *Storage) ListNewsForRetag(ctx context.Context, afterID int, limit int) ([]models.NewsFullDetailed, error) {
+	query := `
+	SELECT id, title, description_text, content_text, link, source, language, category, ` + newsTagsSelect + `, feed_categories
+	FROM news
+	WHERE id > $1
+	ORDER BY id
+	LIMIT $2;`
+
+	rows, err := s.db.Query(ctx, query, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query news for retag: %w", err)
+	}
+	defer rows.Close()
+
+	var news []models.NewsFullDetailed
+	for rows.Next() {
+		var item models.NewsFullDetailed
+		err := rows.Scan(
+			&item.NewsID,
+			&item.Title,
+			&item.DescriptionText,
+			&item.ContentText,
+			&item.Link,
+			&item.Source,
+			&item.Language,
+			&item.Category,
+			&item.Tag,
+			&item.FeedCategories,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan news row: %w", err)
+		}
+		news = append(news, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return news, nil
+}
+
+// Метод для обновления категории и тегов новости
+func (s *Storage) UpdateNewsTags(ctx context.Context, newsID int, category string, tags []string) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `UPDATE news SET category = $2 WHERE id = $1;`, newsID, category); err != nil {
+		return fmt.Errorf("failed to update category of news %d: %w", newsID, err)
+	}
+	if err := saveNewsTags(ctx, tx, int64(newsID), category, tags); err != nil {
+		return fmt.Errorf("failed to update tags of news %d: %w", newsID, err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,291 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"newsservice/internal/models"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var _ TaxonomyStorage = (*Storage)(nil)
+
+// newsTagsSelect — slug тегов новости в порядке, назначенном при тегировании.
+const newsTagsSelect = `ARRAY(
+	SELECT t.slug FROM news_tags nt JOIN tags t ON t.id = nt.tag_id
+	WHERE nt.news_id = news.id ORDER BY nt.position, t.slug) AS tags`
+
+// saveNewsTags заменяет теги новости. Неизвестные теги создаются, прежние
+// slug переименованных и объединенных тегов заменяются актуальными.
+// Категория новости добавляется в справочник категорий.
+func saveNewsTags(ctx context.Context, tx pgx.Tx, newsID int64, category string, tags []string) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM news_tags WHERE news_id = $1;`, newsID); err != nil {
+		return fmt.Errorf("failed to clear news tags: %w", err)
+	}
+	if len(tags) > 0 {
+		_, err := tx.Exec(ctx, `
+		INSERT INTO tags (slug, name)
+		SELECT DISTINCT t.slug, t.slug FROM unnest($1::TEXT[]) AS t (slug)
+		WHERE t.slug <> '' AND NOT EXISTS (SELECT 1 FROM tag_slugs s WHERE s.slug = t.slug)
+		ON CONFLICT (slug) DO NOTHING;`, tags,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to save tags: %w", err)
+		}
+		_, err = tx.Exec(ctx, `
+		INSERT INTO news_tags (news_id, tag_id, position)
+		SELECT $1, s.tag_id, MIN(t.pos)
+		FROM unnest($2::TEXT[]) WITH ORDINALITY AS t (slug, pos)
+		JOIN tag_slugs s ON s.slug = t.slug
+		GROUP BY s.tag_id
+		ON CONFLICT DO NOTHING;`, newsID, tags,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to link tags to news: %w", err)
+		}
+	}
+	if category != "" {
+		_, err := tx.Exec(ctx, `INSERT INTO categories (slug) VALUES ($1) ON CONFLICT (slug) DO NOTHING;`, category)
+		if err != nil {
+			return fmt.Errorf("failed to save category: %w", err)
+		}
+	}
+	return nil
+}
+
+// Метод для получения тегов с числом новостей, начиная с самых частых
+func (s *Storage) ListTags(ctx context.Context, prefix string, limit int) ([]models.Tag, error) {
+	pattern := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(prefix) + "%"
+	rows, err := s.db.Query(ctx, `
+	SELECT t.slug, t.name, COUNT(nt.news_id)
+	FROM tags t
+	LEFT JOIN news_tags nt ON nt.tag_id = t.id
+	WHERE t.slug LIKE $1
+	GROUP BY t.id
+	ORDER BY 3 DESC, t.slug
+	LIMIT $2;`, pattern, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tags: %w", err)
+	}
+	tags, err := pgx.CollectRows(rows, scanTag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan tag: %w", err)
+	}
+	return tags, nil
+}
+
+// Метод для получения тега по slug, в том числе по прежнему slug
+func (s *Storage) GetTag(ctx context.Context, slug string) (models.Tag, error) {
+	return getTag(ctx, s.db, slug)
+}
+
+// Метод для переименования тега. Прежний slug остается ссылкой на тег.
+func (s *Storage) RenameTag(ctx context.Context, slug, newSlug, name string) (models.Tag, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return models.Tag{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := getTag(ctx, tx, slug)
+	if err != nil {
+		return models.Tag{}, err
+	}
+	if newSlug != tag.Slug {
+		var owner int64
+		err := tx.QueryRow(ctx, `SELECT tag_id FROM tag_slugs WHERE slug = $1;`, newSlug).Scan(&owner)
+		if err == nil && owner != tag.ID {
+			return models.Tag{}, fmt.Errorf("tag %q: %w", newSlug, ErrConflict)
+		}
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return models.Tag{}, fmt.Errorf("failed to check tag slug: %w", err)
+		}
+		// Новый slug мог быть прежним slug этого же тега.
+		if _, err := tx.Exec(ctx, `DELETE FROM tag_aliases WHERE slug = $1;`, newSlug); err != nil {
+			return models.Tag{}, fmt.Errorf("failed to remove tag alias: %w", err)
+		}
+		_, err = tx.Exec(ctx, `INSERT INTO tag_aliases (slug, tag_id) VALUES ($1, $2);`, tag.Slug, tag.ID)
+		if err != nil {
+			return models.Tag{}, fmt.Errorf("failed to save tag alias: %w", err)
+		}
+	}
+	_, err = tx.Exec(ctx, `UPDATE tags SET slug = $2, name = $3 WHERE id = $1;`, tag.ID, newSlug, name)
+	if err != nil {
+		return models.Tag{}, fmt.Errorf("failed to rename tag: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return models.Tag{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	tag.Slug, tag.Name = newSlug, name
+	return tag, nil
+}
+
+// Метод для объединения тегов: новости тегов from получают тег into,
+// а slug объединенных тегов становятся ссылками на него.
+func (s *Storage) MergeTags(ctx context.Context, from []string, into string) (models.Tag, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return models.Tag{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	target, err := getTag(ctx, tx, into)
+	if err != nil {
+		return models.Tag{}, err
+	}
+	var ids []int64
+	for _, slug := range from {
+		tag, err := getTag(ctx, tx, slug)
+		if err != nil {
+			return models.Tag{}, err
+		}
+		if tag.ID != target.ID {
+			ids = append(ids, tag.ID)
+		}
+	}
+	if len(ids) > 0 {
+		for _, stmt := range []string{
+			`INSERT INTO news_tags (news_id, tag_id, position)
+			SELECT news_id, $2, MIN(position) FROM news_tags WHERE tag_id = ANY($1)
+			GROUP BY news_id
+			ON CONFLICT DO NOTHING;`,
+			`UPDATE tag_aliases SET tag_id = $2 WHERE tag_id = ANY($1);`,
+			`INSERT INTO tag_aliases (slug, tag_id) SELECT slug, $2 FROM tags WHERE id = ANY($1);`,
+			// Связи с объединенными тегами удаляются каскадно.
+			`DELETE FROM tags WHERE id = ANY($1);`,
+		} {
+			if _, err := tx.Exec(ctx, stmt, ids, target.ID); err != nil {
+				return models.Tag{}, fmt.Errorf("failed to merge tags: %w", err)
+			}
+		}
+	}
+	if target, err = getTag(ctx, tx, target.Slug); err != nil {
+		return models.Tag{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return models.Tag{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return target, nil
+}
+
+// Метод для получения категорий с названиями и числом новостей
+func (s *Storage) ListCategories(ctx context.Context) ([]models.Category, error) {
+	rows, err := s.db.Query(ctx, `
+	SELECT
+		c.slug,
+		COALESCE(c.parent_slug, ''),
+		COALESCE((SELECT jsonb_object_agg(n.language, n.name) FROM category_names n WHERE n.category_slug = c.slug), '{}'::JSONB),
+		(SELECT COUNT(*) FROM news WHERE news.category = c.slug)
+	FROM categories c
+	ORDER BY c.slug;`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query categories: %w", err)
+	}
+	categories, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.Category, error) {
+		var c models.Category
+		err := row.Scan(&c.Slug, &c.Parent, &c.Names, &c.NewsCount)
+		return c, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan category: %w", err)
+	}
+	return categories, nil
+}
+
+// Метод для создания или изменения категории: родителя и названий на языках.
+// Названия, не переданные в category.Names, удаляются. Родителем не может
+// быть сама категория или ее потомок.
+func (s *Storage) SaveCategory(ctx context.Context, category models.Category) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Два параллельных изменения могут вместе замкнуть цикл, поэтому
+	// изменения иерархии выполняются по очереди.
+	if _, err := tx.Exec(ctx, `LOCK TABLE categories IN SHARE ROW EXCLUSIVE MODE;`); err != nil {
+		return fmt.Errorf("failed to lock categories: %w", err)
+	}
+	var parent *string
+	if category.Parent != "" {
+		parent = &category.Parent
+		// Новый родитель не может быть самой категорией или ее потомком.
+		var cycle bool
+		err := tx.QueryRow(ctx, `
+		WITH RECURSIVE ancestors (slug) AS (
+			SELECT $2::TEXT
+			UNION
+			SELECT c.parent_slug FROM categories c JOIN ancestors a ON c.slug = a.slug
+			WHERE c.parent_slug IS NOT NULL
+		)
+		SELECT EXISTS (SELECT 1 FROM ancestors WHERE slug = $1);`, category.Slug, category.Parent,
+		).Scan(&cycle)
+		if err != nil {
+			return fmt.Errorf("failed to check category hierarchy: %w", err)
+		}
+		if cycle {
+			return fmt.Errorf("category %q cannot be nested in %q: %w", category.Slug, category.Parent, ErrConflict)
+		}
+	}
+	_, err = tx.Exec(ctx, `
+	INSERT INTO categories (slug, parent_slug) VALUES ($1, $2)
+	ON CONFLICT (slug) DO UPDATE SET parent_slug = EXCLUDED.parent_slug;`, category.Slug, parent,
+	)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+		return fmt.Errorf("parent category %q: %w", category.Parent, ErrNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save category: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM category_names WHERE category_slug = $1;`, category.Slug); err != nil {
+		return fmt.Errorf("failed to clear category names: %w", err)
+	}
+	for lang, name := range category.Names {
+		_, err := tx.Exec(ctx,
+			`INSERT INTO category_names (category_slug, language, name) VALUES ($1, $2, $3);`,
+			category.Slug, lang, name,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to save category name: %w", err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// rowQuerier — общее для пула соединений и транзакции чтение одной строки.
+type rowQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// getTag находит тег по актуальному или прежнему slug.
+func getTag(ctx context.Context, q rowQuerier, slug string) (models.Tag, error) {
+	var t models.Tag
+	err := q.QueryRow(ctx, `
+	SELECT t.id, t.slug, t.name, (SELECT COUNT(*) FROM news_tags nt WHERE nt.tag_id = t.id)
+	FROM tag_slugs s JOIN tags t ON t.id = s.tag_id
+	WHERE s.slug = $1;`, slug,
+	).Scan(&t.ID, &t.Slug, &t.Name, &t.NewsCount)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Tag{}, fmt.Errorf("tag %q: %w", slug, ErrNotFound)
+	}
+	if err != nil {
+		return models.Tag{}, fmt.Errorf("failed to get tag: %w", err)
+	}
+	return t, nil
+}
+
+func scanTag(row pgx.CollectableRow) (models.Tag, error) {
+	var t models.Tag
+	err := row.Scan(&t.Slug, &t.Name, &t.NewsCount)
+	return t, err
+}